@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"s3downloader/internal/cli"
+)
+
+func main() {
+	// Stop cleanly on Ctrl+C and when cron or CI terminates the job
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runner := cli.Runner{Stdout: os.Stdout, Stderr: os.Stderr, NewDownloader: cli.NewDownloader}
+	code := runner.Run(ctx, os.Args[1:])
+	stop()
+	os.Exit(code)
+}
@@ -1,13 +1,50 @@
 package main
 
 import (
+	"log"
+	"os"
+	"runtime/debug"
+
+	"s3downloader/internal/config"
+	"s3downloader/internal/crash"
+	"s3downloader/internal/logging"
+	"s3downloader/internal/redact"
 	"s3downloader/internal/ui"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 )
 
+// Build information, set via -ldflags at release time
+var (
+	version = "dev"
+	commit  = "none"
+)
+
 func main() {
+	log.SetOutput(redact.NewWriter(os.Stderr))
+
+	// Record panics in a crash log before exiting
+	defer func() {
+		if r := recover(); r != nil {
+			path, err := crash.Write(r, debug.Stack(), version, commit)
+			if err != nil {
+				log.Printf("failed to write crash log to %s, printed it above instead: %v", path, err)
+			} else {
+				log.Printf("crash log written to %s", path)
+			}
+			os.Exit(2)
+		}
+	}()
+
+	// Keep an audit trail of downloads; the app still works if the log cannot be opened
+	logger, err := config.FromEnv().Log.Open(logging.DefaultPath())
+	if err != nil {
+		log.Printf("logging disabled: %v", err)
+	}
+	defer logger.Close()
+	logger.Info("app started", "version", version, "commit", commit)
+
 	// Initialize the application with an ID
 	myApp := app.NewWithID("com.ninenine.s3downloader")
 
@@ -17,6 +54,7 @@ func main() {
 
 	// Initialize the UI manager and setup the UI
 	uiManager := ui.NewUIManager(myWindow)
+	uiManager.SetLogger(logger)
 	uiManager.SetupUI()
 
 	// Show and run the window
@@ -0,0 +1,71 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Access describes what the credentials may do in a bucket. HeadBucket succeeds
+// with permissions that allow neither listing nor reading objects, so CheckAccess
+// tries both.
+type Access struct {
+	List    bool   // Objects can be listed
+	Read    bool   // Objects can be read; only known when a listed key was tested
+	Key     string // Key read access was tested with, empty if none was listed
+	ListErr error  // Why listing failed
+	ReadErr error  // Why reading failed
+}
+
+// ReadTested reports whether read access could be tested, which needs an object
+func (a Access) ReadTested() bool {
+	return a.Key != ""
+}
+
+// String summarizes the access, such as "list: ok, read: denied"
+func (a Access) String() string {
+	list, read := "denied", "denied"
+	if a.List {
+		list = "ok"
+	}
+	switch {
+	case !a.ReadTested():
+		read = "not tested, no objects listed"
+	case a.Read:
+		read = "ok"
+	}
+	return fmt.Sprintf("list: %s, read: %s", list, read)
+}
+
+// CheckAccess lists at most one object under prefix and, if there is one, requests
+// its metadata with HeadObject, which needs the same permission as downloading it
+func (d *Downloader) CheckAccess(ctx context.Context, bucket, prefix string) Access {
+	var access Access
+	input := d.listObjectsInput(bucket, prefix)
+	input.MaxKeys = aws.Int64(1)
+	var first *s3.Object
+	err := d.s3.ListObjectsV2PagesWithContext(ctx, input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		if len(page.Contents) > 0 {
+			first = page.Contents[0]
+		}
+		return false // One page is enough
+	}, d.requestOptions()...)
+	if err != nil {
+		access.ListErr = fmt.Errorf("failed to list objects in bucket '%s': %w", bucket, d.withAnonymousHint(err))
+		return access
+	}
+	access.List = true
+	if first == nil {
+		return access
+	}
+
+	access.Key = aws.StringValue(first.Key)
+	if _, err := d.s3.HeadObjectWithContext(ctx, d.headObjectInput(bucket, first.Key, nil)); err != nil {
+		access.ReadErr = fmt.Errorf("failed to read '%s': %w", access.Key, d.withAnonymousHint(err))
+		return access
+	}
+	access.Read = true
+	return access
+}
@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAccess(t *testing.T) {
+	denied := awserr.New("AccessDenied", "Access Denied", nil)
+	objects := map[string]string{"logs/a.txt": "alpha", "logs/b.txt": "bravo", "other/c.txt": "charlie"}
+
+	testCases := []struct {
+		name      string
+		prefix    string
+		setup     func(f *fakeS3)
+		wantList  bool
+		wantRead  bool
+		wantKey   string
+		wantText  string
+		wantHeads int
+	}{
+		{"Full access", "logs/", func(f *fakeS3) {}, true, true, "logs/a.txt", "list: ok, read: ok", 1},
+		{"List only", "logs/", func(f *fakeS3) { f.headErr = denied }, true, false, "logs/a.txt", "list: ok, read: denied", 1},
+		{"No list permission", "", func(f *fakeS3) { f.pageErr = denied }, false, false, "", "list: denied, read: not tested, no objects listed", 0},
+		{"Nothing under the prefix", "missing/", func(f *fakeS3) {}, true, false, "", "list: ok, read: not tested, no objects listed", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(objects)
+			tc.setup(client)
+			d := newTestDownloader(client, newMemorySink())
+
+			// HeadBucket alone does not tell
+			assert.NoError(t, d.ValidateBucketExists(context.Background(), "bucket"))
+			access := d.CheckAccess(context.Background(), "bucket", tc.prefix)
+
+			assert.Equal(t, tc.wantList, access.List)
+			assert.Equal(t, tc.wantRead, access.Read)
+			assert.Equal(t, tc.wantKey, access.Key)
+			assert.Equal(t, tc.wantText, access.String())
+			assert.Equal(t, tc.wantList, access.ListErr == nil)
+			if tc.wantList && tc.wantKey != "" {
+				assert.Equal(t, tc.wantRead, access.ReadErr == nil)
+			}
+			if assert.Len(t, client.lists, 1) {
+				assert.Equal(t, int64(1), aws.Int64Value(client.lists[0].MaxKeys))
+				assert.Equal(t, tc.prefix, aws.StringValue(client.lists[0].Prefix))
+			}
+			assert.Len(t, client.heads, tc.wantHeads)
+			assert.Empty(t, client.gets, "nothing is downloaded")
+		})
+	}
+}
+
+func TestCheckAccessErrors(t *testing.T) {
+	denied := awserr.New("AccessDenied", "Access Denied", nil)
+	client := newFakeS3(map[string]string{"a.txt": "alpha"})
+	client.headErr = denied
+	d := newTestDownloader(client, newMemorySink())
+
+	access := d.CheckAccess(context.Background(), "bucket", "")
+	assert.ErrorIs(t, access.ReadErr, denied)
+	assert.EqualError(t, access.ReadErr, "failed to read 'a.txt': AccessDenied: Access Denied")
+
+	client.pageErr = denied
+	access = d.CheckAccess(context.Background(), "bucket", "")
+	assert.ErrorIs(t, access.ListErr, denied)
+	assert.EqualError(t, access.ListErr, "failed to list objects in bucket 'bucket': AccessDenied: Access Denied")
+	assert.NoError(t, access.ReadErr)
+}
@@ -0,0 +1,39 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// ErrAnonymousDenied is wrapped around the errors of anonymous requests S3 refused,
+// because the bucket or object is not public
+var ErrAnonymousDenied = errors.New("anonymous access denied, the bucket is not public; enter credentials to use it")
+
+// withAnonymousHint wraps err with ErrAnonymousDenied if the downloader sends
+// anonymous requests and err denies access; other errors are returned as they are
+func (d *Downloader) withAnonymousHint(err error) error {
+	if !d.config.Anonymous || !isAccessDenied(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrAnonymousDenied, err)
+}
+
+// isAccessDenied reports whether err is S3 refusing a request for lack of permission
+func isAccessDenied(err error) bool {
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusForbidden {
+		return true
+	}
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+	switch aerr.Code() {
+	case "AccessDenied", "Forbidden", "AllAccessDisabled":
+		return true
+	}
+	return false
+}
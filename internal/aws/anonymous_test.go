@@ -0,0 +1,103 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymousUsesAnonymousCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "ENVKEY")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+
+	cfg := DefaultConfig()
+	cfg.Anonymous = true
+	d, err := NewDownloaderWithConfig("us-east-1", "AKID", "SECRET", cfg)
+	assert.NoError(t, err)
+	assert.Same(t, credentials.AnonymousCredentials, d.sess.Config.Credentials, "neither the keys nor the default chain are used")
+
+	cfg.RoleARN = "arn:aws:iam::123456789012:role/reader"
+	_, err = NewDownloaderWithConfig("us-east-1", "", "", cfg)
+	assert.ErrorContains(t, err, "anonymous access cannot assume role 'arn:aws:iam::123456789012:role/reader'")
+}
+
+func TestAnonymousRequestsAreUnsigned(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		signed  []string
+		handler = newS3Handler("a.txt", "public data")
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			mu.Lock()
+			signed = append(signed, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := DefaultConfig()
+	cfg.Endpoint = server.URL
+	cfg.Anonymous = true
+	d, err := NewDownloaderWithConfig("us-east-1", "", "", cfg)
+	assert.NoError(t, err)
+
+	downloadPath := t.TempDir()
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil))
+
+	data, err := os.ReadFile(filepath.Join(downloadPath, "a.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "public data", string(data))
+	assert.Empty(t, signed)
+}
+
+func TestAnonymousAccessDenied(t *testing.T) {
+	forbidden := awserr.NewRequestFailure(awserr.New("Forbidden", "Forbidden", nil), http.StatusForbidden, "")
+	testCases := []struct {
+		name      string
+		anonymous bool
+		err       error
+		wantHint  bool
+	}{
+		{"Anonymous and forbidden", true, forbidden, true},
+		{"Anonymous and access denied", true, awserr.New("AccessDenied", "Access Denied", nil), true},
+		{"Anonymous and another error", true, awserr.New("NoSuchBucket", "The specified bucket does not exist", nil), false},
+		{"Signed and forbidden", false, forbidden, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{"a.txt": "alpha"})
+			client.listErr = tc.err
+			d := newTestDownloader(client, newMemorySink())
+			d.config.Anonymous = tc.anonymous
+
+			errs := []error{
+				d.ValidateBucketExists(context.Background(), "bucket"),
+				d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil),
+				d.CheckAccess(context.Background(), "bucket", "").ListErr,
+			}
+			for _, err := range errs {
+				assert.ErrorIs(t, err, tc.err)
+				assert.Equal(t, tc.wantHint, errors.Is(err, ErrAnonymousDenied), err.Error())
+			}
+		})
+	}
+
+	client := newFakeS3(map[string]string{"a.txt": "alpha"})
+	client.listErr = forbidden
+	d := newTestDownloader(client, newMemorySink())
+	d.config.Anonymous = true
+	assert.EqualError(t, d.ValidateBucketExists(context.Background(), "bucket"),
+		"failed to access bucket 'bucket': anonymous access denied, the bucket is not public; enter credentials to use it: "+forbidden.Error())
+}
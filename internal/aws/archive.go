@@ -0,0 +1,207 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"s3downloader/internal/progress"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Defaults for restoring archived objects
+const (
+	defaultRestoreDays         = 1
+	defaultRestorePollInterval = 5 * time.Minute
+)
+
+// isArchived reports whether obj is stored in a Glacier storage class. GLACIER and
+// DEEP_ARCHIVE objects cannot be read until restored; GLACIER_IR objects can, but
+// every read is billed as a retrieval, so they are treated as archived as well.
+func isArchived(obj *s3.Object) bool {
+	switch aws.StringValue(obj.StorageClass) {
+	case s3.ObjectStorageClassGlacier, s3.ObjectStorageClassDeepArchive, s3.ObjectStorageClassGlacierIr:
+		return true
+	}
+	return false
+}
+
+// needsRestore reports whether obj must be restored before it can be downloaded
+func needsRestore(obj *s3.Object) bool {
+	switch aws.StringValue(obj.StorageClass) {
+	case s3.ObjectStorageClassGlacier, s3.ObjectStorageClassDeepArchive:
+		return true
+	}
+	return false
+}
+
+// validateRestoreTier checks that the restore tier, if any, is one S3 accepts
+func validateRestoreTier(tier string) error {
+	if tier == "" {
+		return nil
+	}
+	for _, valid := range s3.Tier_Values() {
+		if tier == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid restore tier '%s': must be one of %s", tier, strings.Join(s3.Tier_Values(), ", "))
+}
+
+// restoreArchived wraps produce so that archived objects are queued only once they
+// are readable. A restore is requested for each one as it is listed, then the pending
+// ones are polled together with HeadObject until S3 reports their restores completed.
+// Restores take hours, so workers must not wait for them: they download everything
+// else meanwhile. Objects that the tag or Content-Type filters skip are passed on
+// without a restore for the workers to skip, and failures are queued as such.
+func (d *Downloader) restoreArchived(runBucket string, tracker *progress.Tracker, observer ProgressObserver, produce objectProducer) objectProducer {
+	return func(ctx context.Context, enqueue func(target) bool) error {
+		var pending []target
+		bucketOf := func(obj target) string {
+			if obj.bucket != "" {
+				return obj.bucket
+			}
+			return runBucket
+		}
+		// ready hands obj to the workers once it no longer waits for a restore
+		ready := func(obj target, err error) bool {
+			tracker.FilesRestoring.Add(-1)
+			report(observer, tracker)
+			obj.err = err
+			return enqueue(obj)
+		}
+
+		err := produce(ctx, func(obj target) bool {
+			if obj.err != nil || !needsRestore(obj.Object) || !d.wantsRestore(ctx, bucketOf(obj), obj) {
+				return enqueue(obj)
+			}
+			state, err := d.restoreState(ctx, bucketOf(obj), obj)
+			if err == nil && state == restoreNone {
+				err = d.requestRestore(ctx, bucketOf(obj), obj)
+			}
+			if err != nil || state == restoreDone {
+				obj.err = err
+				return enqueue(obj)
+			}
+			tracker.FilesRestoring.Add(1)
+			report(observer, tracker)
+			pending = append(pending, obj)
+			return true
+		})
+		if err != nil {
+			tracker.FilesRestoring.Add(-int64(len(pending)))
+			report(observer, tracker)
+			return err
+		}
+
+		interval := d.config.RestorePollInterval
+		if interval <= 0 {
+			interval = defaultRestorePollInterval
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for len(pending) > 0 {
+			select {
+			case <-ctx.Done():
+				tracker.FilesRestoring.Add(-int64(len(pending)))
+				report(observer, tracker)
+				return fmt.Errorf("stopped waiting for restore of %d objects: %w", len(pending), ctx.Err())
+			case <-ticker.C:
+			}
+			waiting := pending[:0]
+			for _, obj := range pending {
+				state, err := d.restoreState(ctx, bucketOf(obj), obj)
+				if err == nil && state != restoreDone {
+					waiting = append(waiting, obj)
+					continue
+				}
+				if !ready(obj, err) {
+					return nil // The run was stopped
+				}
+			}
+			pending = waiting
+		}
+		return nil
+	}
+}
+
+// wantsRestore reports whether the workers' tag and Content-Type filters may keep obj,
+// so that no restore is paid for an object they would skip. On errors it says yes and
+// leaves the object to the workers, which report them.
+func (d *Downloader) wantsRestore(ctx context.Context, bucket string, obj target) bool {
+	if len(d.config.TagFilters) > 0 {
+		if matched, err := d.matchesTags(ctx, bucket, obj); err == nil && !matched {
+			return false
+		}
+	}
+	if len(d.config.ContentTypePrefixes) > 0 {
+		if matched, err := d.matchesContentType(ctx, bucket, &obj); err == nil && !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// requestRestore starts restoring an archived object with the configured retention and tier
+func (d *Downloader) requestRestore(ctx context.Context, bucket string, obj target) error {
+	days := d.config.RestoreDays
+	if days <= 0 {
+		days = defaultRestoreDays
+	}
+	tier := d.config.RestoreTier
+	if tier == "" {
+		tier = s3.TierStandard
+	}
+
+	_, err := d.s3.RestoreObjectWithContext(ctx, &s3.RestoreObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          obj.Key,
+		VersionId:    obj.versionID,
+		RequestPayer: d.requestPayer(),
+		RestoreRequest: &s3.RestoreRequest{
+			Days:                 aws.Int64(days),
+			GlacierJobParameters: &s3.GlacierJobParameters{Tier: aws.String(tier)},
+		},
+	})
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == "RestoreAlreadyInProgress" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore '%s': %w", aws.StringValue(obj.Key), err)
+	}
+	return nil
+}
+
+// restoreStatus is the progress of an object's restore as reported by HeadObject
+type restoreStatus int
+
+const (
+	restoreNone restoreStatus = iota
+	restoreOngoing
+	restoreDone
+)
+
+// restoreState reads the Restore header of an object, which looks like
+// `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`
+func (d *Downloader) restoreState(ctx context.Context, bucket string, obj target) (restoreStatus, error) {
+	out, err := d.s3.HeadObjectWithContext(ctx, d.headObjectInput(bucket, obj.Key, obj.versionID))
+	if err != nil {
+		return restoreNone, fmt.Errorf("failed to check restore status of '%s': %w", aws.StringValue(obj.Key), err)
+	}
+	restore := aws.StringValue(out.Restore)
+	switch {
+	case strings.Contains(restore, `ongoing-request="false"`):
+		return restoreDone, nil
+	case strings.Contains(restore, `ongoing-request="true"`):
+		return restoreOngoing, nil
+	default:
+		return restoreNone, nil
+	}
+}
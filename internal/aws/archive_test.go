@@ -0,0 +1,176 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchivedObjectsSkipped(t *testing.T) {
+	newClient := func() *fakeS3 {
+		client := newFakeS3(map[string]string{
+			"hot.txt":     "hot",
+			"glacier.txt": "glacier",
+			"deep.txt":    "deep",
+			"ir.txt":      "instant",
+			"ia.txt":      "infrequent",
+		})
+		client.classes["glacier.txt"] = s3.ObjectStorageClassGlacier
+		client.classes["deep.txt"] = s3.ObjectStorageClassDeepArchive
+		client.classes["ir.txt"] = s3.ObjectStorageClassGlacierIr
+		client.classes["ia.txt"] = s3.ObjectStorageClassStandardIa
+		return client
+	}
+
+	t.Run("Listing", func(t *testing.T) {
+		client := newClient()
+		sink := newMemorySink()
+		d := newTestDownloader(client, sink)
+
+		err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+		assert.NoError(t, err)
+		p := d.Progress()
+		assert.Equal(t, int64(3), p.ArchivedSkipped)
+		assert.Equal(t, int64(2), p.FilesFound)
+		assert.Equal(t, int64(0), p.ErrorCount)
+		assert.True(t, sink.Exists(filepath.Join("out", "hot.txt")))
+		assert.True(t, sink.Exists(filepath.Join("out", "ia.txt")))
+		assert.False(t, sink.Exists(filepath.Join("out", "glacier.txt")))
+		assert.Len(t, client.gets, 2)
+	})
+
+	t.Run("Keys", func(t *testing.T) {
+		client := newClient()
+		d := newTestDownloader(client, newMemorySink())
+
+		missing, err := d.DownloadObjects(context.Background(), "bucket", []string{"hot.txt", "deep.txt"}, "out", nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, missing)
+		assert.Equal(t, int64(1), d.Progress().ArchivedSkipped)
+		assert.Equal(t, int64(1), d.Progress().FilesFound)
+	})
+
+	t.Run("DownloadArchived", func(t *testing.T) {
+		client := newClient()
+		d := newTestDownloader(client, newMemorySink())
+		d.config.DownloadArchived = true
+
+		err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), d.Progress().ArchivedSkipped)
+		assert.Equal(t, int64(5), d.Progress().FilesFound)
+	})
+}
+
+func TestRestoreArchived(t *testing.T) {
+	const (
+		ongoing  = `ongoing-request="true"`
+		restored = `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`
+	)
+
+	testCases := []struct {
+		name            string
+		states          []string
+		expectedRequest bool
+	}{
+		{"Not restored yet", []string{"", ongoing, ongoing, restored}, true},
+		{"Restore already running", []string{ongoing, restored}, false},
+		{"Already restored", []string{restored}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{"cold.txt": "cold"})
+			client.classes["cold.txt"] = s3.ObjectStorageClassDeepArchive
+			client.restores["cold.txt"] = tc.states
+			sink := newMemorySink()
+			d := newTestDownloader(client, sink)
+			d.config.RestoreArchived = true
+			d.config.RestoreDays = 3
+			d.config.RestoreTier = s3.TierBulk
+			d.config.RestorePollInterval = time.Millisecond
+
+			err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "cold", sink.contents(filepath.Join("out", "cold.txt")))
+			assert.Len(t, client.heads, len(tc.states))
+			assert.Equal(t, int64(0), d.Progress().FilesRestoring)
+			if !tc.expectedRequest {
+				assert.Empty(t, client.restored)
+				return
+			}
+			if assert.Len(t, client.restored, 1) {
+				request := client.restored[0].RestoreRequest
+				assert.Equal(t, int64(3), aws.Int64Value(request.Days))
+				assert.Equal(t, s3.TierBulk, aws.StringValue(request.GlacierJobParameters.Tier))
+			}
+		})
+	}
+}
+
+func TestRestoreArchivedCanceled(t *testing.T) {
+	client := newFakeS3(map[string]string{"cold.txt": "cold"})
+	client.classes["cold.txt"] = s3.ObjectStorageClassGlacier
+	client.restores["cold.txt"] = []string{`ongoing-request="true"`}
+	d := newTestDownloader(client, newMemorySink())
+	d.config.RestoreArchived = true
+	d.config.RestorePollInterval = time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := d.ListAndDownloadObjects(ctx, "bucket", "", "out", nil)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int64(0), d.Progress().FilesRestoring)
+}
+
+func TestRestoreArchivedDoesNotHoldWorkers(t *testing.T) {
+	objects := map[string]string{"warm.txt": "warm"}
+	for i := 0; i < 6; i++ {
+		key := fmt.Sprintf("cold%d.txt", i)
+		objects[key] = "cold"
+	}
+	client := newFakeS3(objects)
+	for key := range objects {
+		if key != "warm.txt" {
+			client.classes[key] = s3.ObjectStorageClassDeepArchive
+			client.restores[key] = []string{"", `ongoing-request="true"`} // Never finishes
+		}
+	}
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+	d.config.MaxWorkers = 2
+	d.config.RestoreArchived = true
+	d.config.RestorePollInterval = time.Millisecond
+
+	observer := &recordingObserver{}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := d.ListAndDownloadObjectsWithObserver(ctx, "bucket", "", "out", observer)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, client.restored, 6, "every restore is requested, not one per worker at a time")
+	assert.Equal(t, "warm", sink.contents(filepath.Join("out", "warm.txt")), "the workers download the rest meanwhile")
+	assert.Equal(t, int64(0), d.Progress().FilesRestoring)
+	var restoring int64
+	for _, p := range observer.updates {
+		restoring = max(restoring, p.FilesRestoring)
+	}
+	assert.Equal(t, int64(6), restoring, "all six wait at once")
+}
+
+func TestValidateRestoreTier(t *testing.T) {
+	assert.NoError(t, validateRestoreTier(""))
+	assert.NoError(t, validateRestoreTier(s3.TierExpedited))
+	assert.Error(t, validateRestoreTier("Fast"))
+}
@@ -0,0 +1,98 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// WrongRegionError reports a request to a bucket that lives in another region
+// than the client's
+type WrongRegionError struct {
+	Bucket   string
+	Region   string // Region the request was sent to
+	Expected string // Region of the bucket
+	Err      error
+}
+
+// Error describes where the bucket is
+func (e *WrongRegionError) Error() string {
+	if e.Region == "" {
+		return fmt.Sprintf("bucket '%s' is in region '%s': %v", e.Bucket, e.Expected, e.Err)
+	}
+	return fmt.Sprintf("bucket '%s' is in region '%s', not '%s': %v", e.Bucket, e.Expected, e.Region, e.Err)
+}
+
+// Unwrap returns the error of the failed request
+func (e *WrongRegionError) Unwrap() error {
+	return e.Err
+}
+
+// normalizeLocation converts a bucket's location constraint to a region name.
+// Buckets in us-east-1 have no constraint, and some old buckets in eu-west-1
+// report "EU".
+func normalizeLocation(constraint string) string {
+	switch constraint {
+	case "":
+		return "us-east-1"
+	case "EU":
+		return "eu-west-1"
+	}
+	return constraint
+}
+
+// DetectBucketRegion returns the region the bucket was created in
+func (d *Downloader) DetectBucketRegion(ctx context.Context, bucket string) (string, error) {
+	out, err := d.s3.GetBucketLocationWithContext(ctx, &s3.GetBucketLocationInput{
+		Bucket: aws.String(bucket),
+	}, d.requestOptions()...)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect the region of bucket '%s': %w", bucket, err)
+	}
+	return normalizeLocation(aws.StringValue(out.LocationConstraint)), nil
+}
+
+// isRegionRedirect reports whether err is S3 refusing a request because the
+// bucket is in another region
+func isRegionRedirect(err error) bool {
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusMovedPermanently {
+		return true
+	}
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+	switch aerr.Code() {
+	case "PermanentRedirect", "MovedPermanently", "BucketRegionError", "AuthorizationHeaderMalformed":
+		return true
+	}
+	return false
+}
+
+// withBucketRegion names the bucket's region in err if err is a region
+// redirect; other errors, and redirects whose region cannot be found, are
+// returned as they are
+func (d *Downloader) withBucketRegion(ctx context.Context, bucket string, err error) error {
+	if !isRegionRedirect(err) {
+		return err
+	}
+	expected, detectErr := d.DetectBucketRegion(ctx, bucket)
+	if detectErr != nil || expected == d.region() {
+		return err
+	}
+	return &WrongRegionError{Bucket: bucket, Region: d.region(), Expected: expected, Err: err}
+}
+
+// region returns the region requests are sent to
+func (d *Downloader) region() string {
+	if d.sess == nil {
+		return ""
+	}
+	return aws.StringValue(d.sess.Config.Region)
+}
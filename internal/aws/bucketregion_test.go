@@ -0,0 +1,95 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectBucketRegion(t *testing.T) {
+	testCases := []struct {
+		name       string
+		constraint string
+		want       string
+	}{
+		{"us-east-1 has no constraint", "", "us-east-1"},
+		{"Legacy EU", "EU", "eu-west-1"},
+		{"eu-central-1", "eu-central-1", "eu-central-1"},
+		{"ap-southeast-2", "ap-southeast-2", "ap-southeast-2"},
+		{"us-gov-west-1", "us-gov-west-1", "us-gov-west-1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(nil)
+			client.location = aws.String(tc.constraint)
+			d := newTestDownloader(client, newMemorySink())
+
+			region, err := d.DetectBucketRegion(context.Background(), "bucket")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, region)
+		})
+	}
+}
+
+func TestDetectBucketRegionFails(t *testing.T) {
+	d := newTestDownloader(newFakeS3(nil), newMemorySink())
+	_, err := d.DetectBucketRegion(context.Background(), "bucket")
+	assert.ErrorContains(t, err, "failed to detect the region of bucket 'bucket'")
+}
+
+func TestIsRegionRedirect(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"PermanentRedirect", awserr.New("PermanentRedirect", "The bucket you are attempting to access must be addressed using the specified endpoint.", nil), true},
+		{"HEAD 301", awserr.NewRequestFailure(awserr.New("MovedPermanently", "Moved Permanently", nil), 301, "id"), true},
+		{"Wrapped", fmt.Errorf("error listing objects: %w", awserr.New("AuthorizationHeaderMalformed", "the region 'us-east-1' is wrong", nil)), true},
+		{"Access denied", awserr.NewRequestFailure(awserr.New("AccessDenied", "Access Denied", nil), 403, "id"), false},
+		{"Plain error", errors.New("timeout"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, isRegionRedirect(tc.err))
+		})
+	}
+}
+
+func TestRedirectNamesBucketRegion(t *testing.T) {
+	redirect := awserr.New("PermanentRedirect", "The bucket you are attempting to access must be addressed using the specified endpoint.", nil)
+	client := newFakeS3(nil)
+	client.listErr = redirect
+	client.location = aws.String("ap-northeast-1")
+	d := newTestDownloader(client, newMemorySink())
+
+	err := d.ValidateBucketExists(context.Background(), "bucket")
+	var regionErr *WrongRegionError
+	if assert.ErrorAs(t, err, &regionErr) {
+		assert.Equal(t, "ap-northeast-1", regionErr.Expected)
+	}
+	assert.ErrorIs(t, err, redirect)
+	assert.ErrorContains(t, err, "bucket 'bucket' is in region 'ap-northeast-1'")
+
+	err = d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+	assert.ErrorAs(t, err, &regionErr)
+	assert.ErrorContains(t, err, "error listing objects")
+
+	// Without the bucket's location the original error stays
+	client.location = nil
+	err = d.ValidateBucketExists(context.Background(), "bucket")
+	assert.False(t, errors.As(err, &regionErr))
+	assert.ErrorIs(t, err, redirect)
+}
+
+func TestWrongRegionErrorMessage(t *testing.T) {
+	err := &WrongRegionError{Bucket: "logs", Region: "us-east-1", Expected: "eu-west-2", Err: errors.New("301 Moved Permanently")}
+	assert.EqualError(t, err, "bucket 'logs' is in region 'eu-west-2', not 'us-east-1': 301 Moved Permanently")
+}
@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"s3downloader/internal/progress"
+)
+
+// BucketJob is a bucket and prefix of a download spanning several buckets
+type BucketJob struct {
+	Bucket string
+	Prefix string
+}
+
+// ListAndDownloadBuckets downloads the objects under the prefix of every job
+// in a single run, so that one worker pool serves all buckets and the progress
+// sums over them. Each bucket's objects land in a subfolder of downloadPath
+// named after the bucket. With overwrite, existing local files are replaced
+// instead of skipped. Buckets are listed one after the other; mirroring is not
+// supported across buckets.
+func (d *Downloader) ListAndDownloadBuckets(ctx context.Context, jobs []BucketJob, downloadPath string, overwrite bool, progressChan chan<- progress.Progress) error {
+	if len(jobs) == 0 {
+		return errors.New("no buckets to download from")
+	}
+	produce := d.listBuckets(jobs)
+	if overwrite {
+		produce = replacing(produce)
+	}
+
+	observer := newChannelObserver(ctx, progressChan)
+	err := d.runDownload(ctx, "", downloadPath, observer, produce)
+	if observer != nil {
+		observer.OnComplete(d.Progress())
+	}
+	return err
+}
+
+// listBuckets produces the objects of every job, placed under their bucket's name
+func (d *Downloader) listBuckets(jobs []BucketJob) objectProducer {
+	return func(ctx context.Context, enqueue func(target) bool) error {
+		stopped := false
+		for _, job := range jobs {
+			err := d.listPrefix(job.Bucket, job.Prefix)(ctx, func(obj target) bool {
+				obj.bucket = job.Bucket
+				obj.localKey = job.Bucket + "/" + obj.localKey
+				stopped = !enqueue(obj)
+				return !stopped
+			})
+			if err != nil {
+				return fmt.Errorf("bucket '%s': %w", job.Bucket, d.withAnonymousHint(d.withBucketRegion(ctx, job.Bucket, err)))
+			}
+			if stopped {
+				return nil
+			}
+		}
+		return nil
+	}
+}
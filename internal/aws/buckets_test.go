@@ -0,0 +1,120 @@
+package aws
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"s3downloader/internal/progress"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBuckets routes requests to a fakeS3 per bucket
+type fakeBuckets struct {
+	s3iface.S3API
+	buckets map[string]*fakeS3
+}
+
+func (f fakeBuckets) bucket(name *string) (*fakeS3, error) {
+	b, ok := f.buckets[aws.StringValue(name)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchBucket, "The specified bucket does not exist", nil)
+	}
+	return b, nil
+}
+
+func (f fakeBuckets) ListObjectsV2PagesWithContext(ctx aws.Context, input *s3.ListObjectsV2Input,
+	fn func(*s3.ListObjectsV2Output, bool) bool, opts ...request.Option) error {
+	b, err := f.bucket(input.Bucket)
+	if err != nil {
+		return err
+	}
+	return b.ListObjectsV2PagesWithContext(ctx, input, fn, opts...)
+}
+
+func (f fakeBuckets) GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	b, err := f.bucket(input.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	return b.GetObjectWithContext(ctx, input, opts...)
+}
+
+func TestListAndDownloadBuckets(t *testing.T) {
+	logs := newFakeS3(map[string]string{"2024/a.log": "alpha", "2024/b.log": "bravo", "2023/old.log": "old"})
+	archive := newFakeS3(map[string]string{"2024/a.log": "archived alpha", "2024/c.log": "charlie"})
+	sink := newMemorySink()
+	d := newTestDownloader(fakeBuckets{buckets: map[string]*fakeS3{"logs": logs, "archive": archive}}, sink)
+
+	var mu sync.Mutex
+	var updates []progress.Progress
+	progressChan := make(chan progress.Progress)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for p := range progressChan {
+			mu.Lock()
+			updates = append(updates, p)
+			mu.Unlock()
+		}
+	}()
+
+	err := d.ListAndDownloadBuckets(context.Background(), []BucketJob{{Bucket: "logs", Prefix: "2024/"}, {Bucket: "archive", Prefix: "2024/"}}, "out", false, progressChan)
+	close(progressChan)
+	<-done
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alpha", sink.contents("out/logs/2024/a.log"))
+	assert.Equal(t, "bravo", sink.contents("out/logs/2024/b.log"))
+	assert.Equal(t, "archived alpha", sink.contents("out/archive/2024/a.log"), "same key in another bucket gets its own folder")
+	assert.Equal(t, "charlie", sink.contents("out/archive/2024/c.log"))
+	assert.False(t, sink.Exists("out/logs/2023/old.log"))
+
+	p := d.Progress()
+	assert.Equal(t, int64(4), p.FilesFound, "counts sum over both buckets")
+	assert.Equal(t, int64(4), p.FilesDownloaded)
+	assert.Equal(t, int64(len("alpha")+len("bravo")+len("archived alpha")+len("charlie")), p.TotalBytes)
+	mu.Lock()
+	assert.NotEmpty(t, updates)
+	mu.Unlock()
+	assert.Len(t, logs.lists, 1)
+	assert.Len(t, archive.lists, 1)
+}
+
+func TestListAndDownloadBucketsMissingBucket(t *testing.T) {
+	logs := newFakeS3(map[string]string{"a.log": "alpha"})
+	d := newTestDownloader(fakeBuckets{buckets: map[string]*fakeS3{"logs": logs}}, newMemorySink())
+
+	err := d.ListAndDownloadBuckets(context.Background(), []BucketJob{{Bucket: "logs"}, {Bucket: "gone"}}, "out", false, nil)
+	assert.ErrorContains(t, err, "bucket 'gone': error listing objects")
+	assert.Equal(t, int64(1), d.Progress().FilesDownloaded, "the buckets before it are still downloaded")
+
+	assert.EqualError(t, d.ListAndDownloadBuckets(context.Background(), nil, "out", false, nil), "no buckets to download from")
+}
+
+func TestListAndDownloadBucketsOverwrite(t *testing.T) {
+	logs := newFakeS3(map[string]string{"a.log": "new"})
+	sink := newMemorySink()
+	w, err := sink.Create("out/logs/a.log")
+	assert.NoError(t, err)
+	w.WriteAt([]byte("old"), 0)
+	w.Close()
+	d := newTestDownloader(fakeBuckets{buckets: map[string]*fakeS3{"logs": logs}}, sink)
+	jobs := []BucketJob{{Bucket: "logs"}}
+
+	assert.NoError(t, d.ListAndDownloadBuckets(context.Background(), jobs, "out", true, nil))
+	assert.Equal(t, "new", sink.contents("out/logs/a.log"))
+	assert.False(t, d.config.Overwrite, "the setting only applies to the call")
+
+	// A later run on the same downloader skips the existing file again
+	logs.objects["a.log"] = []byte("newer")
+	assert.NoError(t, d.ListAndDownloadBuckets(context.Background(), jobs, "out", false, nil))
+	assert.Equal(t, "new", sink.contents("out/logs/a.log"))
+	assert.Equal(t, int64(1), d.Progress().FilesSkipped)
+}
@@ -0,0 +1,96 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"s3downloader/internal/progress"
+)
+
+// Adaptive concurrency tuning
+const (
+	defaultMinWorkers = 4
+	adaptInterval     = 2 * time.Second
+	drainPollInterval = 100 * time.Millisecond
+	throughputMargin  = 0.05 // Relative change in throughput that is not treated as noise
+)
+
+// concurrencyController adjusts the number of workers from periodic throughput
+// samples. It adds a worker while throughput keeps climbing, undoes its last step
+// when throughput drops, and sheds a worker when throughput plateaus or errors
+// increase, always staying within [min, max].
+type concurrencyController struct {
+	min, max   int
+	workers    int
+	lastStep   int
+	lastRate   float64
+	lastErrors int64
+}
+
+// newConcurrencyController starts at minWorkers; minWorkers is raised to 1 and
+// maxWorkers to minWorkers if needed
+func newConcurrencyController(minWorkers, maxWorkers int) *concurrencyController {
+	minWorkers = max(minWorkers, 1)
+	maxWorkers = max(maxWorkers, minWorkers)
+	return &concurrencyController{min: minWorkers, max: maxWorkers, workers: minWorkers}
+}
+
+// next takes the bytes per second and total error count seen since the run started
+// and returns the number of workers to run until the next sample
+func (c *concurrencyController) next(rate float64, errors int64) int {
+	step := -1 // Plateau: the same throughput with fewer workers is better
+	switch {
+	case errors > c.lastErrors:
+		step = -1
+	case rate > c.lastRate*(1+throughputMargin):
+		step = 1
+	case rate < c.lastRate*(1-throughputMargin):
+		step = -c.lastStep
+	}
+
+	workers := min(max(c.workers+step, c.min), c.max)
+	c.lastStep = workers - c.workers
+	c.workers = workers
+	c.lastRate, c.lastErrors = rate, errors
+	return c.workers
+}
+
+// adaptWorkers samples throughput every adaptInterval and starts or stops workers to
+// follow the controller. It returns once listing is done and the queue is drained,
+// since no further objects remain to be picked up by new workers.
+func adaptWorkers(ctx context.Context, c *concurrencyController, tracker *progress.Tracker,
+	fileChan <-chan target, doneChan <-chan struct{}, start func(), stop chan<- struct{}) {
+	sample := time.NewTicker(adaptInterval)
+	defer sample.Stop()
+	drain := time.NewTicker(drainPollInterval)
+	defer drain.Stop()
+
+	running := c.workers
+	lastBytes, lastTime := tracker.TotalBytes.Load(), time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-drain.C:
+			select {
+			case <-doneChan:
+				if len(fileChan) == 0 {
+					return
+				}
+			default:
+			}
+		case now := <-sample.C:
+			bytes := tracker.TotalBytes.Load()
+			rate := float64(bytes-lastBytes) / now.Sub(lastTime).Seconds()
+			lastBytes, lastTime = bytes, now
+
+			workers := c.next(rate, tracker.ErrorCount.Load())
+			for ; running < workers; running++ {
+				start()
+			}
+			for ; running > workers; running-- {
+				stop <- struct{}{}
+			}
+		}
+	}
+}
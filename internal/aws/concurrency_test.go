@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencyController(t *testing.T) {
+	c := newConcurrencyController(2, 5)
+	assert.Equal(t, 2, c.workers)
+
+	samples := []struct {
+		rate     float64
+		errors   int64
+		expected int
+	}{
+		{100, 0, 3}, // Climbing from nothing
+		{200, 0, 4}, // Still climbing
+		{300, 0, 5}, // Still climbing
+		{400, 0, 5}, // Capped at max
+		{405, 0, 4}, // Plateau sheds a worker
+		{300, 0, 5}, // Dropped after removing one, add it back
+		{300, 3, 4}, // Errors spiked
+		{300, 3, 3}, // Plateau
+		{200, 3, 4}, // Dropped after removing one, add it back
+		{100, 3, 3}, // Dropped after adding one, remove it again
+		{100, 3, 2}, // Plateau
+		{100, 3, 2}, // Plateau at min
+		{50, 3, 2},  // Dropped without a previous step, hold
+	}
+
+	for i, s := range samples {
+		assert.Equal(t, s.expected, c.next(s.rate, s.errors), "sample %d", i)
+	}
+}
+
+func TestNewConcurrencyControllerBounds(t *testing.T) {
+	c := newConcurrencyController(0, 0)
+	assert.Equal(t, 1, c.min)
+	assert.Equal(t, 1, c.max)
+
+	c = newConcurrencyController(8, 4)
+	assert.Equal(t, 8, c.min)
+	assert.Equal(t, 8, c.max)
+}
+
+func TestListAndDownloadObjectsAdaptiveConcurrency(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo", "c.txt": "charlie"})
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+	d.config.AdaptiveConcurrency = true
+	d.config.MinWorkers = 1
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), d.Progress().FilesDownloaded)
+	assert.Equal(t, int64(len("alpha")+len("bravo")+len("charlie")), d.Progress().TotalBytes)
+	assert.Equal(t, "charlie", sink.contents(filepath.Join("out", "c.txt")))
+}
@@ -0,0 +1,211 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/client"
+)
+
+// Config holds the options for a Downloader
+type Config struct {
+	MaxWorkers             int               // Number of files downloaded in parallel; each file uses its own worker
+	Concurrency            int               // Most parts of one large file downloaded in parallel, within its worker; smaller files use fewer
+	PartSize               int64             // Largest part, at least MinPartSize; files too small to fill Concurrency parts use smaller ones
+	DownloadTimeout        time.Duration     // Time allowed for one object of up to PartSize; larger objects get at least 30 minutes
+	ChannelBufferSize      int               // Number of listed objects buffered ahead of the workers; zero sizes it from MaxWorkers
+	GenerateIndex          bool              // Write an index.html listing the downloaded files
+	Profile                string            // Shared credentials profile used when no access keys are given
+	Anonymous              bool              // Send unsigned requests without any credentials, for public buckets
+	SessionToken           string            // STS session token sent with temporary access keys, which cannot be refreshed when they expire
+	RoleARN                string            // Role assumed on top of the base credentials, if set
+	ExternalID             string            // External ID required by the role's trust policy
+	RoleSessionName        string            // Session name for the assumed role, defaults to "s3downloader"
+	Endpoint               string            // URL of an S3-compatible service (MinIO, Wasabi, Spaces); implies path-style addressing
+	PathStyle              bool              // Use path-style addressing, needed for bucket names with dots; combines with Endpoint
+	Accelerate             bool              // Route requests through the S3 Transfer Acceleration endpoint; excludes Endpoint and PathStyle
+	ProxyURL               string            // HTTP(S) proxy for every request; empty uses HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+	CACertFile             string            // PEM file of extra CA certificates trusted for TLS, for services with a private CA
+	InsecureSkipVerify     bool              // Accept any TLS certificate; exposes the connection to interception, for testing only
+	SSECustomerKey         string            // SSE-C key, base64-encoded or raw, for objects encrypted with a customer key
+	SSECustomerAlgorithm   string            // SSE-C algorithm, defaults to AES256
+	RequesterPays          bool              // Accept the request charges of Requester Pays buckets
+	ResumePartial          bool              // Download sequentially and continue partial files from where they stopped
+	SkipUnchanged          bool              // Only skip existing local files whose content matches the object's ETag
+	Overwrite              bool              // Download objects again even when a local file already exists
+	VerifyChecksum         bool              // Re-read downloaded files and compare them to the object's MD5 ETag or SHA256 checksum
+	MaxBytesPerSec         int64             // Download rate limit shared by all workers; zero means unlimited
+	IncludePatterns        []string          // Only download listed keys matching one of these path.Match patterns, if any
+	ExcludePatterns        []string          // Never download listed keys matching one of these path.Match patterns
+	DownloadArchived       bool              // Also attempt objects in Glacier storage classes, which are skipped otherwise
+	RestoreArchived        bool              // Restore GLACIER and DEEP_ARCHIVE objects and wait for them before downloading
+	RestoreDays            int64             // Days a restored copy stays available, defaults to 1
+	RestoreTier            string            // Restore speed: Standard (default), Bulk or Expedited
+	RestorePollInterval    time.Duration     // How often a pending restore is checked, defaults to 5 minutes
+	ListVersions           bool              // Also download non-current versions, named with their version ID
+	MaxRecentFiles         int               // Only download the N most recently modified listed objects; zero downloads all
+	Flatten                bool              // Write every object directly into the download path under its base name
+	StripPrefix            bool              // Save listed objects relative to the listing prefix instead of under their full key
+	AdaptiveConcurrency    bool              // Start with MinWorkers and tune the worker count up to MaxWorkers from measured throughput
+	MinWorkers             int               // Lower bound for adaptive concurrency, defaults to 4
+	MaxRetries             int               // Retries of failed list, head and get requests; zero fails on the first error
+	RetryMaxBackoff        time.Duration     // Longest delay between retries; zero keeps the SDK default of 5 minutes
+	FileRetries            int               // Times a failed file is attempted again before it counts as an error
+	FileRetryBackoff       time.Duration     // Delay before the first file retry, doubled after each attempt; defaults to 1 second
+	FailFast               bool              // Stop the whole run, including in-flight downloads, on the first error
+	Mirror                 bool              // After a complete pass, delete local files under the prefix whose object was not listed
+	ReportPath             string            // Write a JSON report of every object's result and the run's totals to this file
+	ManifestPath           string            // Write a sha256sum-compatible manifest of the downloaded files to this file
+	DecompressGzip         bool              // Decompress objects stored with Content-Encoding gzip instead of saving them as-is
+	StripGzipSuffix        bool              // Drop the .gz suffix from the local name of decompressed objects
+	WriteMetadata          bool              // Save each downloaded object's metadata as JSON in a <file>.meta.json sidecar
+	HeadBeforeDownload     bool              // Confirm each object's size with HeadObject before downloading it; costs a request per object
+	TagFilters             map[string]string // Only download objects carrying all of these tags; each object costs a GetObjectTagging call
+	ContentTypePrefixes    []string          // Only download objects whose Content-Type starts with one of these; objects without a known extension cost a HeadObject call
+	CreateDirectoryMarkers bool              // Create a local directory for each zero-byte "folder/" placeholder, which are skipped otherwise
+	StateFile              string            // Log completed objects to this file and skip the ones it lists, to continue after a crash
+	CheckFreeSpace         bool              // Abort as soon as the listed objects no longer fit on the local download volume
+	FreeSpaceMargin        int64             // Bytes that must remain free on the volume after the download when CheckFreeSpace is set
+	FileMode               os.FileMode       // Permissions set on downloaded files; zero leaves those given by the umask
+	DirMode                os.FileMode       // Permissions of created directories before the umask, defaults to 0755
+	MarkExecutable         bool              // Make objects with a script or program Content-Type executable; each costs a HeadObject call
+	CleanPartialsOlderThan time.Duration     // Remove partial files older than this, left by crashed runs, from the download path first; zero keeps them
+}
+
+// DefaultConfig returns the default downloader configuration
+func DefaultConfig() Config {
+	return Config{
+		MaxWorkers:      100,
+		Concurrency:     10,
+		PartSize:        10 * 1024 * 1024, // 10MB chunks for large files
+		DownloadTimeout: 5 * time.Minute,
+		MaxRetries:      3,
+		CheckFreeSpace:  true,
+		FreeSpaceMargin: 100 * 1024 * 1024, // Room for the system and other programs
+	}
+}
+
+// Performance preset names, from gentlest to fastest
+const (
+	PresetConservative = "Conservative"
+	PresetBalanced     = "Balanced"
+	PresetAggressive   = "Aggressive"
+)
+
+// PerformancePresets lists the preset names in display order
+var PerformancePresets = []string{PresetConservative, PresetBalanced, PresetAggressive}
+
+// ConservativeConfig suits slow or shared connections: 10 files at a time,
+// 3 parts of 5MB per large file
+func ConservativeConfig() Config {
+	cfg := DefaultConfig()
+	cfg.MaxWorkers = 10
+	cfg.Concurrency = 3
+	cfg.PartSize = MinPartSize
+	return cfg
+}
+
+// AggressiveConfig suits fast dedicated links: 200 files at a time,
+// 16 parts of 16MB per large file
+func AggressiveConfig() Config {
+	cfg := DefaultConfig()
+	cfg.MaxWorkers = 200
+	cfg.Concurrency = 16
+	cfg.PartSize = 16 * 1024 * 1024
+	return cfg
+}
+
+// PresetConfig returns the configuration for a preset name; unknown names
+// and PresetBalanced return DefaultConfig
+func PresetConfig(name string) Config {
+	switch name {
+	case PresetConservative:
+		return ConservativeConfig()
+	case PresetAggressive:
+		return AggressiveConfig()
+	default:
+		return DefaultConfig()
+	}
+}
+
+// StalePartialAge is how old a partial file must be before a run with partial file
+// cleanup removes it, long enough that no running download still writes to it
+const StalePartialAge = 24 * time.Hour
+
+// listPageSize is the number of objects S3 returns per listing page
+const listPageSize = 1000
+
+// bufferPerWorker is the number of listed objects buffered for each worker by default
+const bufferPerWorker = 20
+
+// channelBufferSize returns the number of listed objects buffered ahead of the workers.
+// By default it holds at least a whole listing page, so that the next page is requested
+// while the workers still have a page of work, and more for many workers, which drain it faster.
+func (c Config) channelBufferSize() int {
+	if c.ChannelBufferSize > 0 {
+		return c.ChannelBufferSize
+	}
+	return max(listPageSize, bufferPerWorker*c.MaxWorkers)
+}
+
+// MinPartSize is the smallest part S3 accepts in a multipart transfer; only the
+// last part of an object may be smaller
+const MinPartSize = 5 * 1024 * 1024
+
+// Validate checks the worker, part, credential and endpoint settings, reporting every invalid one. At most
+// MaxWorkers × Concurrency parts, each buffered up to PartSize, are in flight at once.
+func (c Config) Validate() error {
+	var errs []error
+	if c.MaxWorkers <= 0 {
+		errs = append(errs, fmt.Errorf("max workers (files downloaded in parallel) must be positive, got %d", c.MaxWorkers))
+	}
+	if c.Concurrency <= 0 {
+		errs = append(errs, fmt.Errorf("concurrency (parts of one file downloaded in parallel) must be positive, got %d", c.Concurrency))
+	}
+	if c.PartSize < MinPartSize {
+		errs = append(errs, fmt.Errorf("part size must be at least %d bytes (5MB), got %d", MinPartSize, c.PartSize))
+	}
+	if c.DownloadTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("download timeout must be positive, got %s", c.DownloadTimeout))
+	}
+	if c.Anonymous && c.RoleARN != "" {
+		errs = append(errs, fmt.Errorf("anonymous access cannot assume role '%s'", c.RoleARN))
+	}
+	if c.Accelerate && c.Endpoint != "" {
+		errs = append(errs, fmt.Errorf("transfer acceleration cannot be used with custom endpoint '%s'", c.Endpoint))
+	}
+	if c.Accelerate && c.PathStyle {
+		errs = append(errs, errors.New("transfer acceleration cannot be used with path-style addressing"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid downloader configuration: %w", err)
+	}
+	return nil
+}
+
+// retryer builds the retry policy for every request the downloader's clients make
+func retryer(cfg Config) client.DefaultRetryer {
+	return client.DefaultRetryer{
+		NumMaxRetries:    cfg.MaxRetries,
+		MaxRetryDelay:    cfg.RetryMaxBackoff,
+		MaxThrottleDelay: cfg.RetryMaxBackoff,
+	}
+}
+
+// validateEndpoint checks that a custom endpoint, if any, is an absolute http(s) URL
+func validateEndpoint(endpoint string) error {
+	if endpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint URL '%s': %w", endpoint, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid endpoint URL '%s': must be an http or https URL with a host", endpoint)
+	}
+	return nil
+}
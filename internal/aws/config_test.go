@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresetConfig(t *testing.T) {
+	assert.Equal(t, ConservativeConfig(), PresetConfig(PresetConservative))
+	assert.Equal(t, DefaultConfig(), PresetConfig(PresetBalanced))
+	assert.Equal(t, AggressiveConfig(), PresetConfig(PresetAggressive))
+	assert.Equal(t, DefaultConfig(), PresetConfig("unknown"))
+
+	conservative, balanced, aggressive := ConservativeConfig(), DefaultConfig(), AggressiveConfig()
+	assert.Less(t, conservative.MaxWorkers, balanced.MaxWorkers)
+	assert.Less(t, balanced.MaxWorkers, aggressive.MaxWorkers)
+	assert.Less(t, conservative.Concurrency, balanced.Concurrency)
+	assert.Less(t, balanced.Concurrency, aggressive.Concurrency)
+}
+
+func TestRetryConfig(t *testing.T) {
+	testCases := []struct {
+		name       string
+		maxRetries int
+		backoff    time.Duration
+	}{
+		{"Default", DefaultConfig().MaxRetries, 0},
+		{"Disabled", 0, 0},
+		{"Custom backoff", 5, 10 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.MaxRetries = tc.maxRetries
+			cfg.RetryMaxBackoff = tc.backoff
+			d, err := NewDownloaderWithConfig("us-east-1", "AKID", "SECRET", cfg)
+			assert.NoError(t, err)
+
+			svc := d.s3.(*s3.S3)
+			assert.Equal(t, tc.maxRetries, svc.MaxRetries())
+			if retryer, ok := svc.Retryer.(client.DefaultRetryer); assert.True(t, ok) {
+				assert.Equal(t, tc.backoff, retryer.MaxRetryDelay)
+			}
+		})
+	}
+	assert.Equal(t, 3, DefaultConfig().MaxRetries)
+}
+
+func TestConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr []string
+	}{
+		{"Default", func(*Config) {}, nil},
+		{"Conservative", func(c *Config) { *c = ConservativeConfig() }, nil},
+		{"Aggressive", func(c *Config) { *c = AggressiveConfig() }, nil},
+		{"Single worker and part", func(c *Config) { c.MaxWorkers, c.Concurrency = 1, 1 }, nil},
+		{"No workers", func(c *Config) { c.MaxWorkers = 0 }, []string{"max workers (files downloaded in parallel) must be positive, got 0"}},
+		{"Negative concurrency", func(c *Config) { c.Concurrency = -2 }, []string{"concurrency (parts of one file downloaded in parallel) must be positive, got -2"}},
+		{"Part below S3 minimum", func(c *Config) { c.PartSize = MinPartSize - 1 }, []string{"part size must be at least 5242880 bytes (5MB), got 5242879"}},
+		{"No timeout", func(c *Config) { c.DownloadTimeout = 0 }, []string{"download timeout must be positive, got 0s"}},
+		{"Accelerate", func(c *Config) { c.Accelerate = true }, nil},
+		{"Accelerate with endpoint", func(c *Config) { c.Accelerate, c.Endpoint = true, "http://localhost:9000" },
+			[]string{"transfer acceleration cannot be used with custom endpoint 'http://localhost:9000'"}},
+		{"Accelerate with path style", func(c *Config) { c.Accelerate, c.PathStyle = true, true }, []string{"transfer acceleration cannot be used with path-style addressing"}},
+		{"Every problem", func(c *Config) { *c = Config{} }, []string{"max workers", "concurrency", "part size", "download timeout"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tc.modify(&cfg)
+
+			err := cfg.Validate()
+
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, "invalid downloader configuration")
+			for _, want := range tc.wantErr {
+				assert.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
+func TestNewDownloaderValidatesConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PartSize = 1024
+
+	d, err := NewDownloaderWithConfig("us-east-1", "AKID", "SECRET", cfg)
+
+	assert.Nil(t, d)
+	assert.ErrorContains(t, err, "part size must be at least")
+}
+
+func TestTransferTimeout(t *testing.T) {
+	d := newTestDownloader(newFakeS3(nil), newMemorySink())
+	d.config.DownloadTimeout = 2 * time.Minute
+	assert.Equal(t, 2*time.Minute, d.transferTimeout(d.config.PartSize))
+	assert.Equal(t, largeFileTimeout, d.transferTimeout(d.config.PartSize+1))
+
+	d.config.DownloadTimeout = 2 * time.Hour
+	assert.Equal(t, 2*time.Hour, d.transferTimeout(d.config.PartSize+1))
+}
@@ -0,0 +1,38 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// matchesContentType reports whether the Content-Type of obj starts with one of
+// ContentTypePrefixes. The type implied by a known extension is trusted, so only
+// objects without one cost a HeadObject call.
+func (d *Downloader) matchesContentType(ctx context.Context, bucket string, obj *target) (bool, error) {
+	key := aws.StringValue(obj.Key)
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return d.contentTypeMatches(contentType), nil
+	}
+
+	out, err := d.headObject(ctx, bucket, obj)
+	if err != nil {
+		return false, fmt.Errorf("failed to get content type of '%s': %w", key, err)
+	}
+	return d.contentTypeMatches(aws.StringValue(out.ContentType)), nil
+}
+
+// contentTypeMatches reports whether contentType starts with one of ContentTypePrefixes, ignoring case
+func (d *Downloader) contentTypeMatches(contentType string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	for _, prefix := range d.config.ContentTypePrefixes {
+		if strings.HasPrefix(contentType, strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
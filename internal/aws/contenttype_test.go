@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentTypePrefixes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		prefixes  []string
+		wantFiles []string
+		wantHeads []string
+	}{
+		{"No filter", nil, []string{"diagram", "notes", "photo.png", "report.json"}, nil},
+		{"Images", []string{"image/"}, []string{"diagram", "photo.png"}, []string{"diagram", "notes"}},
+		{"Case-insensitive", []string{"IMAGE/"}, []string{"diagram", "photo.png"}, []string{"diagram", "notes"}},
+		{"Several prefixes", []string{"image/png", "text/"}, []string{"notes", "photo.png"}, []string{"diagram", "notes"}},
+		{"Nothing matches", []string{"video/"}, nil, []string{"diagram", "notes"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			keys := []string{"diagram", "notes", "photo.png", "report.json"}
+			client := newFakeS3(map[string]string{"diagram": "d", "notes": "n", "photo.png": "p", "report.json": "r"})
+			client.types["diagram"] = "image/svg+xml"
+			client.types["notes"] = "text/plain; charset=utf-8"
+			client.types["photo.png"] = "image/png"
+			client.types["report.json"] = "application/json"
+			sink := newMemorySink()
+			d := newTestDownloader(client, sink)
+			d.config.ContentTypePrefixes = tc.prefixes
+
+			err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+			assert.NoError(t, err)
+			var got []string
+			for _, key := range keys {
+				if sink.Exists("out/" + key) {
+					got = append(got, key)
+				}
+			}
+			assert.Equal(t, tc.wantFiles, got)
+
+			// Objects with a known extension are matched without HeadObject
+			var heads []string
+			for _, head := range client.heads {
+				heads = append(heads, aws.StringValue(head.Key))
+			}
+			assert.ElementsMatch(t, tc.wantHeads, heads)
+
+			p := d.Progress()
+			assert.Equal(t, int64(len(tc.wantFiles)), p.FilesDownloaded)
+			assert.Equal(t, int64(len(keys)-len(tc.wantFiles)), p.TypeFiltered)
+			assert.Equal(t, int64(len(tc.wantFiles)), p.TotalBytesExpected)
+		})
+	}
+}
+
+func TestContentTypeHeadFailure(t *testing.T) {
+	client := newFakeS3(map[string]string{"photo.png": "p", "scan": "s"})
+	client.headFails["scan"] = true
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+	d.config.ContentTypePrefixes = []string{"image/"}
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+	assert.ErrorContains(t, err, "failed to get content type of 'scan'")
+	assert.True(t, sink.Exists("out/photo.png"))
+	assert.False(t, sink.Exists("out/scan"))
+	p := d.Progress()
+	assert.Equal(t, int64(1), p.ErrorCount)
+	assert.Equal(t, int64(0), p.TypeFiltered)
+}
+
+func TestContentTypeLookupIsShared(t *testing.T) {
+	client := newFakeS3(map[string]string{"scan": "s"})
+	client.types["scan"] = "image/tiff"
+	d := newTestDownloader(client, newMemorySink())
+	d.config.ContentTypePrefixes = []string{"image/"}
+	d.config.HeadBeforeDownload = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, client.heads, 1)
+}
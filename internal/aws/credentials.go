@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// defaultRoleSessionName is used when assuming a role without an explicit session name
+const defaultRoleSessionName = "s3downloader"
+
+// roleExpiryWindow is how long before they expire assumed role credentials are
+// renewed, as the SDK does for instance and container roles, so that no request
+// is signed with credentials about to expire
+const roleExpiryWindow = 5 * time.Minute
+
+// withAssumedRole returns a copy of sess whose credentials assume cfg.RoleARN through
+// client, using the credentials of sess as the source identity. The session keeps
+// the provider rather than the credentials it returned, so the SDK assumes the
+// role again whenever they expire during a long download. Nothing is called
+// until the credentials are first needed, see CheckCredentials.
+func withAssumedRole(sess *session.Session, client stscreds.AssumeRoler, cfg Config) *session.Session {
+	sessionName := cfg.RoleSessionName
+	if sessionName == "" {
+		sessionName = defaultRoleSessionName
+	}
+
+	creds := stscreds.NewCredentialsWithClient(client, cfg.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+		p.RoleSessionName = sessionName
+		p.ExpiryWindow = roleExpiryWindow
+		if cfg.ExternalID != "" {
+			p.ExternalID = aws.String(cfg.ExternalID)
+		}
+	})
+	return sess.Copy(&aws.Config{Credentials: creds})
+}
+
+// CheckCredentials resolves the downloader's credentials, which for instance, web identity
+// and assumed roles means calling the metadata service or STS, and fails if there are none.
+// Every download checks them first; callers that must stay responsive can check them
+// earlier under their own deadline.
+func (d *Downloader) CheckCredentials(ctx context.Context) error {
+	// A downloader built around a given client signs with that client's credentials,
+	// and anonymous requests are not signed at all
+	if d.sess == nil || d.sess.Config.Credentials == credentials.AnonymousCredentials {
+		return nil
+	}
+	if _, err := d.sess.Config.Credentials.GetWithContext(ctx); err != nil {
+		switch {
+		case d.config.RoleARN != "":
+			return fmt.Errorf("failed to assume role '%s': %w", d.config.RoleARN, err)
+		case errors.Is(err, credentials.ErrNoValidProvidersFoundInChain):
+			return fmt.Errorf("no AWS credentials found in the environment, shared credentials file or instance role: %w", err)
+		default:
+			return fmt.Errorf("failed to load AWS credentials: %w", err)
+		}
+	}
+	return nil
+}
+
+// Identity describes the AWS principal the downloader's credentials belong to
+type Identity struct {
+	Account string
+	ARN     string
+}
+
+// ValidateCredentials checks the credentials with STS and returns the identity they resolve to
+func (d *Downloader) ValidateCredentials(ctx context.Context) (Identity, error) {
+	out, err := d.sts.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to validate AWS credentials: %w", err)
+	}
+	return Identity{Account: aws.StringValue(out.Account), ARN: aws.StringValue(out.Arn)}, nil
+}
@@ -0,0 +1,364 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSharedCredentials(t *testing.T) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "credentials")
+	content := "[default]\naws_access_key_id = DEFAULTKEY\naws_secret_access_key = defaultsecret\n\n" +
+		"[work]\naws_access_key_id = WORKKEY\naws_secret_access_key = worksecret\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	t.Setenv("AWS_PROFILE", "")
+}
+
+func TestNewDownloaderWithProfile(t *testing.T) {
+	writeSharedCredentials(t)
+
+	testCases := []struct {
+		name        string
+		profile     string
+		expectedKey string
+		wantErr     bool
+	}{
+		{"Named profile", "work", "WORKKEY", false},
+		{"Empty profile uses default", "", "DEFAULTKEY", false},
+		{"Missing profile", "missing", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := NewDownloaderWithProfile("eu-west-1", tc.profile)
+			if tc.wantErr {
+				assert.ErrorContains(t, err, "failed to load AWS profile 'missing'")
+				return
+			}
+			assert.NoError(t, err)
+			creds, err := d.sess.Config.Credentials.Get()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedKey, creds.AccessKeyID)
+		})
+	}
+}
+
+func TestNewDownloaderWithoutProfile(t *testing.T) {
+	t.Run("AWS_PROFILE", func(t *testing.T) {
+		writeSharedCredentials(t)
+		t.Setenv("AWS_PROFILE", "work")
+
+		d, err := NewDownloaderWithProfile("eu-west-1", "")
+		assert.NoError(t, err)
+		creds, err := d.sess.Config.Credentials.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, "WORKKEY", creds.AccessKeyID)
+	})
+
+	t.Run("No shared credentials file", func(t *testing.T) {
+		t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "missing"))
+		t.Setenv("AWS_PROFILE", "")
+		t.Setenv("AWS_ACCESS_KEY_ID", "ENVKEY")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+
+		d, err := NewDownloaderWithProfile("eu-west-1", "")
+		assert.NoError(t, err)
+		creds, err := d.sess.Config.Credentials.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, "ENVKEY", creds.AccessKeyID)
+	})
+}
+
+func TestStaticKeysTakePrecedenceOverProfile(t *testing.T) {
+	writeSharedCredentials(t)
+
+	cfg := DefaultConfig()
+	cfg.Profile = "work"
+	d, err := NewDownloaderWithConfig("eu-west-1", "STATICKEY", "staticsecret", cfg)
+	assert.NoError(t, err)
+
+	creds, err := d.sess.Config.Credentials.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "STATICKEY", creds.AccessKeyID)
+}
+
+func TestSessionTokenIsPassedToStaticCredentials(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SessionToken = "token123"
+	d, err := NewDownloaderWithConfig("eu-west-1", "TEMPKEY", "tempsecret", cfg)
+	assert.NoError(t, err)
+
+	creds, err := d.sess.Config.Credentials.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "TEMPKEY", creds.AccessKeyID)
+	assert.Equal(t, "token123", creds.SessionToken)
+}
+
+// TestTemporaryCredentialsIntegration runs against a real bucket using temporary
+// credentials taken from S3DL_TEST_BUCKET and the standard AWS environment variables
+func TestTemporaryCredentialsIntegration(t *testing.T) {
+	bucket := os.Getenv("S3DL_TEST_BUCKET")
+	token := os.Getenv("AWS_SESSION_TOKEN")
+	if bucket == "" || token == "" {
+		t.Skip("S3DL_TEST_BUCKET and AWS_SESSION_TOKEN not set")
+	}
+
+	cfg := DefaultConfig()
+	cfg.SessionToken = token
+	d, err := NewDownloaderWithConfig(os.Getenv("AWS_REGION"), os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), cfg)
+	assert.NoError(t, err)
+
+	_, err = d.ListPrefixes(bucket, "")
+	assert.NoError(t, err)
+}
+
+// fakeSTS answers AssumeRole with credentials valid for lifetime, an hour by
+// default, or an error. Later calls get new keys: ASSUMEDKEY2, ASSUMEDKEY3...
+type fakeSTS struct {
+	err      error
+	lifetime time.Duration
+	mu       sync.Mutex
+	inputs   []*sts.AssumeRoleInput
+}
+
+func (f *fakeSTS) AssumeRole(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.inputs = append(f.inputs, input)
+	if f.err != nil {
+		return nil, f.err
+	}
+	key := "ASSUMEDKEY"
+	if n := len(f.inputs); n > 1 {
+		key += strconv.Itoa(n)
+	}
+	lifetime := f.lifetime
+	if lifetime == 0 {
+		lifetime = time.Hour
+	}
+	return &sts.AssumeRoleOutput{Credentials: &sts.Credentials{
+		AccessKeyId:     aws.String(key),
+		SecretAccessKey: aws.String("assumedsecret"),
+		SessionToken:    aws.String("assumedtoken"),
+		Expiration:      aws.Time(time.Now().Add(lifetime)),
+	}}, nil
+}
+
+// calls returns how often AssumeRole was called
+func (f *fakeSTS) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.inputs)
+}
+
+func TestWithAssumedRole(t *testing.T) {
+	base, err := session.NewSession(&aws.Config{
+		Region:      aws.String("eu-west-1"),
+		Credentials: credentials.NewStaticCredentials("BASEKEY", "basesecret", ""),
+	})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name                string
+		cfg                 Config
+		expectedSessionName string
+		expectedExternalID  *string
+	}{
+		{"Defaults", Config{RoleARN: "arn:aws:iam::123456789012:role/reader"}, defaultRoleSessionName, nil},
+		{"Custom session and external ID", Config{RoleARN: "arn:aws:iam::123456789012:role/reader", RoleSessionName: "nightly", ExternalID: "ext-1"}, "nightly", aws.String("ext-1")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeSTS{}
+			sess := withAssumedRole(base, client, tc.cfg)
+			assert.Zero(t, client.calls(), "the role is assumed when the credentials are first needed")
+			assert.NotSame(t, base.Config.Credentials, sess.Config.Credentials)
+
+			creds, err := sess.Config.Credentials.Get()
+			assert.NoError(t, err)
+			assert.Equal(t, "ASSUMEDKEY", creds.AccessKeyID)
+			assert.Equal(t, stscreds.ProviderName, creds.ProviderName)
+
+			assert.Len(t, client.inputs, 1)
+			assert.Equal(t, tc.cfg.RoleARN, aws.StringValue(client.inputs[0].RoleArn))
+			assert.Equal(t, tc.expectedSessionName, aws.StringValue(client.inputs[0].RoleSessionName))
+			assert.Equal(t, tc.expectedExternalID, client.inputs[0].ExternalId)
+		})
+	}
+}
+
+func TestAssumedRoleCredentialsAreRefreshed(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Authorization: AWS4-HMAC-SHA256 Credential=<key>/<date>/<region>/s3/aws4_request, ...
+		credential := strings.TrimPrefix(strings.Fields(r.Header.Get("Authorization"))[1], "Credential=")
+		mu.Lock()
+		keys = append(keys, strings.Split(credential, "/")[0])
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	base, err := session.NewSession(&aws.Config{
+		Region:      aws.String("eu-west-1"),
+		Credentials: credentials.NewStaticCredentials("BASEKEY", "basesecret", ""),
+	})
+	assert.NoError(t, err)
+
+	// The credentials count as expired this long after they are issued
+	const valid = 300 * time.Millisecond
+	client := &fakeSTS{lifetime: roleExpiryWindow + valid}
+	sess := withAssumedRole(base, client, Config{RoleARN: "arn:aws:iam::123456789012:role/reader"})
+	s3Client := s3.New(sess, &aws.Config{Endpoint: aws.String(server.URL), S3ForcePathStyle: aws.Bool(true)})
+
+	_, err = s3Client.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String("bucket")})
+	assert.NoError(t, err)
+	_, err = s3Client.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String("bucket")})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, client.calls(), "valid credentials are reused")
+
+	time.Sleep(valid + 100*time.Millisecond)
+	_, err = s3Client.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String("bucket")})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, client.calls(), "expired credentials are fetched again")
+	assert.Equal(t, []string{"ASSUMEDKEY", "ASSUMEDKEY", "ASSUMEDKEY2"}, keys)
+}
+
+func TestWithAssumedRoleFailure(t *testing.T) {
+	base, err := session.NewSession(&aws.Config{Region: aws.String("eu-west-1")})
+	assert.NoError(t, err)
+
+	client := &fakeSTS{err: errors.New("AccessDenied")}
+	cfg := Config{RoleARN: "arn:aws:iam::123456789012:role/reader"}
+	d := &Downloader{sess: withAssumedRole(base, client, cfg), config: cfg}
+	err = d.CheckCredentials(context.Background())
+	assert.ErrorContains(t, err, "failed to assume role 'arn:aws:iam::123456789012:role/reader'")
+	assert.ErrorContains(t, err, "AccessDenied")
+}
+
+func TestDefaultCredentialChainUsesEnvironment(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "ENVKEY")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+
+	d, err := NewDownloaderWithConfig("eu-west-1", "", "", DefaultConfig())
+	assert.NoError(t, err)
+
+	creds, err := d.sess.Config.Credentials.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "ENVKEY", creds.AccessKeyID)
+}
+
+func TestDefaultCredentialChainUsesProfileSources(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential_process runs through sh")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "credentials.sh")
+	output := `{"Version": 1, "AccessKeyId": "PROCESSKEY", "SecretAccessKey": "processsecret"}`
+	assert.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho '"+output+"'\n"), 0o700))
+	path := filepath.Join(dir, "credentials")
+	assert.NoError(t, os.WriteFile(path, []byte("[tool]\ncredential_process = "+script+"\n"), 0o600))
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	t.Setenv("AWS_PROFILE", "tool")
+
+	d, err := NewDownloaderWithConfig("eu-west-1", "", "", DefaultConfig())
+	assert.NoError(t, err)
+	assert.NoError(t, d.CheckCredentials(context.Background()))
+
+	creds, err := d.sess.Config.Credentials.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "PROCESSKEY", creds.AccessKeyID, "the profile selected by AWS_PROFILE is used as the SDK resolves it")
+}
+
+func TestDefaultCredentialChainEmpty(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	d, err := NewDownloaderWithConfig("eu-west-1", "", "", DefaultConfig())
+	assert.NoError(t, err)
+	assert.ErrorContains(t, d.CheckCredentials(context.Background()), "no AWS credentials found")
+	assert.ErrorContains(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", t.TempDir(), nil), "no AWS credentials found")
+}
+
+// hangingSTS answers AssumeRole only once the request is canceled, like an unreachable endpoint
+type hangingSTS struct{ fakeSTS }
+
+func (f *hangingSTS) AssumeRoleWithContext(ctx aws.Context, _ *sts.AssumeRoleInput, _ ...request.Option) (*sts.AssumeRoleOutput, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestCheckCredentialsHonorsContext(t *testing.T) {
+	base, err := session.NewSession(&aws.Config{
+		Region:      aws.String("eu-west-1"),
+		Credentials: credentials.NewStaticCredentials("BASEKEY", "basesecret", ""),
+	})
+	assert.NoError(t, err)
+
+	cfg := Config{RoleARN: "arn:aws:iam::123456789012:role/reader"}
+	d := &Downloader{sess: withAssumedRole(base, &hangingSTS{}, cfg), config: cfg}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = d.CheckCredentials(ctx)
+	assert.ErrorContains(t, err, "failed to assume role")
+	assert.ErrorContains(t, err, "context deadline exceeded")
+}
+
+// fakeCallerIdentity answers GetCallerIdentity with a fixed identity or an error
+type fakeCallerIdentity struct {
+	stsiface.STSAPI
+	err error
+}
+
+func (f *fakeCallerIdentity) GetCallerIdentityWithContext(aws.Context, *sts.GetCallerIdentityInput, ...request.Option) (*sts.GetCallerIdentityOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &sts.GetCallerIdentityOutput{
+		Account: aws.String("123456789012"),
+		Arn:     aws.String("arn:aws:iam::123456789012:user/downloader"),
+	}, nil
+}
+
+func TestValidateCredentials(t *testing.T) {
+	d := &Downloader{sts: &fakeCallerIdentity{}}
+	identity, err := d.ValidateCredentials(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, Identity{Account: "123456789012", ARN: "arn:aws:iam::123456789012:user/downloader"}, identity)
+
+	d = &Downloader{sts: &fakeCallerIdentity{err: errors.New("InvalidClientTokenId")}}
+	_, err = d.ValidateCredentials(context.Background())
+	assert.ErrorContains(t, err, "failed to validate AWS credentials")
+	assert.ErrorContains(t, err, "InvalidClientTokenId")
+}
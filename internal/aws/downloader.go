@@ -3,113 +3,505 @@ package aws
 import (
 	"context"
 	"fmt"
-	"os"
 	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"s3downloader/internal/logging"
+	"s3downloader/internal/metrics"
 	"s3downloader/internal/progress"
+	"s3downloader/internal/redact"
 	"s3downloader/pkg/fileutils"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"golang.org/x/time/rate"
 )
 
 // Downloader struct handles AWS sessions and S3 operations
 type Downloader struct {
-	sess *session.Session
-	s3   *s3.S3
+	sess     *session.Session
+	s3       s3iface.S3API
+	sts      stsiface.STSAPI
+	sink     Sink
+	config   Config
+	tracker  atomic.Pointer[progress.Tracker]
+	failures atomic.Pointer[failureLog]       // Failed objects of the current or last run
+	reports  atomic.Pointer[progressReporter] // Intra-file reports of the running download, if any
+	pause    pauseGate
+	sseKey   *sseCustomerKey
+	limiter  *rate.Limiter // Shared by all workers, nil when unlimited
+	logSink  LogSink
+	logger   *logging.Logger  // Audit trail of runs and objects, nil when off
+	metrics  *metrics.Metrics // Counters for scraping, nil when off
 }
 
-// NewDownloader initializes a new Downloader with AWS credentials
+// NewDownloader initializes a new Downloader with AWS credentials and the default configuration
 func NewDownloader(region, accessKey, secretKey string) (*Downloader, error) {
-	config := &aws.Config{
-		Region: aws.String(region),
+	return NewDownloaderWithConfig(region, accessKey, secretKey, DefaultConfig())
+}
+
+// NewDownloaderWithProfile initializes a new Downloader using a named profile from the
+// shared credentials file (~/.aws/credentials). An empty profileName leaves the choice to
+// the default credential chain, which honors AWS_PROFILE and works without that file.
+func NewDownloaderWithProfile(region, profileName string) (*Downloader, error) {
+	cfg := DefaultConfig()
+	cfg.Profile = profileName
+	return NewDownloaderWithConfig(region, "", "", cfg)
+}
+
+// NewDownloaderWithConfig initializes a new Downloader with AWS credentials and the given configuration.
+// Access keys given here, with or without cfg.SessionToken, cannot be refreshed: a run with
+// temporary keys fails once they expire. Assumed roles and the instance or container roles
+// of the default chain are renewed automatically, so long downloads should use those.
+func NewDownloaderWithConfig(region, accessKey, secretKey string, cfg Config) (*Downloader, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateEndpoint(cfg.Endpoint); err != nil {
+		return nil, err
+	}
+	if err := validatePatterns(cfg); err != nil {
+		return nil, err
+	}
+	if err := validateRestoreTier(cfg.RestoreTier); err != nil {
+		return nil, err
 	}
-	if accessKey != "" && secretKey != "" {
-		config.Credentials = credentials.NewStaticCredentials(accessKey, secretKey, "")
+	sseKey, err := resolveSSECustomerKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// Errors and logs may quote these; they must not show up in plain text
+	redact.Register(secretKey, cfg.SessionToken, cfg.SSECustomerKey)
+
+	awsConfig := &aws.Config{
+		Region:     aws.String(region),
+		MaxRetries: aws.Int(cfg.MaxRetries),
+		Retryer:    retryer(cfg),
+		HTTPClient: httpClient,
+	}
+	if cfg.Anonymous {
+		// Public buckets refuse requests signed with invalid credentials, so send none
+		awsConfig.Credentials = credentials.AnonymousCredentials
+	} else if accessKey != "" && secretKey != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(accessKey, secretKey, cfg.SessionToken)
+	} else if cfg.Profile != "" {
+		creds := credentials.NewSharedCredentials("", cfg.Profile)
+		if _, err := creds.Get(); err != nil {
+			return nil, fmt.Errorf("failed to load AWS profile '%s': %w", cfg.Profile, err)
+		}
+		awsConfig.Credentials = creds
 	}
-	sess, err := session.NewSession(config)
+	// Without any, the session resolves them as the AWS CLI does: environment, profiles
+	// with SSO, credential_process or role_arn, web identity and instance roles
+	sess, err := session.NewSession(awsConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
-	return &Downloader{sess: sess, s3: s3.New(sess)}, nil
+	if err := configureTLS(httpClient, cfg); err != nil {
+		return nil, err
+	}
+	// Roles may need the network, so credentials are resolved later by CheckCredentials
+	if cfg.RoleARN != "" {
+		// The STS client inherits the session's region and retry settings
+		sess = withAssumedRole(sess, sts.New(sess), cfg)
+	}
+
+	// Endpoint settings only apply to the S3 client, other services keep their AWS endpoints
+	// Path-style addressing avoids TLS failures on dotted bucket names and is what
+	// most S3-compatible services expect, so a custom endpoint always enables it
+	s3Config := &aws.Config{}
+	if cfg.PathStyle || cfg.Endpoint != "" {
+		s3Config.S3ForcePathStyle = aws.Bool(true)
+	}
+	if cfg.Endpoint != "" {
+		s3Config.Endpoint = aws.String(cfg.Endpoint)
+	}
+	if cfg.Accelerate {
+		s3Config.S3UseAccelerate = aws.Bool(true)
+	}
+	return &Downloader{sess: sess, s3: s3.New(sess, s3Config), sts: sts.New(sess), sink: LocalSink{}, config: cfg, sseKey: sseKey, limiter: newRateLimiter(cfg.MaxBytesPerSec)}, nil
+}
+
+// SetSink redirects downloaded files to the given destination instead of the local filesystem
+func (d *Downloader) SetSink(sink Sink) {
+	d.sink = sink
+}
+
+// Progress returns a snapshot of the counters of the current or last run
+func (d *Downloader) Progress() progress.Progress {
+	if t := d.tracker.Load(); t != nil {
+		return t.Snapshot()
+	}
+	return progress.Progress{}
+}
+
+// report passes the current progress to observer; a nil observer disables reporting
+func report(observer ProgressObserver, tracker *progress.Tracker) {
+	if observer != nil {
+		observer.OnProgress(tracker.Snapshot())
+	}
 }
 
-// ListAndDownloadObjects lists and downloads S3 objects concurrently
+// ListAndDownloadObjects lists and downloads S3 objects concurrently. Objects filtered out
+// by the include and exclude patterns are never counted as found. With ListVersions set,
+// non-current versions are downloaded as well. Progress is sent to progressChan when it
+// is non-nil, until ctx is canceled, and can always be polled through Progress. The
+// channel receives nothing once the method has returned, so it may be closed then.
 func (d *Downloader) ListAndDownloadObjects(ctx context.Context, bucket, prefix, downloadPath string, progressChan chan<- progress.Progress) error {
-	const (
-		maxWorkers        = 100              // Maximum number of workers
-		chunkSize         = 10 * 1024 * 1024 // 10MB chunks for large files
-		channelBufferSize = 2000             // Channel buffer size
-	)
-
-	var (
-		foundFiles     int64
-		processedFiles int64
-		skippedFiles   int64
-	)
-
-	fileChan := make(chan *s3.Object, channelBufferSize)
-	errChan := make(chan error, maxWorkers)
+	return d.ListAndDownloadObjectsWithObserver(ctx, bucket, prefix, downloadPath, newChannelObserver(ctx, progressChan))
+}
+
+// ListAndDownloadObjectsWithObserver works like ListAndDownloadObjects but reports progress
+// to observer, which may be nil. OnComplete is called exactly once, with the final
+// progress, after every OnProgress call and whether or not the run succeeded.
+func (d *Downloader) ListAndDownloadObjectsWithObserver(ctx context.Context, bucket, prefix, downloadPath string, observer ProgressObserver) error {
+	err := d.listAndDownload(ctx, bucket, prefix, downloadPath, observer)
+	if observer != nil {
+		observer.OnComplete(d.Progress())
+	}
+	return err
+}
+
+// listAndDownload implements ListAndDownloadObjectsWithObserver
+func (d *Downloader) listAndDownload(ctx context.Context, bucket, prefix, downloadPath string, observer ProgressObserver) error {
+	produce := d.listPrefix(bucket, prefix)
+
+	var keep *keptKeys
+	if d.config.Mirror {
+		keep = newKeptKeys()
+		produce = keep.record(produce)
+	}
+
+	if err := d.runDownload(ctx, bucket, downloadPath, observer, produce); err != nil || keep == nil {
+		return d.withAnonymousHint(d.withBucketRegion(ctx, bucket, err))
+	}
+	return d.mirror(ctx, downloadPath, prefix, keep, observer)
+}
+
+// listPrefix produces the objects under prefix with the local names the
+// configuration asks for
+func (d *Downloader) listPrefix(bucket, prefix string) objectProducer {
+	produce := d.listObjects(bucket, prefix)
+	if d.config.ListVersions {
+		produce = d.listVersions(bucket, prefix)
+	}
+	if d.config.StripPrefix {
+		produce = stripPrefix(produce, prefix)
+	}
+	if d.config.Flatten {
+		produce = flatten(produce)
+	}
+	return produce
+}
+
+// listObjects produces the current objects under prefix
+func (d *Downloader) listObjects(bucket, prefix string) objectProducer {
+	return func(ctx context.Context, enqueue func(target) bool) error {
+		// Selecting the newest objects needs the full listing, otherwise objects stream to the workers
+		var candidates []*s3.Object
+		err := d.s3.ListObjectsV2PagesWithContext(ctx, d.listObjectsInput(bucket, prefix), func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, obj := range page.Contents {
+				if !keyMatches(aws.StringValue(obj.Key), d.config.IncludePatterns, d.config.ExcludePatterns) {
+					continue
+				}
+				if d.config.MaxRecentFiles > 0 {
+					candidates = append(candidates, obj)
+					continue
+				}
+				if !enqueue(newTarget(obj)) {
+					return false
+				}
+			}
+			return !lastPage
+		})
+		if err != nil {
+			return fmt.Errorf("error listing objects: %w", err)
+		}
+		for _, obj := range newestObjects(candidates, d.config.MaxRecentFiles) {
+			if !enqueue(newTarget(obj)) {
+				break
+			}
+		}
+		return nil
+	}
+}
+
+// target is an object queued for download
+type target struct {
+	*s3.Object
+	versionID *string              // Version to fetch; nil fetches the current version
+	localKey  string               // Path of the local file relative to the download directory
+	gzipped   bool                 // Content-Encoding is gzip and the content is decompressed on download
+	bucket    string               // Bucket of the object in a run over several buckets, empty for the run's bucket
+	head      *s3.HeadObjectOutput // HeadObject response once a feature requested it
+	err       error                // Why the producer could not look the object up; it is recorded as failed, not downloaded
+	replace   bool                 // Replace an existing local file, as Config.Overwrite does for every object
+}
+
+// newTarget queues the current version of obj under its own key
+func newTarget(obj *s3.Object) target {
+	return target{Object: obj, localKey: aws.StringValue(obj.Key)}
+}
+
+// replacing wraps produce so that its objects replace existing local files. It
+// applies Overwrite to a single run without touching the shared configuration.
+func replacing(produce objectProducer) objectProducer {
+	return func(ctx context.Context, enqueue func(target) bool) error {
+		return produce(ctx, func(obj target) bool {
+			obj.replace = true
+			return enqueue(obj)
+		})
+	}
+}
+
+// objectProducer feeds objects to the worker pool through enqueue, which reports
+// false once the run is canceled. ctx is canceled when the run stops early.
+type objectProducer func(ctx context.Context, enqueue func(target) bool) error
+
+// runDownload downloads every object supplied by produce using a pool of workers
+func (d *Downloader) runDownload(ctx context.Context, bucket, downloadPath string, observer ProgressObserver, produce objectProducer) (err error) {
+	tracker := progress.NewTracker(progress.PhaseDownloading)
+	d.tracker.Store(tracker)
+	logger := d.logger
+	if bucket != "" {
+		logger = logger.With("bucket", bucket)
+	}
+	failures := newFailureLog(maxFailures, logger, d.metrics)
+	d.failures.Store(failures)
+	defer d.Resume() // The next run must not start paused
+	logger.Info("download started", "path", downloadPath)
+	if d.config.InsecureSkipVerify {
+		logger.Warn("TLS certificate verification is disabled")
+		d.logf("WARNING: TLS certificate verification is disabled, anyone on the network can read or alter this download")
+	}
+	defer func() {
+		p := tracker.Snapshot()
+		if err != nil {
+			logger.Error("download failed", "error", err, "downloaded", p.FilesDownloaded-p.FilesSkipped, "errors", p.ErrorCount)
+			return
+		}
+		logger.Info("download finished", "downloaded", p.FilesDownloaded-p.FilesSkipped, "skipped", p.FilesSkipped,
+			"errors", p.ErrorCount, "bytes", p.TotalBytes)
+	}()
+	if err := d.CheckCredentials(ctx); err != nil {
+		return err
+	}
+	if observer != nil {
+		d.reports.Store(&progressReporter{observer: observer, tracker: tracker, interval: fileProgressInterval})
+		defer d.reports.Store(nil)
+	}
+
+	var results *reportWriter
+	if d.config.ReportPath != "" {
+		if results, err = newReportWriter(d.config.ReportPath); err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := results.close(err); err == nil {
+				err = closeErr
+			}
+		}()
+	}
+
+	var state *stateLog
+	if d.config.StateFile != "" {
+		if state, err = openStateLog(d.config.StateFile); err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := state.close(); err == nil {
+				err = closeErr
+			}
+		}()
+	}
+
+	var manifest *manifestWriter
+	if d.config.ManifestPath != "" {
+		if manifest, err = newManifestWriter(d.config.ManifestPath); err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := manifest.close(); err == nil {
+				err = closeErr
+			}
+		}()
+	}
+
+	if d.config.CleanPartialsOlderThan > 0 {
+		d.cleanPartials(logger, downloadPath)
+	}
+
+	space := d.newSpaceBudget(downloadPath)
+
+	// runCtx stops the producer and the workers early in fail-fast mode
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	fileChan := make(chan target, d.config.channelBufferSize())
 	doneChan := make(chan struct{})
 	var wg sync.WaitGroup
 
-	downloader := s3manager.NewDownloader(d.sess, func(d *s3manager.Downloader) {
-		d.PartSize = chunkSize
-		d.Concurrency = 10
+	downloader := d.newTransferManager()
+
+	// Every error is kept; in fail-fast mode the first one also stops everything else
+	errs := newRunErrors(d.logf, func() {
+		if d.config.FailFast {
+			cancel()
+		}
 	})
 
-	// Start worker pool based on file size
-	for i := 0; i < maxWorkers; i++ {
+	var index *fileIndex
+	if d.config.GenerateIndex {
+		index = &fileIndex{}
+	}
+
+	// Start the worker pool; in adaptive mode it starts small and is resized as it runs
+	stop := make(chan struct{}, d.config.MaxWorkers)
+	startWorker := func() {
 		wg.Add(1)
-		go d.downloadWorker(ctx, bucket, downloadPath, downloader, fileChan, errChan, &wg, chunkSize, &processedFiles, &skippedFiles, progressChan)
+		go d.downloadWorker(runCtx, bucket, downloadPath, downloader, fileChan, stop, errs, &wg, tracker, observer, index, results, manifest, state, failures)
+	}
+	workers := d.config.MaxWorkers
+	var controller *concurrencyController
+	if d.config.AdaptiveConcurrency {
+		minWorkers := d.config.MinWorkers
+		if minWorkers <= 0 {
+			minWorkers = defaultMinWorkers
+		}
+		controller = newConcurrencyController(minWorkers, d.config.MaxWorkers)
+		workers = controller.workers
+	}
+	for i := 0; i < workers; i++ {
+		startWorker()
+	}
+	if controller != nil {
+		wg.Add(1) // Held while resizing so that the run waits for added workers
+		go func() {
+			defer wg.Done()
+			adaptWorkers(runCtx, controller, tracker, fileChan, doneChan, startWorker, stop)
+		}()
 	}
 
-	// List objects and send to channel
+	// Archived objects are restored together while the workers download the rest
+	if d.config.RestoreArchived {
+		produce = d.restoreArchived(bucket, tracker, observer, produce)
+	}
+
+	// Produce objects and send to channel; the producer holds a wg slot so that
+	// the run waits for its error even when the workers stop early
+	wg.Add(1)
 	go func() {
+		defer wg.Done()
 		defer close(fileChan)
 		defer close(doneChan)
-		err := d.s3.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
-			Bucket: aws.String(bucket),
-			Prefix: aws.String(prefix),
-		}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
-			for _, obj := range page.Contents {
-				select {
-				case fileChan <- obj:
-					atomic.AddInt64(&foundFiles, 1)
-					progressChan <- progress.Progress{FilesFound: foundFiles, FilesDownloaded: processedFiles, FilesSkipped: skippedFiles}
-				case <-ctx.Done():
-					return false
+		err := produce(runCtx, func(obj target) bool {
+			if obj.err != nil {
+				tracker.ErrorCount.Add(1)
+				failures.add(aws.StringValue(obj.Key), obj.err)
+				results.add(obj, statusError, obj.err)
+				errs.add(obj.err)
+				report(observer, tracker)
+				return true
+			}
+			// Folder placeholders are not files; recreate them as directories if asked
+			if isDirectoryMarker(obj.Object) {
+				if d.config.CreateDirectoryMarkers && !d.config.Flatten {
+					dir, err := fileutils.SafeJoin(downloadPath, fileutils.SanitizeFilename(obj.localKey))
+					if err == nil {
+						err = d.sink.Mkdir(dir, d.dirMode())
+					}
+					if err != nil {
+						err = fmt.Errorf("failed to create directory for '%s': %w", aws.StringValue(obj.Key), err)
+						tracker.ErrorCount.Add(1)
+						failures.add(aws.StringValue(obj.Key), err)
+						errs.add(err)
+					}
 				}
+				return true
+			}
+			if !d.config.DownloadArchived && !d.config.RestoreArchived && isArchived(obj.Object) {
+				tracker.ArchivedSkipped.Add(1)
+				results.add(obj, statusSkipped, nil)
+				report(observer, tracker)
+				return true
+			}
+			// Objects completed by an earlier run count as skipped without being queued
+			if state.completed(obj) {
+				tracker.FilesFound.Add(1)
+				tracker.FilesSkipped.Add(1)
+				tracker.FilesDownloaded.Add(1)
+				d.metrics.FileSkipped()
+				index.add(obj.localKey, aws.Int64Value(obj.Size))
+				results.add(obj, statusSkipped, nil)
+				report(observer, tracker)
+				return true
+			}
+			// Stop before filling the disk rather than failing halfway through
+			if err := space.reserve(obj, filepath.Join(downloadPath, obj.localKey)); err != nil {
+				errs.add(err)
+				cancel()
+				return false
+			}
+			select {
+			case fileChan <- obj:
+				tracker.FilesFound.Add(1)
+				tracker.TotalBytesExpected.Add(aws.Int64Value(obj.Size))
+				report(observer, tracker)
+				return true
+			case <-runCtx.Done():
+				return false
 			}
-			return !lastPage
 		})
 		if err != nil {
-			errChan <- fmt.Errorf("error listing objects: %w", err)
+			errs.add(err)
 		}
+		tracker.ListingComplete.Store(true)
+		report(observer, tracker)
 	}()
 
+	finished := make(chan struct{})
 	go func() {
 		wg.Wait()
-		close(errChan)
+		close(finished)
 	}()
 
 	select {
 	case <-doneChan:
-		// Listing completed
+		// Producing completed
 	case <-ctx.Done():
-		// Context canceled
+		// Context canceled; wait for the workers so that nothing reports after returning
+		<-finished
 		return ctx.Err()
 	}
 
-	// Check for errors from downloading
-	for err := range errChan {
-		if err != nil {
+	// Wait for the workers to finish
+	<-finished
+	firstErr := errs.first()
+	if firstErr == nil && ctx.Err() != nil {
+		// Canceled after listing; the workers stopped without downloading everything
+		return ctx.Err()
+	}
+	if firstErr != nil {
+		if d.config.FailFast {
+			return fmt.Errorf("download aborted after the first error (fail-fast): %w", firstErr)
+		}
+		return errs.err()
+	}
+
+	if index != nil {
+		if err := index.write(d.sink, downloadPath); err != nil {
 			return err
 		}
 	}
@@ -118,109 +510,344 @@ func (d *Downloader) ListAndDownloadObjects(ctx context.Context, bucket, prefix,
 }
 
 // downloadWorker processes the download of each file
-func (d *Downloader) downloadWorker(ctx context.Context, bucket, downloadPath string, downloader *s3manager.Downloader,
-	fileChan <-chan *s3.Object, errChan chan<- error, wg *sync.WaitGroup, chunkSize int64,
-	processedFiles, skippedFiles *int64, progressChan chan<- progress.Progress) {
+func (d *Downloader) downloadWorker(ctx context.Context, runBucket, downloadPath string, downloader *s3manager.Downloader,
+	fileChan <-chan target, stop <-chan struct{}, errs *runErrors, wg *sync.WaitGroup,
+	tracker *progress.Tracker, observer ProgressObserver, index *fileIndex, results *reportWriter, manifest *manifestWriter, state *stateLog, failures *failureLog) {
 	defer wg.Done()
 
-	for file := range fileChan {
+	for {
+		file, ok := nextFile(fileChan, stop)
+		if !ok {
+			return
+		}
+		bucket := runBucket
+		if file.bucket != "" {
+			bucket = file.bucket
+		}
+		logger := d.logger.With("bucket", bucket)
+		// A paused worker holds on to its file until the download is resumed
+		if !d.pause.wait(ctx) {
+			return
+		}
 		select {
 		case <-ctx.Done():
 			return
 		default:
-			localFilePath := filepath.Join(downloadPath, aws.StringValue(file.Key))
+			// Tag and Content-Type lookups run here so that they share the workers' concurrency
+			if len(d.config.TagFilters) > 0 {
+				matched, err := d.matchesTags(ctx, bucket, file)
+				if err != nil {
+					tracker.ErrorCount.Add(1)
+					failures.add(aws.StringValue(file.Key), err)
+					results.add(file, statusError, err)
+					errs.add(err)
+					continue
+				}
+				if !matched {
+					tracker.TagFiltered.Add(1)
+					tracker.TotalBytesExpected.Add(-aws.Int64Value(file.Size))
+					results.add(file, statusSkipped, nil)
+					report(observer, tracker)
+					continue
+				}
+			}
+
+			if len(d.config.ContentTypePrefixes) > 0 {
+				matched, err := d.matchesContentType(ctx, bucket, &file)
+				if err != nil {
+					tracker.ErrorCount.Add(1)
+					failures.add(aws.StringValue(file.Key), err)
+					results.add(file, statusError, err)
+					errs.add(err)
+					continue
+				}
+				if !matched {
+					tracker.TypeFiltered.Add(1)
+					tracker.TotalBytesExpected.Add(-aws.Int64Value(file.Size))
+					results.add(file, statusSkipped, nil)
+					report(observer, tracker)
+					continue
+				}
+			}
+
+			// Listed sizes can differ from what is fetched, so ask the object itself
+			if d.config.HeadBeforeDownload {
+				if err := d.confirmSize(ctx, bucket, &file, tracker); err != nil {
+					tracker.ErrorCount.Add(1)
+					failures.add(aws.StringValue(file.Key), err)
+					results.add(file, statusError, err)
+					errs.add(err)
+					continue
+				}
+			}
+
+			// The local name of a decompressed object depends on its encoding
+			if d.config.DecompressGzip {
+				if err := d.detectGzip(ctx, bucket, &file); err != nil {
+					tracker.ErrorCount.Add(1)
+					failures.add(aws.StringValue(file.Key), err)
+					results.add(file, statusError, err)
+					errs.add(err)
+					continue
+				}
+			}
+
+			// Keys may contain characters that the local filesystem does not accept
+			if name := fileutils.SanitizeFilename(file.localKey); name != file.localKey {
+				d.logf("Saving '%s' as '%s'", aws.StringValue(file.Key), name)
+				file.localKey = name
+			}
+
+			// Keys from untrusted buckets must not write outside the download path
+			localFilePath, err := fileutils.SafeJoin(downloadPath, file.localKey)
+			if err != nil {
+				err = fmt.Errorf("refusing to download '%s': %w", aws.StringValue(file.Key), err)
+				tracker.ErrorCount.Add(1)
+				failures.add(aws.StringValue(file.Key), err)
+				results.add(file, statusError, err)
+				errs.add(err)
+				continue
+			}
 			localDir := filepath.Dir(localFilePath)
 
 			// Ensure that the directory exists before attempting to create the file
-			if err := fileutils.EnsureDirectoryExists(localDir); err != nil {
-				errChan <- fmt.Errorf("failed to create directory for '%s': %w", aws.StringValue(file.Key), err)
+			if err := d.sink.Mkdir(localDir, d.dirMode()); err != nil {
+				err = fmt.Errorf("failed to create directory for '%s': %w", aws.StringValue(file.Key), err)
+				tracker.ErrorCount.Add(1)
+				failures.add(aws.StringValue(file.Key), err)
+				results.add(file, statusError, err)
+				errs.add(err)
 				continue
 			}
 
-			// Skip files that already exist
-			if fileutils.FileExists(localFilePath) {
-				atomic.AddInt64(skippedFiles, 1)
-				atomic.AddInt64(processedFiles, 1)
-				progressChan <- progress.Progress{FilesFound: atomic.LoadInt64(processedFiles), FilesDownloaded: atomic.LoadInt64(processedFiles), FilesSkipped: atomic.LoadInt64(skippedFiles)}
+			logger.Debug("object started", "key", aws.StringValue(file.Key), "size", aws.Int64Value(file.Size))
+			skipped, digest, err := d.transferWithRetries(ctx, bucket, downloader, file, localFilePath)
+			if err == nil && !skipped && file.LastModified != nil {
+				// Keep the object's timestamp so incremental tools can rely on mtimes
+				if chErr := d.sink.Chtimes(localFilePath, *file.LastModified); chErr != nil {
+					err = fmt.Errorf("failed to set modification time of '%s': %w", aws.StringValue(file.Key), chErr)
+				}
+			}
+			if err == nil && !skipped {
+				err = d.setFileMode(ctx, bucket, file, localFilePath)
+			}
+			if err == nil && !skipped && d.config.WriteMetadata {
+				// Missing metadata does not make the download itself fail
+				if metaErr := d.writeMetadata(ctx, bucket, file, localFilePath); metaErr != nil {
+					tracker.WarningCount.Add(1)
+				}
+			}
+			if err != nil {
+				// Transfers interrupted by a stop are not failures of their own
+				if ctx.Err() == nil {
+					tracker.ErrorCount.Add(1)
+					failures.add(aws.StringValue(file.Key), err)
+				}
+				results.add(file, statusError, err)
+				errs.add(err)
 				continue
 			}
 
-			// Proceed to download the file
-			if aws.Int64Value(file.Size) > chunkSize {
-				if err := d.downloadLargeFile(ctx, downloader, bucket, file.Key, localFilePath); err != nil {
-					errChan <- err
-				} else {
-					atomic.AddInt64(processedFiles, 1)
-					progressChan <- progress.Progress{FilesFound: atomic.LoadInt64(processedFiles), FilesDownloaded: atomic.LoadInt64(processedFiles), FilesSkipped: atomic.LoadInt64(skippedFiles)}
-				}
+			index.add(file.localKey, aws.Int64Value(file.Size))
+			manifest.add(file.localKey, digest)
+			state.add(file)
+			if skipped {
+				tracker.FilesSkipped.Add(1)
+				tracker.TotalBytesExpected.Add(-aws.Int64Value(file.Size)) // Nothing left to download
+				results.add(file, statusSkipped, nil)
+				d.metrics.FileSkipped()
+				logger.Info("object skipped", "key", aws.StringValue(file.Key), "path", localFilePath)
 			} else {
-				if err := d.downloadSmallFile(ctx, downloader, bucket, file.Key, localFilePath); err != nil {
-					errChan <- err
-				} else {
-					atomic.AddInt64(processedFiles, 1)
-					progressChan <- progress.Progress{FilesFound: atomic.LoadInt64(processedFiles), FilesDownloaded: atomic.LoadInt64(processedFiles), FilesSkipped: atomic.LoadInt64(skippedFiles)}
-				}
+				results.add(file, statusDownloaded, nil)
+				d.metrics.FileDownloaded()
+				logger.Info("object downloaded", "key", aws.StringValue(file.Key), "size", aws.Int64Value(file.Size), "path", localFilePath)
 			}
+			tracker.FilesDownloaded.Add(1)
+			report(observer, tracker)
 		}
 	}
 }
 
-// downloadSmallFile downloads a small file (<chunkSize) from S3
-func (d *Downloader) downloadSmallFile(ctx context.Context, downloader *s3manager.Downloader, bucket string, key *string, localPath string) error {
-	f, err := os.Create(localPath)
-	if err != nil {
-		return fmt.Errorf("failed to create file '%s': %w", aws.StringValue(key), err)
+// newTransferManager creates the multipart downloader shared by a run's workers
+func (d *Downloader) newTransferManager() *s3manager.Downloader {
+	return s3manager.NewDownloaderWithClient(d.s3, func(dl *s3manager.Downloader) {
+		dl.PartSize = d.config.PartSize
+		dl.Concurrency = d.config.Concurrency
+		dl.BufferProvider = partBuffers
+	})
+}
+
+// countBytes wraps w so that bytes written for key add to the current run's TotalBytes
+// and are reported while the object downloads
+func (d *Downloader) countBytes(w WriteAtCloser, key string) WriteAtCloser {
+	t := d.tracker.Load()
+	if t == nil {
+		return w
 	}
-	defer f.Close()
+	return countingWriter{WriteAtCloser: w, count: &t.TotalBytes, file: d.reports.Load().startFile(key), metrics: d.metrics}
+}
 
-	downloadCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-	defer cancel()
+// defaultFileRetryBackoff is the delay before the first retry of a failed file
+const defaultFileRetryBackoff = time.Second
 
-	_, err = downloader.DownloadWithContext(downloadCtx, f, &s3.GetObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    key,
-	})
+// largeFileTimeout is the least time allowed for an object larger than PartSize, or of unknown size
+const largeFileTimeout = 30 * time.Minute
+
+// transferTimeout returns the time allowed to download an object of the given size
+func (d *Downloader) transferTimeout(size int64) time.Duration {
+	if size > d.config.PartSize {
+		return max(largeFileTimeout, d.config.DownloadTimeout)
+	}
+	return d.config.DownloadTimeout
+}
+
+// transfer downloads one object to localPath and verifies it if configured. Files that
+// already exist are skipped, partial files are continued in resume mode, and existing
+// files whose content changed in S3 are downloaded again in SkipUnchanged mode.
+// Overwrite replaces existing files in every mode.
+func (d *Downloader) transfer(ctx context.Context, bucket string, downloader *s3manager.Downloader, file target, localPath string) (bool, string, error) {
+	// A directory in the way is neither a copy of the object nor replaceable by it
+	if fileutils.IsDir(localPath) {
+		return false, "", fmt.Errorf("cannot save '%s': '%s' is a directory", aws.StringValue(file.Key), localPath)
+	}
+	timeout := d.transferTimeout(aws.Int64Value(file.Size))
+
+	download := func(path string) error {
+		input := d.getObjectInput(bucket, file.Key, file.versionID)
+		if file.gzipped {
+			_, err := d.downloadGzipFile(ctx, input, path, timeout)
+			return err
+		}
+		_, err := d.downloadFile(ctx, downloader, input, aws.Int64Value(file.Size), path, timeout)
+		return err
+	}
 
+	// Decompressed files differ from the object, so they can be neither resumed,
+	// compared with it nor verified against its checksums. Resumed downloads
+	// continue the partial file in place.
+	overwrite := d.config.Overwrite || file.replace
+	if d.config.ResumePartial && !file.gzipped && !overwrite {
+		skipped, err := d.resumeFile(ctx, bucket, file, localPath, timeout)
+		if err != nil {
+			return false, "", err
+		}
+		digest, err := d.checkFile(ctx, bucket, file, localPath, !skipped && d.config.VerifyChecksum)
+		return skipped, digest, err
+	}
+
+	present := d.sink.Exists(localPath)
+	if d.config.SkipUnchanged {
+		present = localFileUnchanged(localPath, file.Object)
+	}
+	if overwrite {
+		present = false
+	}
+	if present {
+		digest, err := d.checkFile(ctx, bucket, file, localPath, false)
+		return true, digest, err
+	}
+
+	// Download next to the final file and move it into place only once it is
+	// complete and verified, so that an interrupted run never leaves a
+	// truncated file under a name that later runs would skip
+	partPath := fileutils.PartialPath(localPath)
+	if err := download(partPath); err != nil {
+		return false, "", err
+	}
+	digest, err := d.checkFile(ctx, bucket, file, partPath, d.config.VerifyChecksum && !file.gzipped)
+	if err == nil {
+		if err = d.sink.Rename(partPath, localPath); err != nil {
+			err = fmt.Errorf("failed to move '%s' into place: %w", aws.StringValue(file.Key), err)
+		}
+	}
 	if err != nil {
-		os.Remove(localPath) // Clean up partially downloaded file
-		return fmt.Errorf("failed to download '%s': %w", aws.StringValue(key), err)
+		d.sink.Remove(partPath)
+		return false, "", err
 	}
+	return false, digest, nil
+}
 
-	return nil
+// transferWithRetries runs transfer up to FileRetries more times after a failure,
+// doubling the delay between attempts from FileRetryBackoff. Failed attempts have
+// already removed their partial file, except in resume mode where the next attempt
+// continues it. Cancelling ctx stops waiting immediately.
+func (d *Downloader) transferWithRetries(ctx context.Context, bucket string, downloader *s3manager.Downloader, file target, localPath string) (bool, string, error) {
+	backoff := d.config.FileRetryBackoff
+	if backoff <= 0 {
+		backoff = defaultFileRetryBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		skipped, digest, err := d.transfer(ctx, bucket, downloader, file, localPath)
+		if err == nil || attempt >= d.config.FileRetries || ctx.Err() != nil {
+			return skipped, digest, err
+		}
+
+		select {
+		case <-time.After(backoff << attempt):
+		case <-ctx.Done():
+			return false, "", err
+		}
+	}
+}
+
+// nextFile receives the next queued object, reporting false once the queue is closed
+// or the worker is asked to stop
+func nextFile(fileChan <-chan target, stop <-chan struct{}) (target, bool) {
+	select {
+	case <-stop:
+		return target{}, false
+	case file, ok := <-fileChan:
+		return file, ok
+	}
 }
 
-// downloadLargeFile downloads a large file (>=chunkSize) from S3 using multipart download
-func (d *Downloader) downloadLargeFile(ctx context.Context, downloader *s3manager.Downloader, bucket string, key *string, localPath string) error {
-	f, err := os.Create(localPath)
+// downloadFile downloads a single object from S3 into the sink, using multipart download
+// for large files, and returns the number of bytes written. The listed size, or -1 when
+// unknown, picks the part size and concurrency, see Config.partStrategy.
+func (d *Downloader) downloadFile(ctx context.Context, downloader *s3manager.Downloader, input *s3.GetObjectInput, size int64, localPath string, timeout time.Duration) (int64, error) {
+	key := input.Key
+	f, err := d.sink.Create(localPath)
 	if err != nil {
-		return fmt.Errorf("failed to create file '%s': %w", aws.StringValue(key), err)
+		return 0, fmt.Errorf("failed to create file '%s': %w", aws.StringValue(key), err)
 	}
-	defer f.Close()
 
-	downloadCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	downloadCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	_, err = downloader.DownloadWithContext(downloadCtx, f, &s3.GetObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    key,
+	partSize, concurrency := d.config.partStrategy(size)
+	n, err := downloader.DownloadWithContext(downloadCtx, d.throttle(downloadCtx, d.countBytes(f, aws.StringValue(key))), input, func(dl *s3manager.Downloader) {
+		dl.PartSize = partSize
+		dl.Concurrency = concurrency
 	})
-
+	if closeErr := f.Close(); err == nil {
+		err = closeErr // Data that never reached the disk makes the download incomplete
+	}
 	if err != nil {
-		os.Remove(localPath) // Clean up partially downloaded file
-		return fmt.Errorf("failed to download '%s': %w", aws.StringValue(key), err)
+		d.sink.Remove(localPath) // Clean up partially downloaded file
+		return 0, fmt.Errorf("failed to download '%s': %w", aws.StringValue(key), err)
 	}
 
+	return n, nil
+}
+
+// ValidateBucketExists checks that the bucket exists and is reachable with the current credentials
+func (d *Downloader) ValidateBucketExists(ctx context.Context, bucket string) error {
+	_, err := d.s3.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
+	}, d.requestOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to access bucket '%s': %w", bucket, d.withAnonymousHint(d.withBucketRegion(ctx, bucket, err)))
+	}
 	return nil
 }
 
 // ListPrefixes lists prefixes (subdirectories) within a given S3 bucket and prefix
 func (d *Downloader) ListPrefixes(bucket, prefix string) ([]string, error) {
 	var prefixes []string
-	err := d.s3.ListObjectsV2Pages(&s3.ListObjectsV2Input{
-		Bucket:    aws.String(bucket),
-		Delimiter: aws.String("/"),
-		Prefix:    aws.String(prefix),
-	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+	input := d.listObjectsInput(bucket, prefix)
+	input.Delimiter = aws.String("/")
+	err := d.s3.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
 		for _, p := range page.CommonPrefixes {
 			prefixes = append(prefixes, aws.StringValue(p.Prefix))
 		}
@@ -229,3 +856,17 @@ func (d *Downloader) ListPrefixes(bucket, prefix string) ([]string, error) {
 
 	return prefixes, err
 }
+
+// cleanPartials removes the partial files that crashed runs left in downloadPath.
+// Failing to do so does not stop the run.
+func (d *Downloader) cleanPartials(logger *logging.Logger, downloadPath string) {
+	removed, err := fileutils.CleanPartials(downloadPath, d.config.CleanPartialsOlderThan)
+	if removed > 0 {
+		logger.Info("removed stale partial files", "path", downloadPath, "count", removed)
+		d.logf("Removed %d stale partial files from '%s'", removed, downloadPath)
+	}
+	if err != nil {
+		logger.Warn("failed to remove stale partial files", "path", downloadPath, "error", err)
+		d.logf("Warning: %v", err)
+	}
+}
@@ -0,0 +1,361 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"s3downloader/internal/progress"
+	"s3downloader/pkg/fileutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListAndDownloadObjectsWithoutProgressChannel(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"})
+	d := newTestDownloader(client, newMemorySink())
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+	assert.NoError(t, err)
+	p := d.Progress()
+	assert.Equal(t, progress.PhaseDownloading, p.Phase)
+	assert.Equal(t, int64(3), p.FilesFound)
+	assert.Equal(t, int64(3), p.FilesDownloaded)
+}
+
+func TestDownloadPreservesLastModified(t *testing.T) {
+	client := newFakeS3(map[string]string{"dir/a.txt": "alpha"})
+	downloadPath := t.TempDir()
+	d := newTestDownloader(client, LocalSink{})
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil)
+
+	assert.NoError(t, err)
+	info, err := os.Stat(filepath.Join(downloadPath, "dir", "a.txt"))
+	assert.NoError(t, err)
+	assert.WithinDuration(t, client.modified, info.ModTime(), time.Second)
+}
+
+func TestDownloadRefusesKeysOutsideDownloadPath(t *testing.T) {
+	client := newFakeS3(map[string]string{
+		"../escaped.txt":         "evil",
+		"dir/../../escaped2.txt": "evil",
+		"/rooted.txt":            "fine",
+		"dir/ok.txt":             "fine",
+	})
+	parent := t.TempDir()
+	downloadPath := filepath.Join(parent, "out")
+	d := newTestDownloader(client, LocalSink{})
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil)
+
+	assert.ErrorContains(t, err, "refusing to download")
+	assert.Equal(t, int64(2), d.Progress().ErrorCount)
+	assert.Equal(t, int64(2), d.Progress().FilesDownloaded)
+	assert.NoFileExists(t, filepath.Join(parent, "escaped.txt"))
+	assert.NoFileExists(t, filepath.Join(parent, "escaped2.txt"))
+	assert.FileExists(t, filepath.Join(downloadPath, "rooted.txt"))
+	assert.FileExists(t, filepath.Join(downloadPath, "dir", "ok.txt"))
+}
+
+func TestDownloadSanitizesLocalNames(t *testing.T) {
+	client := newFakeS3(map[string]string{"dir/a\x00b.txt": "alpha"})
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+
+	assert.Equal(t, "alpha", sink.contents(filepath.Join("out", "dir", "a_b.txt")))
+	assert.False(t, sink.Exists(filepath.Join("out", "dir", "a\x00b.txt")))
+}
+
+func TestDownloadWritesFilesAtomically(t *testing.T) {
+	testCases := []struct {
+		name    string
+		prepare func(client *fakeS3, d *Downloader)
+		wantErr bool
+	}{
+		{"Success", func(*fakeS3, *Downloader) {}, false},
+		{"Failed transfer", func(client *fakeS3, _ *Downloader) { client.failures["dir/a.txt"] = -1 }, true},
+		{"Checksum mismatch", func(client *fakeS3, d *Downloader) {
+			client.etags["dir/a.txt"] = "00000000000000000000000000000000"
+			d.config.VerifyChecksum = true
+		}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{"dir/a.txt": "alpha"})
+			downloadPath := t.TempDir()
+			d := newTestDownloader(client, LocalSink{})
+			tc.prepare(client, d)
+
+			err := d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil)
+
+			entries, readErr := os.ReadDir(filepath.Join(downloadPath, "dir"))
+			assert.NoError(t, readErr)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.Empty(t, entries, "neither the file nor its temporary copy may remain")
+				return
+			}
+			assert.NoError(t, err)
+			if assert.Len(t, entries, 1) {
+				assert.Equal(t, "a.txt", entries[0].Name())
+			}
+			data, readErr := os.ReadFile(filepath.Join(downloadPath, "dir", "a.txt"))
+			assert.NoError(t, readErr)
+			assert.Equal(t, "alpha", string(data))
+		})
+	}
+}
+
+func TestCleanPartialsBeforeDownload(t *testing.T) {
+	downloadPath := t.TempDir()
+	stale := filepath.Join(downloadPath, "dir", ".b.txt.part123")
+	fresh := filepath.Join(downloadPath, ".c.txt.part456")
+	other := filepath.Join(downloadPath, "notes.part1")
+	old := time.Now().Add(-2 * time.Hour)
+	for _, path := range []string{stale, fresh, other} {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte("partial"), 0o644))
+	}
+	assert.NoError(t, os.Chtimes(stale, old, old))
+	assert.NoError(t, os.Chtimes(other, old, old))
+
+	for _, olderThan := range []time.Duration{0, time.Hour} {
+		d := newTestDownloader(newFakeS3(map[string]string{"a.txt": "alpha"}), LocalSink{})
+		d.config.CleanPartialsOlderThan = olderThan
+		log := &recordingLog{}
+		d.SetLogSink(log)
+
+		assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil))
+
+		assert.Equal(t, olderThan == 0, fileutils.FileExists(stale), "stale partial with olderThan %s", olderThan)
+		assert.FileExists(t, fresh)
+		assert.FileExists(t, other)
+		assert.FileExists(t, filepath.Join(downloadPath, "a.txt"))
+		if olderThan > 0 {
+			assert.Contains(t, log.lines, fmt.Sprintf("Removed 1 stale partial files from '%s'", downloadPath))
+		}
+	}
+}
+
+func TestTotalBytesExpected(t *testing.T) {
+	objects := map[string]string{"a.txt": "a", "b.txt": "bravo", "dir/c.txt": "charlie charlie", "empty.txt": ""}
+	client := newFakeS3(objects)
+	client.pageSize = 2 // Totals must add up across listing pages
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+	progressChan := make(chan progress.Progress, 100)
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", progressChan)
+	close(progressChan)
+
+	assert.NoError(t, err)
+	var want int64
+	for _, body := range objects {
+		want += int64(len(body))
+	}
+	p := d.Progress()
+	assert.Equal(t, want, p.TotalBytesExpected)
+	assert.Equal(t, want, p.TotalBytes)
+	assert.True(t, p.ListingComplete)
+
+	// The expected total only grows while listing and is final once listing completes
+	var last int64
+	for update := range progressChan {
+		assert.GreaterOrEqual(t, update.TotalBytesExpected, last)
+		last = update.TotalBytesExpected
+		if update.ListingComplete {
+			assert.Equal(t, want, update.TotalBytesExpected)
+		}
+	}
+}
+
+func TestTotalBytesExpectedExcludesSkippedFiles(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo!"})
+	sink := newMemorySink()
+	w, err := sink.Create("out/a.txt")
+	assert.NoError(t, err)
+	w.Close()
+	d := newTestDownloader(client, sink)
+
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+
+	p := d.Progress()
+	assert.Equal(t, int64(1), p.FilesSkipped)
+	assert.Equal(t, int64(6), p.TotalBytesExpected)
+	assert.Equal(t, int64(6), p.TotalBytes)
+}
+
+func TestCompletedCountsSkippedFiles(t *testing.T) {
+	testCases := []struct {
+		name        string
+		existing    []string
+		wantSkipped int64
+	}{
+		{"All downloaded", nil, 0},
+		{"Mixed", []string{"a.txt"}, 1},
+		{"All skipped", []string{"a.txt", "b.txt", "c.txt"}, 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo", "c.txt": "charlie"})
+			sink := newMemorySink()
+			for _, key := range tc.existing {
+				w, err := sink.Create("out/" + key)
+				assert.NoError(t, err)
+				w.Close()
+			}
+			d := newTestDownloader(client, sink)
+
+			assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+
+			p := d.Progress()
+			assert.Equal(t, tc.wantSkipped, p.FilesSkipped)
+			assert.Equal(t, p.FilesFound, p.Completed())
+			assert.Equal(t, p.TotalBytesExpected, p.TotalBytes)
+		})
+	}
+}
+
+func TestDirectoryAtFilePathIsAnError(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo"})
+	downloadPath := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(downloadPath, "a.txt"), 0o755))
+	d := newTestDownloader(client, LocalSink{})
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil)
+
+	assert.ErrorContains(t, err, "cannot save 'a.txt': ")
+	assert.ErrorContains(t, err, "is a directory")
+	assert.DirExists(t, filepath.Join(downloadPath, "a.txt"))
+	assert.FileExists(t, filepath.Join(downloadPath, "b.txt"))
+	p := d.Progress()
+	assert.Equal(t, int64(0), p.FilesSkipped)
+	assert.Equal(t, int64(1), p.ErrorCount)
+	assert.Len(t, client.gets, 1)
+}
+
+// newLargeBucket returns a fake bucket of n one-byte objects, listed page by page with
+// pageDelay and downloaded with getDelay, as for a bucket too large to list up front
+func newLargeBucket(n int, pageDelay, getDelay time.Duration) *fakeS3 {
+	objects := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		objects[fmt.Sprintf("logs/%06d.txt", i)] = "x"
+	}
+	client := newFakeS3(objects)
+	client.pageDelay = pageDelay
+	client.delay = getDelay
+	return client
+}
+
+// utilization is the share of the workers' time spent downloading during a run
+func utilization(client *fakeS3, workers int, elapsed time.Duration) float64 {
+	return float64(client.busy.Load()) / float64(time.Duration(workers)*elapsed)
+}
+
+func TestListingOverlapsDownloading(t *testing.T) {
+	client := newLargeBucket(20000, time.Millisecond, 5*time.Millisecond)
+	d := newTestDownloader(client, newMemorySink())
+	d.config.MaxWorkers = 100
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20000), d.Progress().FilesDownloaded)
+	// The next page is requested while the workers still have plenty of objects queued,
+	// so they keep downloading instead of waiting for the listing
+	assert.Len(t, client.backlogs, 19)
+	for _, backlog := range client.backlogs {
+		assert.Greater(t, backlog, int64(listPageSize/4))
+	}
+}
+
+func TestCancelDrainsLargeListing(t *testing.T) {
+	client := newLargeBucket(20000, 0, 10*time.Millisecond)
+	d := newTestDownloader(client, newMemorySink())
+	d.config.MaxWorkers = 10
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	err := d.ListAndDownloadObjects(ctx, "bucket", "", "out", nil)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second, "the buffered objects are dropped, not downloaded")
+	assert.Less(t, d.Progress().FilesDownloaded, int64(1000))
+}
+
+func TestChannelBufferSize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		workers  int
+		buffer   int
+		expected int
+	}{
+		{"Few workers hold a listing page", 10, 0, 1000},
+		{"Many workers", 200, 0, 4000},
+		{"Configured", 200, 50, 50},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.MaxWorkers = tc.workers
+			cfg.ChannelBufferSize = tc.buffer
+			assert.Equal(t, tc.expected, cfg.channelBufferSize())
+		})
+	}
+}
+
+// BenchmarkListAndDownload reports how busy the workers stay while a large bucket is
+// listed; a utilization well below 1 means they wait for the listing
+func BenchmarkListAndDownload(b *testing.B) {
+	for name, buffer := range map[string]int{"small buffer": 10, "default buffer": 0} {
+		b.Run(name, func(b *testing.B) {
+			var total float64
+			for i := 0; i < b.N; i++ {
+				client := newLargeBucket(10000, 50*time.Millisecond, time.Millisecond)
+				d := newTestDownloader(client, newMemorySink())
+				d.config.MaxWorkers = 20
+				d.config.ChannelBufferSize = buffer
+
+				start := time.Now()
+				if err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil); err != nil {
+					b.Fatal(err)
+				}
+				total += utilization(client, 20, time.Since(start))
+			}
+			b.ReportMetric(total/float64(b.N), "utilization")
+		})
+	}
+}
+
+// discardObserver takes progress reports without keeping them
+type discardObserver struct{}
+
+func (discardObserver) OnProgress(progress.Progress) {}
+func (discardObserver) OnComplete(progress.Progress) {}
+
+// BenchmarkDownloadSmallFiles measures the allocations of a run over many small objects,
+// which are dominated by the per-file costs rather than the data
+func BenchmarkDownloadSmallFiles(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		client := newLargeBucket(10000, 0, 0)
+		d := newTestDownloader(client, newMemorySink())
+		b.StartTimer()
+
+		if err := d.ListAndDownloadObjectsWithObserver(context.Background(), "bucket", "", "out", discardObserver{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
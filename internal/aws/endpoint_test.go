@@ -0,0 +1,127 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+// newS3Server emulates a path-style S3-compatible service holding a single object
+func newS3Server(t *testing.T, key, body string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	handler := newS3Handler(key, body)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// newS3Handler answers path-style S3 requests for a bucket holding a single object
+func newS3Handler(key, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodHead && r.URL.Path == "/bucket":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodGet && r.URL.Path == "/bucket" && r.URL.Query().Get("list-type") == "2":
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Name>bucket</Name><KeyCount>1</KeyCount><IsTruncated>false</IsTruncated><Contents><Key>%s</Key><Size>%d</Size></Contents></ListBucketResult>`, key, len(body))
+		case r.Method == http.MethodGet && r.URL.Path == "/bucket/"+key:
+			fmt.Fprint(w, body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestCustomEndpoint(t *testing.T) {
+	server, requests := newS3Server(t, "a.txt", "hello")
+
+	cfg := DefaultConfig()
+	cfg.Endpoint = server.URL
+	d, err := NewDownloaderWithConfig("us-east-1", "AKID", "SECRET", cfg)
+	assert.NoError(t, err)
+
+	assert.NoError(t, d.ValidateBucketExists(context.Background(), "bucket"))
+
+	downloadPath := t.TempDir()
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil))
+
+	data, err := os.ReadFile(filepath.Join(downloadPath, "a.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	assert.Contains(t, *requests, "HEAD /bucket")
+	assert.Contains(t, *requests, "GET /bucket")
+	assert.Contains(t, *requests, "GET /bucket/a.txt")
+}
+
+func TestInvalidEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"localhost:9000", "ftp://minio.local", "http://", "://bad"} {
+		t.Run(endpoint, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Endpoint = endpoint
+			_, err := NewDownloaderWithConfig("us-east-1", "AKID", "SECRET", cfg)
+			assert.ErrorContains(t, err, "invalid endpoint URL")
+		})
+	}
+}
+
+func TestPathStyle(t *testing.T) {
+	testCases := []struct {
+		name      string
+		pathStyle bool
+		endpoint  string
+		expected  bool
+	}{
+		{"Default virtual-hosted", false, "", false},
+		{"Path style", true, "", true},
+		{"Custom endpoint implies path style", false, "http://localhost:9000", true},
+		{"Path style with custom endpoint", true, "http://localhost:9000", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.PathStyle = tc.pathStyle
+			cfg.Endpoint = tc.endpoint
+			d, err := NewDownloaderWithConfig("us-east-1", "AKID", "SECRET", cfg)
+			assert.NoError(t, err)
+
+			client := d.s3.(*s3.S3)
+			assert.Equal(t, tc.expected, aws.BoolValue(client.Config.S3ForcePathStyle))
+		})
+	}
+}
+
+func TestAccelerate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Accelerate = true
+	d, err := NewDownloaderWithConfig("us-east-1", "AKID", "SECRET", cfg)
+	assert.NoError(t, err)
+
+	client := d.s3.(*s3.S3)
+	assert.True(t, aws.BoolValue(client.Config.S3UseAccelerate))
+	req, _ := client.HeadObjectRequest(&s3.HeadObjectInput{Bucket: aws.String("bucket"), Key: aws.String("a.txt")})
+	assert.NoError(t, req.Build())
+	assert.Equal(t, "bucket.s3-accelerate.amazonaws.com", req.HTTPRequest.URL.Host)
+
+	cfg.PathStyle = true
+	_, err = NewDownloaderWithConfig("us-east-1", "AKID", "SECRET", cfg)
+	assert.ErrorContains(t, err, "transfer acceleration cannot be used with path-style addressing")
+}
@@ -0,0 +1,78 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"s3downloader/internal/logging"
+	"s3downloader/internal/metrics"
+	"s3downloader/internal/progress"
+)
+
+// maxFailures bounds the failures remembered per run; the error count still
+// includes the ones beyond it
+const maxFailures = 10000
+
+// FailedObject is an object that could not be downloaded and why
+type FailedObject struct {
+	Key string
+	Err error
+}
+
+// failureLog collects the failed objects of a run from concurrent workers
+type failureLog struct {
+	mu      sync.Mutex
+	items   []FailedObject
+	limit   int
+	logger  *logging.Logger
+	metrics *metrics.Metrics
+}
+
+// newFailureLog creates an empty log keeping at most limit failures; every
+// failure is also written to logger and counted in metrics
+func newFailureLog(limit int, logger *logging.Logger, m *metrics.Metrics) *failureLog {
+	return &failureLog{limit: limit, logger: logger, metrics: m}
+}
+
+// add records that the object key failed with err; failures beyond the limit
+// are only logged
+func (f *failureLog) add(key string, err error) {
+	f.logger.Error("object failed", "key", key, "error", err)
+	f.metrics.Error()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.items) < f.limit {
+		f.items = append(f.items, FailedObject{Key: key, Err: err})
+	}
+}
+
+// list returns a copy of the recorded failures
+func (f *failureLog) list() []FailedObject {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FailedObject(nil), f.items...)
+}
+
+// Failures returns the objects that failed in the current or last run, in the
+// order they failed
+func (d *Downloader) Failures() []FailedObject {
+	if f := d.failures.Load(); f != nil {
+		return f.list()
+	}
+	return nil
+}
+
+// DownloadKeys downloads the given keys again, typically the failures of an
+// earlier run. With overwrite, existing local files are replaced instead of
+// skipped. Keys that no longer exist count as failures of this run. It must not
+// run at the same time as another download on d.
+func (d *Downloader) DownloadKeys(ctx context.Context, bucket string, keys []string, downloadPath string, overwrite bool, progressChan chan<- progress.Progress) error {
+	missing, err := d.downloadObjects(ctx, bucket, keys, downloadPath, overwrite, progressChan)
+	if f := d.failures.Load(); f != nil {
+		for _, key := range missing {
+			f.add(key, fmt.Errorf("'%s' no longer exists in bucket '%s'", key, bucket))
+		}
+	}
+	return err
+}
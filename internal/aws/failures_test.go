@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureLog(t *testing.T) {
+	f := newFailureLog(50, nil, nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			f.add(fmt.Sprintf("key-%d", i), errors.New("boom"))
+		}(i)
+	}
+	wg.Wait()
+
+	failures := f.list()
+	assert.Len(t, failures, 50, "failures beyond the limit are dropped")
+	failures[0].Key = "changed"
+	assert.NotEqual(t, "changed", f.list()[0].Key, "list returns a copy")
+}
+
+func TestRetryFailedKeys(t *testing.T) {
+	client := newFakeS3(map[string]string{
+		"a.txt":     "alpha",
+		"dir/b.txt": "bravo",
+		"c.txt":     "charlie",
+	})
+	client.failures["dir/b.txt"] = -1
+	client.failures["c.txt"] = -1
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+	assert.Error(t, err)
+	var keys []string
+	for _, f := range d.Failures() {
+		assert.Error(t, f.Err)
+		keys = append(keys, f.Key)
+	}
+	assert.ElementsMatch(t, []string{"dir/b.txt", "c.txt"}, keys)
+	assert.Equal(t, int64(2), d.Progress().ErrorCount)
+
+	// The retry only fetches the failed keys, and its progress and failures replace the first run's
+	client.failures = map[string]int{"c.txt": -1}
+	client.gets = nil
+	delete(client.objects, "gone.txt")
+	err = d.DownloadKeys(context.Background(), "bucket", append(keys, "gone.txt"), "out", false, nil)
+	assert.Error(t, err)
+	assert.Equal(t, "bravo", sink.contents(filepath.Join("out", "dir", "b.txt")))
+	for _, get := range client.gets {
+		assert.NotEqual(t, "a.txt", *get.Key, "downloaded files are not fetched again")
+	}
+
+	p := d.Progress()
+	assert.Equal(t, int64(2), p.FilesFound)
+	assert.Equal(t, int64(1), p.FilesDownloaded)
+	assert.Equal(t, int64(1), p.ErrorCount)
+	keys = nil
+	for _, f := range d.Failures() {
+		keys = append(keys, f.Key)
+	}
+	assert.ElementsMatch(t, []string{"c.txt", "gone.txt"}, keys)
+}
+
+func TestDownloadKeysOverwrite(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "new"})
+	sink := newMemorySink()
+	w, err := sink.Create(filepath.Join("out", "a.txt"))
+	assert.NoError(t, err)
+	w.WriteAt([]byte("old"), 0)
+	w.Close()
+	d := newTestDownloader(client, sink)
+
+	assert.NoError(t, d.DownloadKeys(context.Background(), "bucket", []string{"a.txt"}, "out", false, nil))
+	assert.Equal(t, "old", sink.contents(filepath.Join("out", "a.txt")))
+	assert.Equal(t, int64(1), d.Progress().FilesSkipped)
+
+	assert.NoError(t, d.DownloadKeys(context.Background(), "bucket", []string{"a.txt"}, "out", true, nil))
+	assert.Equal(t, "new", sink.contents(filepath.Join("out", "a.txt")))
+	assert.Equal(t, int64(0), d.Progress().FilesSkipped)
+	assert.False(t, d.config.Overwrite, "the setting only applies to the call")
+
+	// Later runs on the same downloader skip existing files again
+	client.objects["a.txt"] = []byte("newer")
+	_, err = d.DownloadObjects(context.Background(), "bucket", []string{"a.txt"}, "out", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", sink.contents(filepath.Join("out", "a.txt")))
+	assert.Equal(t, int64(1), d.Progress().FilesSkipped)
+}
@@ -0,0 +1,337 @@
+package aws
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// fakeS3 is an in-memory S3 client serving a fixed set of objects
+type fakeS3 struct {
+	s3iface.S3API
+
+	mu        sync.Mutex
+	objects   map[string][]byte
+	pageSize  int
+	etags     map[string]string            // ETag overrides; the content MD5 is used otherwise
+	checksums map[string]string            // Base64 SHA256 checksums returned by HeadObject
+	classes   map[string]string            // Storage classes; STANDARD objects report none
+	encodings map[string]string            // Content-Encoding returned by HeadObject
+	types     map[string]string            // Content-Type returned by HeadObject
+	metadata  map[string]map[string]string // User metadata returned by HeadObject
+	headFails map[string]bool              // Keys whose HeadObject calls fail
+	headErr   error                        // Error returned by every HeadObject call, as without read permission
+	tags      map[string]map[string]string // Object tags returned by GetObjectTagging
+	taggings  []*s3.GetObjectTaggingInput
+	restores  map[string][]string // Restore headers returned by successive HeadObject calls, the last one repeats
+	restored  []*s3.RestoreObjectInput
+	versions  map[string][]byte // Contents of non-current versions by version ID
+	history   []*s3.ObjectVersion
+	failures  map[string]int // Number of upcoming GetObject calls that fail for a key; -1 fails forever
+	listErr   error          // Error returned by ListObjectsV2 and HeadBucket
+	pageErr   error          // Error returned by ListObjectsV2 only, as without list permission
+	location  *string        // Location constraint of the bucket; nil fails GetBucketLocation
+	delay     time.Duration  // Latency of GetObject, cut short when the request is canceled
+	pageDelay time.Duration  // Latency of each listing page
+	busy      atomic.Int64   // Nanoseconds spent in GetObject calls, summed over concurrent calls
+	started   atomic.Int64   // GetObject calls made so far
+	backlogs  []int64        // Objects listed but not yet requested whenever a page after the first is requested
+	modified  time.Time
+	times     map[string]time.Time // LastModified overrides; modified is used otherwise
+	sizes     map[string]int64     // Listed sizes overriding the content length, as for objects rewritten after listing
+	lists     []*s3.ListObjectsV2Input
+	gets      []*s3.GetObjectInput
+	heads     []*s3.HeadObjectInput
+}
+
+func newFakeS3(objects map[string]string) *fakeS3 {
+	f := &fakeS3{
+		objects:   make(map[string][]byte),
+		pageSize:  1000,
+		etags:     make(map[string]string),
+		checksums: make(map[string]string),
+		classes:   make(map[string]string),
+		encodings: make(map[string]string),
+		types:     make(map[string]string),
+		metadata:  make(map[string]map[string]string),
+		headFails: make(map[string]bool),
+		tags:      make(map[string]map[string]string),
+		restores:  make(map[string][]string),
+		versions:  make(map[string][]byte),
+		failures:  make(map[string]int),
+		modified:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		times:     make(map[string]time.Time),
+		sizes:     make(map[string]int64),
+	}
+	for key, body := range objects {
+		f.objects[key] = []byte(body)
+	}
+	return f
+}
+
+func (f *fakeS3) sortedKeys(prefix string) []string {
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// object describes a stored object as a listing would
+func (f *fakeS3) object(key string) *s3.Object {
+	body := f.objects[key]
+	etag, ok := f.etags[key]
+	if !ok {
+		sum := md5.Sum(body)
+		etag = hex.EncodeToString(sum[:])
+	}
+	modified, ok := f.times[key]
+	if !ok {
+		modified = f.modified
+	}
+	size, ok := f.sizes[key]
+	if !ok {
+		size = int64(len(body))
+	}
+	obj := &s3.Object{
+		Key:          aws.String(key),
+		Size:         aws.Int64(size),
+		ETag:         aws.String(`"` + etag + `"`),
+		LastModified: aws.Time(modified),
+	}
+	if class, ok := f.classes[key]; ok {
+		obj.StorageClass = aws.String(class)
+	}
+	return obj
+}
+
+func (f *fakeS3) ListObjectsV2PagesWithContext(ctx aws.Context, input *s3.ListObjectsV2Input,
+	fn func(*s3.ListObjectsV2Output, bool) bool, _ ...request.Option) error {
+	f.mu.Lock()
+	f.lists = append(f.lists, input)
+	err := f.listErr
+	if err == nil {
+		err = f.pageErr
+	}
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	keys := f.sortedKeys(aws.StringValue(input.Prefix))
+	page := &s3.ListObjectsV2Output{}
+	var pages []*s3.ListObjectsV2Output
+	for _, key := range keys {
+		page.Contents = append(page.Contents, f.object(key))
+		if len(page.Contents) == f.pageSize {
+			pages = append(pages, page)
+			page = &s3.ListObjectsV2Output{}
+		}
+	}
+	if len(page.Contents) > 0 || len(pages) == 0 {
+		pages = append(pages, page)
+	}
+	f.mu.Unlock()
+
+	var listed int64
+	for i, p := range pages {
+		if i > 0 {
+			f.mu.Lock()
+			f.backlogs = append(f.backlogs, listed-f.started.Load())
+			f.mu.Unlock()
+		}
+		if f.pageDelay > 0 {
+			select {
+			case <-time.After(f.pageDelay):
+			case <-ctx.Done():
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !fn(p, i == len(pages)-1) {
+			break
+		}
+		listed += int64(len(p.Contents))
+	}
+	return nil
+}
+
+func (f *fakeS3) GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	f.started.Add(1)
+	called := time.Now()
+	defer func() { f.busy.Add(int64(time.Since(called))) }()
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.gets = append(f.gets, input)
+
+	if n := f.failures[aws.StringValue(input.Key)]; n != 0 {
+		if n > 0 {
+			f.failures[aws.StringValue(input.Key)] = n - 1
+		}
+		return nil, awserr.New("InternalError", "We encountered an internal error. Please try again.", nil)
+	}
+
+	body, ok := f.objects[aws.StringValue(input.Key)]
+	if input.VersionId != nil {
+		body, ok = f.versions[aws.StringValue(input.VersionId)]
+	}
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil)
+	}
+
+	size := int64(len(body))
+	start, end := int64(0), size-1
+	if input.Range != nil {
+		fmt.Sscanf(aws.StringValue(input.Range), "bytes=%d-%d", &start, &end)
+		if end >= size {
+			end = size - 1
+		}
+	}
+	part := body[start : end+1]
+	return &s3.GetObjectOutput{
+		Body:          io.NopCloser(bytes.NewReader(part)),
+		ContentLength: aws.Int64(int64(len(part))),
+		ContentRange:  aws.String(fmt.Sprintf("bytes %d-%d/%d", start, end, size)),
+	}, nil
+}
+
+func (f *fakeS3) HeadObjectWithContext(ctx aws.Context, input *s3.HeadObjectInput, _ ...request.Option) (*s3.HeadObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.heads = append(f.heads, input)
+	if f.headErr != nil {
+		return nil, f.headErr
+	}
+
+	body, ok := f.objects[aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.New("NotFound", "Not Found", nil)
+	}
+	if f.headFails[aws.StringValue(input.Key)] {
+		return nil, awserr.New("InternalError", "We encountered an internal error. Please try again.", nil)
+	}
+	obj := f.object(aws.StringValue(input.Key))
+	out := &s3.HeadObjectOutput{ContentLength: aws.Int64(int64(len(body))), ETag: obj.ETag, LastModified: obj.LastModified, StorageClass: obj.StorageClass}
+	if encoding, ok := f.encodings[aws.StringValue(input.Key)]; ok {
+		out.ContentEncoding = aws.String(encoding)
+	}
+	if contentType, ok := f.types[aws.StringValue(input.Key)]; ok {
+		out.ContentType = aws.String(contentType)
+	}
+	if meta, ok := f.metadata[aws.StringValue(input.Key)]; ok {
+		out.Metadata = aws.StringMap(meta)
+	}
+	if aws.StringValue(input.ChecksumMode) == s3.ChecksumModeEnabled {
+		if checksum, ok := f.checksums[aws.StringValue(input.Key)]; ok {
+			out.ChecksumSHA256 = aws.String(checksum)
+		}
+	}
+	if states := f.restores[aws.StringValue(input.Key)]; len(states) > 0 {
+		if states[0] != "" {
+			out.Restore = aws.String(states[0])
+		}
+		if len(states) > 1 {
+			f.restores[aws.StringValue(input.Key)] = states[1:]
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeS3) GetObjectTaggingWithContext(ctx aws.Context, input *s3.GetObjectTaggingInput, _ ...request.Option) (*s3.GetObjectTaggingOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.taggings = append(f.taggings, input)
+
+	if _, ok := f.objects[aws.StringValue(input.Key)]; !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil)
+	}
+	out := &s3.GetObjectTaggingOutput{TagSet: []*s3.Tag{}}
+	for key, value := range f.tags[aws.StringValue(input.Key)] {
+		out.TagSet = append(out.TagSet, &s3.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	return out, nil
+}
+
+func (f *fakeS3) HeadBucketWithContext(ctx aws.Context, input *s3.HeadBucketInput, _ ...request.Option) (*s3.HeadBucketOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	return &s3.HeadBucketOutput{}, nil
+}
+
+func (f *fakeS3) GetBucketLocationWithContext(ctx aws.Context, input *s3.GetBucketLocationInput, _ ...request.Option) (*s3.GetBucketLocationOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.location == nil {
+		return nil, awserr.New("AccessDenied", "Access Denied", nil)
+	}
+	return &s3.GetBucketLocationOutput{LocationConstraint: f.location}, nil
+}
+
+// addVersion stores body as a version of key; the latest version also becomes the object's content
+func (f *fakeS3) addVersion(key, versionID, body string, latest bool) {
+	f.versions[versionID] = []byte(body)
+	if latest {
+		f.objects[key] = []byte(body)
+	}
+	f.history = append(f.history, &s3.ObjectVersion{
+		Key:          aws.String(key),
+		VersionId:    aws.String(versionID),
+		IsLatest:     aws.Bool(latest),
+		Size:         aws.Int64(int64(len(body))),
+		LastModified: aws.Time(f.modified),
+	})
+}
+
+func (f *fakeS3) ListObjectVersionsPagesWithContext(ctx aws.Context, input *s3.ListObjectVersionsInput,
+	fn func(*s3.ListObjectVersionsOutput, bool) bool, _ ...request.Option) error {
+	f.mu.Lock()
+	page := &s3.ListObjectVersionsOutput{}
+	for _, v := range f.history {
+		if strings.HasPrefix(aws.StringValue(v.Key), aws.StringValue(input.Prefix)) {
+			page.Versions = append(page.Versions, v)
+		}
+	}
+	f.mu.Unlock()
+
+	fn(page, true)
+	return nil
+}
+
+func (f *fakeS3) RestoreObjectWithContext(ctx aws.Context, input *s3.RestoreObjectInput, _ ...request.Option) (*s3.RestoreObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.restored = append(f.restored, input)
+	return &s3.RestoreObjectOutput{}, nil
+}
+
+// newTestDownloader builds a Downloader backed by the fake client and the given sink
+func newTestDownloader(client s3iface.S3API, sink Sink) *Downloader {
+	return &Downloader{s3: client, sink: sink, config: DefaultConfig()}
+}
@@ -0,0 +1,54 @@
+package aws
+
+import (
+	"sync/atomic"
+	"time"
+
+	"s3downloader/internal/progress"
+)
+
+// fileProgressInterval is the shortest time between two reports sent while objects download
+const fileProgressInterval = 250 * time.Millisecond
+
+// progressReporter sends progress of a run while its objects are still downloading,
+// at most once per interval across all workers
+type progressReporter struct {
+	observer ProgressObserver
+	tracker  *progress.Tracker
+	interval time.Duration
+	last     atomic.Int64 // Time of the last report in Unix nanoseconds
+}
+
+// startFile returns the progress of a new download of key; it is nil on a nil reporter
+func (r *progressReporter) startFile(key string) *fileProgress {
+	if r == nil {
+		return nil
+	}
+	return &fileProgress{reporter: r, counter: progress.FileCounter{Key: key}}
+}
+
+// send reports the tracker's counters unless a report was sent less than an interval ago
+func (r *progressReporter) send() {
+	now := time.Now().UnixNano()
+	last := r.last.Load()
+	if now-last < int64(r.interval) || !r.last.CompareAndSwap(last, now) {
+		return
+	}
+	report(r.observer, r.tracker)
+}
+
+// fileProgress counts the bytes written for one object. It is the only allocation
+// progress reporting makes per object; counting a write allocates nothing.
+type fileProgress struct {
+	reporter *progressReporter
+	counter  progress.FileCounter
+}
+
+// add counts n more bytes written; it is a no-op on a nil fileProgress
+func (f *fileProgress) add(n int64) {
+	if f == nil {
+		return
+	}
+	f.reporter.tracker.AddFileBytes(&f.counter, n)
+	f.reporter.send()
+}
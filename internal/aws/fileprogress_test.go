@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"s3downloader/internal/progress"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountingWriterReportsIntraFileProgress(t *testing.T) {
+	tracker := progress.NewTracker(progress.PhaseDownloading)
+	observer := &recordingObserver{}
+	reporter := &progressReporter{observer: observer, tracker: tracker}
+	buf := &aws.WriteAtBuffer{}
+	w := countingWriter{WriteAtCloser: memoryFile{buf}, count: &tracker.TotalBytes, file: reporter.startFile("big.bin")}
+
+	chunk := make([]byte, 100)
+	for i := 0; i < 3; i++ {
+		n, err := w.WriteAt(chunk, int64(i*len(chunk)))
+		assert.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+
+	if assert.Len(t, observer.updates, 3) {
+		for i, p := range observer.updates {
+			want := int64((i + 1) * len(chunk))
+			assert.Equal(t, progress.FileProgress{Key: "big.bin", Bytes: want}, p.CurrentFile)
+			assert.Equal(t, want, p.TotalBytes)
+		}
+	}
+	assert.Len(t, buf.Bytes(), 300)
+}
+
+func TestCountingWriterLimitsReportRate(t *testing.T) {
+	tracker := progress.NewTracker(progress.PhaseDownloading)
+	observer := &recordingObserver{}
+	reporter := &progressReporter{observer: observer, tracker: tracker, interval: time.Hour}
+	w := countingWriter{WriteAtCloser: memoryFile{&aws.WriteAtBuffer{}}, count: &tracker.TotalBytes, file: reporter.startFile("big.bin")}
+
+	for i := 0; i < 5; i++ {
+		_, err := w.WriteAt([]byte("0123456789"), int64(i*10))
+		assert.NoError(t, err)
+	}
+
+	// Only the first write is reported, but the counters stay current
+	assert.Len(t, observer.updates, 1)
+	p := tracker.Snapshot()
+	assert.Equal(t, int64(50), p.TotalBytes)
+	assert.Equal(t, progress.FileProgress{Key: "big.bin", Bytes: 50}, p.CurrentFile)
+}
+
+func TestCountingWriterDoesNotAllocate(t *testing.T) {
+	tracker := progress.NewTracker(progress.PhaseDownloading)
+	reporter := &progressReporter{observer: &recordingObserver{}, tracker: tracker, interval: time.Hour}
+	w := countingWriter{WriteAtCloser: memoryFile{aws.NewWriteAtBuffer(make([]byte, 10))}, count: &tracker.TotalBytes, file: reporter.startFile("big.bin")}
+	chunk := []byte("0123456789")
+	_, err := w.WriteAt(chunk, 0)
+	assert.NoError(t, err)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		w.WriteAt(chunk, 0)
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, "big.bin", tracker.Snapshot().CurrentFile.Key)
+}
@@ -0,0 +1,36 @@
+package aws
+
+import (
+	"fmt"
+	"path"
+)
+
+// keyMatches reports whether key should be downloaded: it must match at least one
+// include pattern, or includes must be empty, and must match no exclude pattern.
+// Patterns use path.Match syntax, so '*' does not cross '/' boundaries.
+func keyMatches(key string, includes, excludes []string) bool {
+	for _, pattern := range excludes {
+		if ok, _ := path.Match(pattern, key); ok {
+			return false
+		}
+	}
+	if len(includes) == 0 {
+		return true
+	}
+	for _, pattern := range includes {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePatterns checks that every include and exclude pattern is well-formed
+func validatePatterns(cfg Config) error {
+	for _, pattern := range append(append([]string{}, cfg.IncludePatterns...), cfg.ExcludePatterns...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+		}
+	}
+	return nil
+}
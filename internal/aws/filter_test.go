@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyMatches(t *testing.T) {
+	testCases := []struct {
+		name     string
+		key      string
+		includes []string
+		excludes []string
+		expected bool
+	}{
+		{"No patterns", "logs/a.log", nil, nil, true},
+		{"Include match", "data.json", []string{"*.json"}, nil, true},
+		{"Include miss", "data.csv", []string{"*.json"}, nil, false},
+		{"Include does not cross slashes", "dir/data.json", []string{"*.json"}, nil, false},
+		{"One of several includes", "dir/data.json", []string{"*.json", "dir/*.json"}, nil, true},
+		{"Exclude match", "logs/a.log", nil, []string{"logs/*"}, false},
+		{"Exclude miss", "data/a.log", nil, []string{"logs/*"}, true},
+		{"Include and exclude match", "logs/a.json", []string{"logs/*.json"}, []string{"logs/*"}, false},
+		{"Include match and exclude miss", "data/a.json", []string{"*/*.json"}, []string{"logs/*"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, keyMatches(tc.key, tc.includes, tc.excludes))
+		})
+	}
+}
+
+func TestValidatePatterns(t *testing.T) {
+	assert.NoError(t, validatePatterns(Config{IncludePatterns: []string{"*.json"}, ExcludePatterns: []string{"logs/*"}}))
+	assert.Error(t, validatePatterns(Config{IncludePatterns: []string{"[a-"}}))
+	assert.Error(t, validatePatterns(Config{ExcludePatterns: []string{"[a-"}}))
+}
+
+func TestListAndDownloadObjectsFiltersKeys(t *testing.T) {
+	client := newFakeS3(map[string]string{
+		"a.json":      "a",
+		"b.txt":       "b",
+		"logs/c.json": "c",
+		"data/d.json": "d",
+	})
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+	d.config.IncludePatterns = []string{"*.json", "*/*.json"}
+	d.config.ExcludePatterns = []string{"logs/*"}
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+	assert.NoError(t, err)
+	assert.True(t, sink.Exists(filepath.Join("out", "a.json")))
+	assert.True(t, sink.Exists(filepath.Join("out", "data", "d.json")))
+	assert.False(t, sink.Exists(filepath.Join("out", "b.txt")))
+	assert.False(t, sink.Exists(filepath.Join("out", "logs", "c.json")))
+	assert.Equal(t, int64(2), d.Progress().FilesFound)
+}
@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// flatNames assigns local names when the directory structure is flattened. The
+// first object with a base name keeps it; later ones get a numeric suffix, so
+// "a/file.txt" and "b/file.txt" become "file.txt" and "file(1).txt".
+type flatNames struct {
+	mu   sync.Mutex
+	used map[string]bool
+}
+
+func newFlatNames() *flatNames {
+	return &flatNames{used: make(map[string]bool)}
+}
+
+// flatten wraps produce so that every object is saved directly in the download path
+func flatten(produce objectProducer) objectProducer {
+	return func(ctx context.Context, enqueue func(target) bool) error {
+		names := newFlatNames()
+		return produce(ctx, func(obj target) bool {
+			obj.localKey = names.assign(obj.localKey)
+			return enqueue(obj)
+		})
+	}
+}
+
+// assign returns a base name for key that no earlier object was given
+func (f *flatNames) assign(key string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := path.Base(key)
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	for i := 1; f.used[name]; i++ {
+		name = fmt.Sprintf("%s(%d)%s", stem, i, ext)
+	}
+	f.used[name] = true
+	return name
+}
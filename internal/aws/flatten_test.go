@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatNames(t *testing.T) {
+	testCases := []struct {
+		name     string
+		keys     []string
+		expected []string
+	}{
+		{"Unique", []string{"a/one.txt", "b/two.txt", "three"}, []string{"one.txt", "two.txt", "three"}},
+		{"Colliding", []string{"a/file.txt", "b/file.txt", "c/file.txt"}, []string{"file.txt", "file(1).txt", "file(2).txt"}},
+		{"Suffix already taken", []string{"a/file.txt", "b/file(1).txt", "c/file.txt"}, []string{"file.txt", "file(1).txt", "file(2).txt"}},
+		{"No extension", []string{"a/README", "b/README"}, []string{"README", "README(1)"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			names := newFlatNames()
+			var result []string
+			for _, key := range tc.keys {
+				result = append(result, names.assign(key))
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestListAndDownloadObjectsFlatten(t *testing.T) {
+	client := newFakeS3(map[string]string{
+		"2024/01/data.csv":  "january",
+		"2024/02/data.csv":  "february",
+		"2024/02/notes.txt": "notes",
+	})
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+	d.config.Flatten = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "january", sink.contents(filepath.Join("out", "data.csv")))
+	assert.Equal(t, "february", sink.contents(filepath.Join("out", "data(1).csv")))
+	assert.Equal(t, "notes", sink.contents(filepath.Join("out", "notes.txt")))
+	assert.False(t, sink.dirs[filepath.Join("out", "2024")])
+}
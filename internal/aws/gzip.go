@@ -0,0 +1,87 @@
+package aws
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// gzipSuffix is removed from the local name of decompressed objects when StripGzipSuffix is set
+const gzipSuffix = ".gz"
+
+// detectGzip marks obj as gzipped when its Content-Encoding is gzip and, if
+// StripGzipSuffix is set, drops the .gz suffix from its local name
+func (d *Downloader) detectGzip(ctx context.Context, bucket string, obj *target) error {
+	out, err := d.headObject(ctx, bucket, obj)
+	if err != nil {
+		return fmt.Errorf("failed to get content encoding of '%s': %w", aws.StringValue(obj.Key), err)
+	}
+	obj.gzipped = isGzipEncoding(aws.StringValue(out.ContentEncoding))
+	if obj.gzipped && d.config.StripGzipSuffix {
+		obj.localKey = strippedGzipName(obj.localKey)
+	}
+	return nil
+}
+
+// isGzipEncoding reports whether a Content-Encoding header value ends with gzip.
+// Encodings are listed in the order they were applied, so only the last one is
+// undone by decompressing.
+func isGzipEncoding(encoding string) bool {
+	codings := strings.Split(encoding, ",")
+	last := strings.ToLower(strings.TrimSpace(codings[len(codings)-1]))
+	return last == "gzip" || last == "x-gzip"
+}
+
+// strippedGzipName removes the .gz suffix from a local name unless nothing would be left
+func strippedGzipName(name string) string {
+	if trimmed := strings.TrimSuffix(name, gzipSuffix); trimmed != name && !strings.HasSuffix(trimmed, "/") && trimmed != "" {
+		return trimmed
+	}
+	return name
+}
+
+// downloadGzipFile downloads a gzip-encoded object and writes its decompressed content
+// into the sink. The stream is read sequentially, so multipart download is not used.
+func (d *Downloader) downloadGzipFile(ctx context.Context, input *s3.GetObjectInput, localPath string, timeout time.Duration) (int64, error) {
+	key := aws.StringValue(input.Key)
+	f, err := d.sink.Create(localPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file '%s': %w", key, err)
+	}
+
+	downloadCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	n, err := d.decompressObject(downloadCtx, input, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		d.sink.Remove(localPath) // Clean up partially downloaded file
+		return 0, fmt.Errorf("failed to download '%s': %w", key, err)
+	}
+	return n, nil
+}
+
+// decompressObject streams the object through a gzip reader into w
+func (d *Downloader) decompressObject(ctx context.Context, input *s3.GetObjectInput, w WriteAtCloser) (int64, error) {
+	out, err := d.s3.GetObjectWithContext(ctx, input)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Body.Close()
+
+	gz, err := gzip.NewReader(out.Body)
+	if err != nil {
+		return 0, fmt.Errorf("invalid gzip content: %w", err)
+	}
+	defer gz.Close()
+
+	return streamCopy(io.NewOffsetWriter(d.throttle(ctx, d.countBytes(w, aws.StringValue(input.Key))), 0), gz)
+}
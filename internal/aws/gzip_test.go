@@ -0,0 +1,148 @@
+package aws
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipped(t *testing.T, body string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(body))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.String()
+}
+
+func TestDecompressGzip(t *testing.T) {
+	const csv = "id,name\n1,alpha\n"
+	archive := gzipped(t, "kept compressed")
+
+	testCases := []struct {
+		name      string
+		stripGz   bool
+		wantFiles map[string]string
+	}{
+		{
+			name:    "Keep local names",
+			stripGz: false,
+			wantFiles: map[string]string{
+				"data.csv.gz":    csv,
+				"archive.tar.gz": archive,
+				"plain.txt":      "plain",
+			},
+		},
+		{
+			name:    "Strip .gz from decompressed objects",
+			stripGz: true,
+			wantFiles: map[string]string{
+				"data.csv":       csv,
+				"archive.tar.gz": archive,
+				"plain.txt":      "plain",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{
+				"data.csv.gz":    gzipped(t, csv),
+				"archive.tar.gz": archive, // A gzip file without Content-Encoding
+				"plain.txt":      "plain",
+			})
+			client.encodings["data.csv.gz"] = "gzip"
+			downloadPath := t.TempDir()
+			d := newTestDownloader(client, LocalSink{})
+			d.config.DecompressGzip = true
+			d.config.StripGzipSuffix = tc.stripGz
+			d.config.VerifyChecksum = true
+
+			err := d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil)
+
+			assert.NoError(t, err)
+			entries, err := os.ReadDir(downloadPath)
+			assert.NoError(t, err)
+			assert.Len(t, entries, len(tc.wantFiles))
+			for name, want := range tc.wantFiles {
+				data, err := os.ReadFile(filepath.Join(downloadPath, name))
+				assert.NoError(t, err)
+				assert.Equal(t, want, string(data), name)
+			}
+		})
+	}
+}
+
+// newGzipS3Handler serves key like newS3Handler but stored with Content-Encoding gzip,
+// answering HEAD and ranged requests as S3 does
+func newGzipS3Handler(key, stored string) http.HandlerFunc {
+	list := newS3Handler(key, stored)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bucket/"+key {
+			list(w, r)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		http.ServeContent(w, r, key, time.Time{}, strings.NewReader(stored))
+	}
+}
+
+func TestDecompressGzipOverHTTP(t *testing.T) {
+	const csv = "id,name\n1,alpha\n"
+	server := httptest.NewServer(newGzipS3Handler("data.csv.gz", gzipped(t, csv)))
+	t.Cleanup(server.Close)
+
+	cfg := DefaultConfig()
+	cfg.Endpoint = server.URL
+	cfg.DecompressGzip = true
+	d, err := NewDownloaderWithConfig("us-east-1", "AKID", "SECRET", cfg)
+	assert.NoError(t, err)
+
+	downloadPath := t.TempDir()
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil))
+
+	data, err := os.ReadFile(filepath.Join(downloadPath, "data.csv.gz"))
+	assert.NoError(t, err)
+	assert.Equal(t, csv, string(data), "the client does not decompress the body before the downloader does")
+}
+
+func TestDecompressGzipInvalidContent(t *testing.T) {
+	client := newFakeS3(map[string]string{"broken.gz": "not gzip"})
+	client.encodings["broken.gz"] = "gzip"
+	downloadPath := t.TempDir()
+	d := newTestDownloader(client, LocalSink{})
+	d.config.DecompressGzip = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil)
+
+	assert.ErrorContains(t, err, "invalid gzip content")
+	assert.NoFileExists(t, filepath.Join(downloadPath, "broken.gz"))
+}
+
+func TestIsGzipEncoding(t *testing.T) {
+	testCases := map[string]bool{
+		"gzip":        true,
+		"GZIP":        true,
+		"x-gzip":      true,
+		"br, gzip":    true,
+		"gzip, br":    false,
+		"":            false,
+		"identity":    false,
+		"compress":    false,
+		" gzip ":      true,
+		"aws-chunked": false,
+	}
+	for encoding, want := range testCases {
+		assert.Equal(t, want, isGzipEncoding(encoding), encoding)
+	}
+}
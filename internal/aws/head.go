@@ -0,0 +1,46 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"s3downloader/internal/progress"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// headObject returns the HeadObject response for obj, requesting it at most
+// once per object so that the features reading it share a single call
+func (d *Downloader) headObject(ctx context.Context, bucket string, obj *target) (*s3.HeadObjectOutput, error) {
+	if obj.head != nil {
+		return obj.head, nil
+	}
+	out, err := d.s3.HeadObjectWithContext(ctx, d.headObjectInput(bucket, obj.Key, obj.versionID))
+	if err != nil {
+		return nil, err
+	}
+	obj.head = out
+	return out, nil
+}
+
+// confirmSize replaces the listed size of obj with the ContentLength reported by
+// HeadObject, correcting the expected total of the run by the difference
+func (d *Downloader) confirmSize(ctx context.Context, bucket string, obj *target, tracker *progress.Tracker) error {
+	out, err := d.headObject(ctx, bucket, obj)
+	if err != nil {
+		return fmt.Errorf("failed to get size of '%s': %w", aws.StringValue(obj.Key), err)
+	}
+	if out.ContentLength == nil {
+		return nil
+	}
+	listed, actual := aws.Int64Value(obj.Size), aws.Int64Value(out.ContentLength)
+	if actual != listed {
+		tracker.TotalBytesExpected.Add(actual - listed)
+		// The listing's object may be shared, so change a copy
+		object := *obj.Object
+		object.Size = aws.Int64(actual)
+		obj.Object = &object
+	}
+	return nil
+}
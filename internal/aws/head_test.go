@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadBeforeDownloadConfirmsSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		head     bool
+		expected int64
+		heads    int
+	}{
+		{name: "listed sizes", head: false, expected: 4 + 5, heads: 0},
+		{name: "head before download", head: true, expected: 12 + 5, heads: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{"grown.txt": "twelve bytes", "same.txt": "bravo"})
+			client.sizes["grown.txt"] = 4
+			downloadPath := t.TempDir()
+			d := newTestDownloader(client, LocalSink{})
+			d.config.HeadBeforeDownload = tt.head
+
+			err := d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil)
+
+			assert.NoError(t, err)
+			assert.Len(t, client.heads, tt.heads)
+			p := d.Progress()
+			assert.Equal(t, tt.expected, p.TotalBytesExpected)
+			assert.Equal(t, int64(2), p.FilesDownloaded)
+			data, err := os.ReadFile(filepath.Join(downloadPath, "grown.txt"))
+			assert.NoError(t, err)
+			assert.Equal(t, "twelve bytes", string(data))
+		})
+	}
+}
+
+func TestHeadBeforeDownloadSharesHeadObject(t *testing.T) {
+	client := newFakeS3(map[string]string{"report.csv": "a,b\n"})
+	client.types["report.csv"] = "text/csv"
+	d := newTestDownloader(client, LocalSink{})
+	d.config.HeadBeforeDownload = true
+	d.config.WriteMetadata = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", t.TempDir(), nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, client.heads, 1)
+	assert.Equal(t, "report.csv", aws.StringValue(client.heads[0].Key))
+}
+
+func TestHeadBeforeDownloadFailure(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo"})
+	client.headFails["b.txt"] = true
+	downloadPath := t.TempDir()
+	d := newTestDownloader(client, LocalSink{})
+	d.config.HeadBeforeDownload = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil)
+
+	assert.ErrorContains(t, err, "failed to get size of 'b.txt'")
+	assert.FileExists(t, filepath.Join(downloadPath, "a.txt"))
+	assert.NoFileExists(t, filepath.Join(downloadPath, "b.txt"))
+	p := d.Progress()
+	assert.Equal(t, int64(1), p.FilesDownloaded)
+	assert.Equal(t, int64(1), p.ErrorCount)
+}
@@ -0,0 +1,95 @@
+package aws
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// indexFileName is the name of the generated listing inside the download path
+const indexFileName = "index.html"
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>S3 Downloader index</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+td { padding: 0.2em 1em 0.2em 0; }
+td.size { text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{len .}} files</h1>
+<table>
+<tr><th>Path</th><th>Size (bytes)</th></tr>
+{{- range .}}
+<tr><td><a href="{{.Href}}">{{.Path}}</a></td><td class="size">{{.Size}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// indexEntry describes a single file in the generated index
+type indexEntry struct {
+	Path string
+	Href string
+	Size int64
+}
+
+// fileIndex collects the files of a run so an index can be written at the end
+type fileIndex struct {
+	mu      sync.Mutex
+	entries []indexEntry
+}
+
+// add records a file by its key relative to the download path; it is a no-op on a nil index
+func (i *fileIndex) add(key string, size int64) {
+	if i == nil {
+		return
+	}
+
+	segments := strings.Split(key, "/")
+	for n, s := range segments {
+		segments[n] = url.PathEscape(s)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	// Keys come from the bucket; "./" keeps a key such as "javascript:..." from reading as a
+	// URL scheme, and the template still filters whatever it considers unsafe
+	i.entries = append(i.entries, indexEntry{Path: key, Href: "./" + strings.Join(segments, "/"), Size: size})
+}
+
+// write renders the collected files as index.html in the download path
+func (i *fileIndex) write(sink Sink, downloadPath string) error {
+	i.mu.Lock()
+	entries := append([]indexEntry(nil), i.entries...)
+	i.mu.Unlock()
+
+	sort.Slice(entries, func(a, b int) bool { return entries[a].Path < entries[b].Path })
+
+	var buf bytes.Buffer
+	if err := indexTemplate.Execute(&buf, entries); err != nil {
+		return fmt.Errorf("failed to render index: %w", err)
+	}
+
+	indexPath := filepath.Join(downloadPath, indexFileName)
+	f, err := sink.Create(indexPath)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteAt(buf.Bytes(), 0); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
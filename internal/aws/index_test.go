@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"s3downloader/internal/progress"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateIndex(t *testing.T) {
+	client := newFakeS3(map[string]string{
+		"docs/readme.txt":       "hello",
+		"docs/my report #1.csv": "a,b,c",
+	})
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+	d.config.GenerateIndex = true
+
+	progressChan := make(chan progress.Progress, 1)
+	last := drain(progressChan)
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "docs/", "out", progressChan)
+	close(progressChan)
+	<-last
+
+	assert.NoError(t, err)
+	index := sink.contents(filepath.Join("out", indexFileName))
+	assert.Contains(t, index, "<h1>2 files</h1>")
+	assert.Contains(t, index, `<a href="./docs/readme.txt">docs/readme.txt</a></td><td class="size">5</td>`)
+	assert.Contains(t, index, `<a href="./docs/my%20report%20%231.csv">docs/my report #1.csv</a>`)
+}
+
+func TestGenerateIndexEscapesSchemes(t *testing.T) {
+	client := newFakeS3(map[string]string{"javascript:alert(document.domain)//x.txt": "x"})
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+	d.config.GenerateIndex = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+	assert.NoError(t, err)
+	index := sink.contents(filepath.Join("out", indexFileName))
+	assert.NotContains(t, index, `href="javascript:`)
+	assert.Contains(t, index, `<a href="./javascript:alert%28document.domain%29//x.txt">`)
+}
+
+func TestGenerateIndexDisabled(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "a"})
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+
+	progressChan := make(chan progress.Progress, 1)
+	last := drain(progressChan)
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", progressChan)
+	close(progressChan)
+	<-last
+
+	assert.NoError(t, err)
+	assert.False(t, sink.Exists(filepath.Join("out", indexFileName)))
+}
@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"s3downloader/internal/progress"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// DownloadObjects downloads the given keys without listing the bucket. Each key is
+// checked with HeadObject first, using at most MaxWorkers concurrent requests, and
+// keys that do not exist are returned as missing instead of failing the run.
+func (d *Downloader) DownloadObjects(ctx context.Context, bucket string, keys []string, downloadPath string, progressChan chan<- progress.Progress) ([]string, error) {
+	return d.downloadObjects(ctx, bucket, keys, downloadPath, false, progressChan)
+}
+
+// downloadObjects implements DownloadObjects, replacing existing local files with overwrite
+func (d *Downloader) downloadObjects(ctx context.Context, bucket string, keys []string, downloadPath string, overwrite bool, progressChan chan<- progress.Progress) ([]string, error) {
+	var (
+		mu      sync.Mutex
+		missing []string
+	)
+
+	var produce objectProducer = func(ctx context.Context, enqueue func(target) bool) error {
+		keyChan := make(chan string)
+		var wg sync.WaitGroup
+
+		for i := 0; i < d.config.MaxWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for key := range keyChan {
+					out, err := d.s3.HeadObjectWithContext(ctx, d.headObjectInput(bucket, aws.String(key), nil))
+					if isNotFound(err) {
+						mu.Lock()
+						missing = append(missing, key)
+						mu.Unlock()
+						continue
+					}
+					if err != nil {
+						// Recorded like a failed download, so that every such key can be retried
+						enqueue(target{Object: &s3.Object{Key: aws.String(key)}, localKey: key, err: fmt.Errorf("failed to check '%s': %w", key, err)})
+						continue
+					}
+					enqueue(newTarget(&s3.Object{Key: aws.String(key), Size: out.ContentLength, ETag: out.ETag, LastModified: out.LastModified, StorageClass: out.StorageClass}))
+				}
+			}()
+		}
+
+	feed:
+		for _, key := range keys {
+			select {
+			case keyChan <- key:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		close(keyChan)
+		wg.Wait()
+		return nil
+	}
+	if d.config.Flatten {
+		produce = flatten(produce)
+	}
+	if overwrite {
+		produce = replacing(produce)
+	}
+
+	err := d.runDownload(ctx, bucket, downloadPath, newChannelObserver(ctx, progressChan), produce)
+	return missing, err
+}
+
+// isNotFound reports whether err is S3's response for a missing object
+func isNotFound(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+	return aerr.Code() == "NotFound" || aerr.Code() == s3.ErrCodeNoSuchKey
+}
@@ -0,0 +1,57 @@
+package aws
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadObjects(t *testing.T) {
+	client := newFakeS3(map[string]string{
+		"a.txt":     "alpha",
+		"dir/b.txt": "bravo",
+		"c.txt":     "charlie",
+	})
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+
+	missing, err := d.DownloadObjects(context.Background(), "bucket", []string{"a.txt", "dir/b.txt", "gone.txt"}, "out", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"gone.txt"}, missing)
+	assert.Equal(t, "alpha", sink.contents(filepath.Join("out", "a.txt")))
+	assert.Equal(t, "bravo", sink.contents(filepath.Join("out", "dir", "b.txt")))
+	assert.False(t, sink.Exists(filepath.Join("out", "c.txt")))
+	assert.Len(t, client.heads, 3)
+	assert.Equal(t, int64(2), d.Progress().FilesFound)
+}
+
+func TestDownloadObjectsRecordsEveryCheckError(t *testing.T) {
+	client := newFakeS3(map[string]string{
+		"a.txt": "alpha",
+		"b.txt": "bravo",
+		"c.txt": "charlie",
+	})
+	client.headFails["a.txt"] = true
+	client.headFails["c.txt"] = true
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+
+	missing, err := d.DownloadObjects(context.Background(), "bucket", []string{"a.txt", "b.txt", "c.txt"}, "out", nil)
+
+	assert.ErrorContains(t, err, "failed to check '")
+	assert.ErrorContains(t, err, "2 errors in total")
+	assert.Empty(t, missing)
+	assert.Equal(t, "bravo", sink.contents(filepath.Join("out", "b.txt")))
+	assert.False(t, sink.Exists(filepath.Join("out", "a.txt")))
+	assert.Equal(t, int64(2), d.Progress().ErrorCount)
+	assert.Equal(t, int64(1), d.Progress().FilesFound)
+
+	var failed []string
+	for _, f := range d.Failures() {
+		failed = append(failed, f.Key)
+	}
+	assert.ElementsMatch(t, []string{"a.txt", "c.txt"}, failed)
+}
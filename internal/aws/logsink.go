@@ -0,0 +1,39 @@
+package aws
+
+import (
+	"fmt"
+
+	"s3downloader/internal/logging"
+	"s3downloader/internal/metrics"
+)
+
+// LogSink receives human-readable lines about a run's activity as it happens,
+// such as every object that failed. Log may be called from several goroutines
+// at once.
+type LogSink interface {
+	Log(line string)
+}
+
+// SetLogSink sets where run activity is logged; nil, the default, discards it
+func (d *Downloader) SetLogSink(sink LogSink) {
+	d.logSink = sink
+}
+
+// SetLogger sets where the structured audit trail of runs and objects is
+// written; nil, the default, turns it off
+func (d *Downloader) SetLogger(logger *logging.Logger) {
+	d.logger = logger
+}
+
+// SetMetrics sets the counters that runs add their outcomes to; nil, the
+// default, turns them off
+func (d *Downloader) SetMetrics(m *metrics.Metrics) {
+	d.metrics = m
+}
+
+// logf formats a line for the log sink, if any
+func (d *Downloader) logf(format string, args ...any) {
+	if d.logSink != nil {
+		d.logSink.Log(fmt.Sprintf(format, args...))
+	}
+}
@@ -0,0 +1,118 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"s3downloader/internal/logging"
+	"s3downloader/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingLog keeps every logged line
+type recordingLog struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *recordingLog) Log(line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, line)
+}
+
+func TestLogSinkReceivesEveryError(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo", "c.txt": "charlie"})
+	client.failures["a.txt"] = -1
+	client.failures["c.txt"] = -1
+	d := newTestDownloader(client, newMemorySink())
+	logged := &recordingLog{}
+	d.SetLogSink(logged)
+
+	assert.Error(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+
+	if assert.Len(t, logged.lines, 2) {
+		assert.Contains(t, logged.lines[0]+logged.lines[1], "'a.txt'")
+		assert.Contains(t, logged.lines[0]+logged.lines[1], "'c.txt'")
+		assert.Contains(t, logged.lines[0], "Error: ")
+	}
+}
+
+func TestLoggerRecordsObjects(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo"})
+	client.failures["b.txt"] = -1
+	d := newTestDownloader(client, newMemorySink())
+	var buf bytes.Buffer
+	d.SetLogger(logging.New(&buf, slog.LevelDebug))
+
+	assert.Error(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+
+	events := make(map[string]map[string]any)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &event))
+		assert.Equal(t, "bucket", event["bucket"])
+		key, _ := event["key"].(string)
+		events[event["msg"].(string)+" "+key] = event
+	}
+	assert.Equal(t, "INFO", events["download started "]["level"])
+	assert.Equal(t, "DEBUG", events["object started a.txt"]["level"])
+	assert.Equal(t, 5.0, events["object downloaded a.txt"]["size"])
+	assert.Equal(t, "ERROR", events["object failed b.txt"]["level"])
+	assert.Contains(t, events["object failed b.txt"]["error"], "InternalError")
+	assert.Equal(t, 1.0, events["download failed "]["errors"])
+}
+
+// scrape returns the value of every metric in reg by name
+func scrape(t *testing.T, reg *prometheus.Registry) map[string]float64 {
+	families, err := reg.Gather()
+	assert.NoError(t, err)
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			if m.GetCounter() != nil {
+				values[family.GetName()] = m.GetCounter().GetValue()
+			} else {
+				values[family.GetName()] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	return values
+}
+
+func TestMetricsCountRuns(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo!", "c.txt": "charlie"})
+	client.failures["c.txt"] = -1
+	sink := newMemorySink()
+	w, err := sink.Create("out/a.txt")
+	assert.NoError(t, err)
+	w.Close()
+	d := newTestDownloader(client, sink)
+	reg := prometheus.NewRegistry()
+	d.SetMetrics(metrics.New(reg))
+
+	assert.Error(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+
+	got := scrape(t, reg)
+	assert.Equal(t, 1.0, got["s3downloader_files_downloaded_total"])
+	assert.Equal(t, 1.0, got["s3downloader_files_skipped_total"])
+	assert.Equal(t, 1.0, got["s3downloader_errors_total"])
+	assert.Equal(t, 6.0, got["s3downloader_bytes_downloaded_total"])
+	assert.Contains(t, got, "s3downloader_throughput_bytes_per_second")
+
+	// Counters keep growing across runs while the progress starts over
+	delete(client.failures, "c.txt")
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+	got = scrape(t, reg)
+	assert.Equal(t, 2.0, got["s3downloader_files_downloaded_total"])
+	assert.Equal(t, 3.0, got["s3downloader_files_skipped_total"])
+	assert.Equal(t, 13.0, got["s3downloader_bytes_downloaded_total"])
+}
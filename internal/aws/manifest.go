@@ -0,0 +1,76 @@
+package aws
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// manifestWriter streams a checksum manifest in the format of sha256sum, one
+// "<hash>  <path>" line per file with paths relative to the download path, so
+// that "sha256sum -c" run from the download path verifies every file
+type manifestWriter struct {
+	mu     sync.Mutex
+	file   *os.File
+	w      *bufio.Writer
+	err    error // First write error, reported by close
+	closed bool
+}
+
+// newManifestWriter creates the manifest file at path
+func newManifestWriter(path string) (*manifestWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create manifest '%s': %w", path, err)
+	}
+	return &manifestWriter{file: f, w: bufio.NewWriter(f)}, nil
+}
+
+// add records the SHA256 of the file at the slash-separated path key; it is a
+// no-op on a nil manifest or after close
+func (m *manifestWriter) add(key, sha256 string) {
+	if m == nil {
+		return
+	}
+
+	// Like sha256sum, escape names that would break the line format and mark
+	// the line with a leading backslash
+	line := sha256 + "  " + key + "\n"
+	if strings.ContainsAny(key, "\\\n\r") {
+		escaped := strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r").Replace(key)
+		line = "\\" + sha256 + "  " + escaped + "\n"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed || m.err != nil {
+		return
+	}
+	_, m.err = m.w.WriteString(line)
+}
+
+// close flushes and closes the manifest
+func (m *manifestWriter) close() error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+
+	if m.err == nil {
+		m.err = m.w.Flush()
+	}
+	if closeErr := m.file.Close(); m.err == nil {
+		m.err = closeErr
+	}
+	if m.err != nil {
+		return fmt.Errorf("failed to write manifest '%s': %w", m.file.Name(), m.err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifestListsDownloadedFiles(t *testing.T) {
+	objects := map[string]string{"a.txt": "alpha", "dir/b.txt": "bravo", "dir/sub/c.txt": "charlie"}
+	client := newFakeS3(objects)
+	downloadPath := t.TempDir()
+	manifestPath := filepath.Join(t.TempDir(), "SHA256SUMS")
+	d := newTestDownloader(client, LocalSink{})
+	d.config.ManifestPath = manifestPath
+	d.config.VerifyChecksum = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil)
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(manifestPath)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	sort.Strings(lines)
+
+	var want []string
+	for key, body := range objects {
+		sum := sha256.Sum256([]byte(body))
+		want = append(want, hex.EncodeToString(sum[:])+"  "+key)
+	}
+	sort.Strings(want)
+	assert.Equal(t, want, lines)
+
+	// Verifying and hashing for the manifest share a single read, so only the
+	// listing-provided ETags are needed and no HeadObject call is made
+	assert.Empty(t, client.heads)
+}
+
+func TestManifestIsSha256sumCompatible(t *testing.T) {
+	sha256sum, err := exec.LookPath("sha256sum")
+	if err != nil {
+		t.Skip("sha256sum is not available")
+	}
+
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "dir/with space.txt": "bravo", `odd\name.txt`: "charlie"})
+	downloadPath := t.TempDir()
+	manifestPath := filepath.Join(t.TempDir(), "SHA256SUMS")
+	d := newTestDownloader(client, LocalSink{})
+	d.config.ManifestPath = manifestPath
+
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil))
+
+	cmd := exec.Command(sha256sum, "-c", manifestPath)
+	cmd.Dir = downloadPath
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+	assert.Equal(t, 3, strings.Count(string(out), ": OK"))
+}
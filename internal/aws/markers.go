@@ -0,0 +1,15 @@
+package aws
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// isDirectoryMarker reports whether obj is a zero-byte "folder" placeholder, such as
+// those created by the AWS console, rather than a file. Its key ends with a slash
+// whatever the last segment is, so such keys must not be checked with filepath.Base.
+func isDirectoryMarker(obj *s3.Object) bool {
+	return strings.HasSuffix(aws.StringValue(obj.Key), "/") && aws.Int64Value(obj.Size) == 0
+}
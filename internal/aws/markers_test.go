@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDirectoryMarker(t *testing.T) {
+	testCases := []struct {
+		key  string
+		size int64
+		want bool
+	}{
+		{"a/b/", 0, true},
+		{"a/", 0, true},
+		{"/", 0, true},
+		{"a/b/c.txt", 0, false},
+		{"a/b/c.txt", 12, false},
+		{"a/b/", 12, false}, // Has content, so not a placeholder
+	}
+
+	for _, tc := range testCases {
+		obj := &s3.Object{Key: aws.String(tc.key), Size: aws.Int64(tc.size)}
+		assert.Equal(t, tc.want, isDirectoryMarker(obj), tc.key)
+	}
+}
+
+func TestDirectoryMarkers(t *testing.T) {
+	testCases := []struct {
+		name        string
+		createDirs  bool
+		wantDirs    []string
+		wantMissing []string
+	}{
+		{"Skip markers", false, []string{"a/b"}, []string{"a/empty-dir"}},
+		{"Create directories for markers", true, []string{"a/b", "a/empty-dir"}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{
+				"a/b/":         "",
+				"a/b/c.txt":    "content",
+				"a/empty-dir/": "",
+				"a/empty.txt":  "", // An empty file, not a marker
+				"/":            "", // A marker whose last segment is empty
+			})
+			downloadPath := t.TempDir()
+			d := newTestDownloader(client, LocalSink{})
+			d.config.CreateDirectoryMarkers = tc.createDirs
+
+			err := d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil)
+
+			assert.NoError(t, err)
+			data, err := os.ReadFile(filepath.Join(downloadPath, "a", "b", "c.txt"))
+			assert.NoError(t, err)
+			assert.Equal(t, "content", string(data))
+			info, err := os.Stat(filepath.Join(downloadPath, "a", "empty.txt"))
+			if assert.NoError(t, err) {
+				assert.True(t, info.Mode().IsRegular())
+				assert.Equal(t, int64(0), info.Size())
+			}
+			for _, dir := range tc.wantDirs {
+				assert.DirExists(t, filepath.Join(downloadPath, filepath.FromSlash(dir)))
+			}
+			for _, dir := range tc.wantMissing {
+				assert.NoDirExists(t, filepath.Join(downloadPath, filepath.FromSlash(dir)))
+			}
+
+			p := d.Progress()
+			assert.Equal(t, int64(2), p.FilesFound)
+			assert.Equal(t, int64(2), p.FilesDownloaded)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// metadataSuffix is appended to a downloaded file's name to name its metadata sidecar
+const metadataSuffix = ".meta.json"
+
+// objectMetadata is the content of a metadata sidecar file
+type objectMetadata struct {
+	Key          string            `json:"key"`
+	VersionID    string            `json:"versionId,omitempty"`
+	ETag         string            `json:"etag"`
+	Size         int64             `json:"size"`
+	LastModified time.Time         `json:"lastModified"`
+	StorageClass string            `json:"storageClass"`
+	ContentType  string            `json:"contentType,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+// writeMetadata saves the object's metadata from HeadObject as JSON next to its local file
+func (d *Downloader) writeMetadata(ctx context.Context, bucket string, obj target, localPath string) error {
+	key := aws.StringValue(obj.Key)
+	out, err := d.headObject(ctx, bucket, &obj)
+	if err != nil {
+		return fmt.Errorf("failed to get metadata of '%s': %w", key, err)
+	}
+
+	meta := objectMetadata{
+		Key:          key,
+		VersionID:    aws.StringValue(obj.versionID),
+		ETag:         aws.StringValue(out.ETag),
+		Size:         aws.Int64Value(out.ContentLength),
+		LastModified: aws.TimeValue(out.LastModified),
+		StorageClass: aws.StringValue(out.StorageClass),
+		ContentType:  aws.StringValue(out.ContentType),
+		Metadata:     aws.StringValueMap(out.Metadata),
+	}
+	if meta.StorageClass == "" {
+		meta.StorageClass = s3.StorageClassStandard // HeadObject omits the default class
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata of '%s': %w", key, err)
+	}
+
+	sidecar := localPath + metadataSuffix
+	f, err := d.sink.Create(sidecar)
+	if err != nil {
+		return fmt.Errorf("failed to create metadata file for '%s': %w", key, err)
+	}
+	if _, err := f.WriteAt(append(data, '\n'), 0); err != nil {
+		f.Close()
+		d.sink.Remove(sidecar)
+		return fmt.Errorf("failed to write metadata file for '%s': %w", key, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteMetadataSidecar(t *testing.T) {
+	client := newFakeS3(map[string]string{"dir/report.csv": "a,b\n1,2\n"})
+	client.etags["dir/report.csv"] = "0123456789abcdef0123456789abcdef"
+	client.classes["dir/report.csv"] = s3.StorageClassStandardIa
+	client.types["dir/report.csv"] = "text/csv"
+	client.metadata["dir/report.csv"] = map[string]string{"Source": "billing", "Run-Id": "42"}
+	downloadPath := t.TempDir()
+	d := newTestDownloader(client, LocalSink{})
+	d.config.WriteMetadata = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil)
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(downloadPath, "dir", "report.csv.meta.json"))
+	assert.NoError(t, err)
+	var meta map[string]any
+	assert.NoError(t, json.Unmarshal(data, &meta))
+	assert.Equal(t, map[string]any{
+		"key":          "dir/report.csv",
+		"etag":         `"0123456789abcdef0123456789abcdef"`,
+		"size":         float64(8),
+		"lastModified": "2024-01-02T03:04:05Z",
+		"storageClass": s3.StorageClassStandardIa,
+		"contentType":  "text/csv",
+		"metadata":     map[string]any{"Source": "billing", "Run-Id": "42"},
+	}, meta)
+	assert.Equal(t, int64(0), d.Progress().WarningCount)
+}
+
+func TestWriteMetadataFailureIsAWarning(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo"})
+	client.headFails["b.txt"] = true
+	downloadPath := t.TempDir()
+	d := newTestDownloader(client, LocalSink{})
+	d.config.WriteMetadata = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil)
+
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(downloadPath, "a.txt.meta.json"))
+	assert.FileExists(t, filepath.Join(downloadPath, "b.txt"))
+	assert.NoFileExists(t, filepath.Join(downloadPath, "b.txt.meta.json"))
+	p := d.Progress()
+	assert.Equal(t, int64(2), p.FilesDownloaded)
+	assert.Equal(t, int64(0), p.ErrorCount)
+	assert.Equal(t, int64(1), p.WarningCount)
+}
@@ -0,0 +1,162 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"s3downloader/internal/progress"
+	"s3downloader/pkg/fileutils"
+)
+
+// CleanStaleFiles walks root and removes every regular file whose slash-separated
+// path relative to root is not in keep. It must only be called after a download
+// pass has completed fully, otherwise files that were simply not reached yet
+// would be deleted. Progress is reported in the cleaning phase and the walk
+// stops as soon as ctx is canceled.
+func CleanStaleFiles(ctx context.Context, root string, keep map[string]struct{}, progressChan chan<- progress.Progress) (int64, error) {
+	tracker := progress.NewTracker(progress.PhaseCleaning)
+	err := cleanStaleFiles(ctx, root, "", keep, tracker, newChannelObserver(ctx, progressChan))
+	return tracker.FilesDeleted.Load(), err
+}
+
+// cleanStaleFiles implements CleanStaleFiles, counting into tracker and reporting
+// each file to observer, which may be nil. Only files whose relative path starts
+// with match are considered; the others are neither scanned nor deleted.
+func cleanStaleFiles(ctx context.Context, root, match string, keep map[string]struct{}, tracker *progress.Tracker, observer ProgressObserver) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !strings.HasPrefix(rel, match) {
+			return nil
+		}
+		tracker.FilesScanned.Add(1)
+		if _, ok := keep[rel]; !ok {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to delete '%s': %w", path, err)
+			}
+			tracker.FilesDeleted.Add(1)
+		}
+
+		report(observer, tracker)
+		return nil
+	})
+}
+
+// relativeTo returns the slash-separated path of path relative to root if it is inside root
+func relativeTo(root, path string) (string, bool) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(absRoot, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// keptKeys collects the local keys of every listed object so that mirror mode
+// knows which local files still have a counterpart in the bucket
+type keptKeys struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+func newKeptKeys() *keptKeys {
+	return &keptKeys{keys: make(map[string]struct{})}
+}
+
+// record wraps produce so that every enqueued object's local key is kept
+func (k *keptKeys) record(produce objectProducer) objectProducer {
+	return func(ctx context.Context, enqueue func(target) bool) error {
+		return produce(ctx, func(obj target) bool {
+			k.mu.Lock()
+			k.keys[obj.localKey] = struct{}{}
+			k.mu.Unlock()
+			return enqueue(obj)
+		})
+	}
+}
+
+// mirror deletes the local files under the prefix-mapped part of downloadPath
+// that were not seen in the listing. It refuses to run when nothing was listed,
+// since that almost always means a wrong bucket or prefix rather than an empty one.
+func (d *Downloader) mirror(ctx context.Context, downloadPath, prefix string, keep *keptKeys, observer ProgressObserver) error {
+	if _, ok := d.sink.(LocalSink); !ok {
+		return errors.New("mirror mode requires downloading to the local filesystem")
+	}
+	if len(keep.keys) == 0 {
+		return fmt.Errorf("mirror aborted: no objects were listed under prefix '%s', refusing to delete local files", prefix)
+	}
+
+	// Local keys start with the prefix unless they were renamed. The walk starts at the
+	// prefix's directory and only considers the files matching the rest of it, so that
+	// the prefix "logs/app" leaves "logs/db.log" alone.
+	subtree, match := "", ""
+	if !d.config.StripPrefix && !d.config.Flatten {
+		subtree = fileutils.SanitizeFilename(prefix[:strings.LastIndex(prefix, "/")+1])
+		match = strings.TrimPrefix(fileutils.SanitizeFilename(prefix), subtree)
+	}
+
+	relative := make(map[string]struct{}, len(keep.keys)+1)
+	for key := range keep.keys {
+		names := []string{key}
+		if d.config.DecompressGzip && d.config.StripGzipSuffix {
+			// Whether the suffix was dropped depends on the encoding, keep either name
+			names = append(names, strippedGzipName(key))
+		}
+		for _, name := range names {
+			name = fileutils.SanitizeFilename(name) // As saved by the workers
+			relative[strings.TrimPrefix(name, subtree)] = struct{}{}
+			if d.config.WriteMetadata {
+				relative[strings.TrimPrefix(name+metadataSuffix, subtree)] = struct{}{}
+			}
+		}
+	}
+	if d.config.GenerateIndex && subtree == "" {
+		relative[indexFileName] = struct{}{}
+	}
+
+	root := filepath.Join(downloadPath, filepath.FromSlash(subtree))
+	// The run's own output files may be written among the downloads; losing the
+	// state file would also lose what the next run is to resume from
+	for _, output := range []string{d.config.ReportPath, d.config.ManifestPath, d.config.StateFile} {
+		if rel, ok := relativeTo(root, output); output != "" && ok {
+			relative[rel] = struct{}{}
+		}
+	}
+	if _, err := os.Stat(root); errors.Is(err, fs.ErrNotExist) {
+		// Nothing was ever written there, so nothing is stale
+		return nil
+	}
+
+	tracker := d.tracker.Load()
+	tracker.SetPhase(progress.PhaseCleaning)
+	if err := cleanStaleFiles(ctx, root, match, relative, tracker, observer); err != nil {
+		return fmt.Errorf("mirror failed: %w", err)
+	}
+	return nil
+}
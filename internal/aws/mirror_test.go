@@ -0,0 +1,168 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"s3downloader/internal/progress"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createFiles(t *testing.T, root string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0o600))
+	}
+}
+
+func TestCleanStaleFiles(t *testing.T) {
+	root := t.TempDir()
+	createFiles(t, root, "keep.txt", "stale.txt", "dir/keep.txt", "dir/stale.txt")
+
+	keep := map[string]struct{}{"keep.txt": {}, "dir/keep.txt": {}}
+	progressChan := make(chan progress.Progress, 10)
+
+	deleted, err := CleanStaleFiles(context.Background(), root, keep, progressChan)
+	close(progressChan)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	assert.FileExists(t, filepath.Join(root, "keep.txt"))
+	assert.FileExists(t, filepath.Join(root, "dir", "keep.txt"))
+	assert.NoFileExists(t, filepath.Join(root, "stale.txt"))
+	assert.NoFileExists(t, filepath.Join(root, "dir", "stale.txt"))
+
+	var last progress.Progress
+	for p := range progressChan {
+		assert.Equal(t, progress.PhaseCleaning, p.Phase)
+		last = p
+	}
+	assert.Equal(t, int64(4), last.FilesScanned)
+	assert.Equal(t, int64(2), last.FilesDeleted)
+}
+
+func TestCleanStaleFilesCancelMidDelete(t *testing.T) {
+	root := t.TempDir()
+	const total = 20
+	for i := 0; i < total; i++ {
+		createFiles(t, root, fmt.Sprintf("stale-%02d.txt", i))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	progressChan := make(chan progress.Progress)
+
+	type result struct {
+		deleted int64
+		err     error
+	}
+	resultChan := make(chan result, 1)
+	go func() {
+		deleted, err := CleanStaleFiles(ctx, root, map[string]struct{}{}, progressChan)
+		resultChan <- result{deleted, err}
+	}()
+
+	// Cancel as soon as the first deletion is reported
+	<-progressChan
+	cancel()
+
+	var res result
+	for done := false; !done; {
+		select {
+		case <-progressChan:
+		case res = <-resultChan:
+			done = true
+		}
+	}
+
+	assert.ErrorIs(t, res.err, context.Canceled)
+	assert.Less(t, res.deleted, int64(total))
+
+	entries, err := os.ReadDir(root)
+	assert.NoError(t, err)
+	assert.Equal(t, total-int(res.deleted), len(entries))
+}
+
+func TestMirrorRemovesStaleFiles(t *testing.T) {
+	client := newFakeS3(map[string]string{"data/a.txt": "a", "data/sub/b.txt": "b"})
+	downloadPath := t.TempDir()
+	createFiles(t, downloadPath, "data/old.txt", "data/sub/old.txt", "other/untouched.txt")
+	d := newTestDownloader(client, LocalSink{})
+	d.config.Mirror = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "data/", downloadPath, nil)
+
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(downloadPath, "data", "a.txt"))
+	assert.FileExists(t, filepath.Join(downloadPath, "data", "sub", "b.txt"))
+	assert.NoFileExists(t, filepath.Join(downloadPath, "data", "old.txt"))
+	assert.NoFileExists(t, filepath.Join(downloadPath, "data", "sub", "old.txt"))
+	assert.FileExists(t, filepath.Join(downloadPath, "other", "untouched.txt"))
+
+	p := d.Progress()
+	assert.Equal(t, progress.PhaseCleaning, p.Phase)
+	assert.Equal(t, int64(2), p.FilesDownloaded)
+	assert.Equal(t, int64(4), p.FilesScanned)
+	assert.Equal(t, int64(2), p.FilesDeleted)
+}
+
+func TestMirrorStaysInsidePartialPrefix(t *testing.T) {
+	client := newFakeS3(map[string]string{"logs/app.log": "a", "logs/app/1.log": "b"})
+	downloadPath := t.TempDir()
+	createFiles(t, downloadPath, "logs/app.old", "logs/app/old.log", "logs/db.log", "logs/db/1.log")
+	d := newTestDownloader(client, LocalSink{})
+	d.config.Mirror = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "logs/app", downloadPath, nil)
+
+	assert.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(downloadPath, "logs", "app.old"))
+	assert.NoFileExists(t, filepath.Join(downloadPath, "logs", "app", "old.log"))
+	assert.FileExists(t, filepath.Join(downloadPath, "logs", "db.log"), "outside the prefix")
+	assert.FileExists(t, filepath.Join(downloadPath, "logs", "db", "1.log"), "outside the prefix")
+	assert.Equal(t, int64(4), d.Progress().FilesScanned)
+	assert.Equal(t, int64(2), d.Progress().FilesDeleted)
+}
+
+func TestMirrorKeepsOutputFiles(t *testing.T) {
+	client := newFakeS3(map[string]string{"data/a.txt": "a"})
+	downloadPath := t.TempDir()
+	createFiles(t, downloadPath, "old.txt")
+	d := newTestDownloader(client, LocalSink{})
+	d.config.Mirror = true
+	d.config.StripPrefix = true // The whole download path is cleaned
+	d.config.StateFile = filepath.Join(downloadPath, ".s3downloader-state")
+	d.config.ReportPath = filepath.Join(downloadPath, "report.csv")
+	d.config.ManifestPath = filepath.Join(downloadPath, "manifest.json")
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "data/", downloadPath, nil)
+
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(downloadPath, "a.txt"))
+	assert.NoFileExists(t, filepath.Join(downloadPath, "old.txt"))
+	assert.FileExists(t, d.config.StateFile)
+	assert.FileExists(t, d.config.ReportPath)
+	assert.FileExists(t, d.config.ManifestPath)
+	assert.Equal(t, int64(1), d.Progress().FilesDeleted)
+}
+
+func TestMirrorAbortsOnEmptyListing(t *testing.T) {
+	client := newFakeS3(map[string]string{"data/a.txt": "a"})
+	downloadPath := t.TempDir()
+	createFiles(t, downloadPath, "keep.txt", "typo/keep.txt")
+	d := newTestDownloader(client, LocalSink{})
+	d.config.Mirror = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "typo/", downloadPath, nil)
+
+	assert.ErrorContains(t, err, "no objects were listed")
+	assert.FileExists(t, filepath.Join(downloadPath, "keep.txt"))
+	assert.FileExists(t, filepath.Join(downloadPath, "typo", "keep.txt"))
+	assert.Equal(t, int64(0), d.Progress().FilesDeleted)
+}
@@ -0,0 +1,23 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// DownloadObject downloads a single object to localPath without listing the bucket and
+// returns the number of bytes written. An existing local file is left untouched and
+// reported as zero bytes unless overwrite is set. The object's size is not known up
+// front, so it is given the timeout of a large file.
+func (d *Downloader) DownloadObject(ctx context.Context, bucket, key, localPath string, overwrite bool) (int64, error) {
+	if !overwrite && d.sink.Exists(localPath) {
+		return 0, nil
+	}
+	if err := d.sink.Mkdir(filepath.Dir(localPath), d.dirMode()); err != nil {
+		return 0, fmt.Errorf("failed to create directory for '%s': %w", key, err)
+	}
+	return d.downloadFile(ctx, d.newTransferManager(), d.getObjectInput(bucket, aws.String(key), nil), -1, localPath, largeFileTimeout)
+}
@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadObject(t *testing.T) {
+	localPath := filepath.Join("out", "dir", "a.txt")
+
+	testCases := []struct {
+		name      string
+		existing  string
+		overwrite bool
+		expected  string
+		written   int64
+	}{
+		{"New file", "", false, "alpha", 5},
+		{"Existing file kept", "old", false, "old", 0},
+		{"Existing file replaced", "old", true, "alpha", 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{"dir/a.txt": "alpha"})
+			sink := newMemorySink()
+			if tc.existing != "" {
+				sink.files[localPath] = aws.NewWriteAtBuffer([]byte(tc.existing))
+			}
+			d := newTestDownloader(client, sink)
+
+			n, err := d.DownloadObject(context.Background(), "bucket", "dir/a.txt", localPath, tc.overwrite)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.written, n)
+			assert.Equal(t, tc.expected, sink.contents(localPath))
+		})
+	}
+}
+
+func TestDownloadObjectMissingKey(t *testing.T) {
+	client := newFakeS3(nil)
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+
+	localPath := filepath.Join("out", "missing.txt")
+	_, err := d.DownloadObject(context.Background(), "bucket", "missing.txt", localPath, false)
+
+	assert.ErrorContains(t, err, "missing.txt")
+	var aerr awserr.Error
+	if assert.True(t, errors.As(err, &aerr)) {
+		assert.Equal(t, s3.ErrCodeNoSuchKey, aerr.Code())
+	}
+	assert.False(t, sink.Exists(localPath))
+}
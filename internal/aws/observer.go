@@ -0,0 +1,41 @@
+package aws
+
+import (
+	"context"
+
+	"s3downloader/internal/progress"
+)
+
+// ProgressObserver receives the progress of a download. OnProgress is called from the
+// downloading goroutines, possibly concurrently, and should return quickly since it
+// delays the download while it runs.
+type ProgressObserver interface {
+	OnProgress(p progress.Progress)
+	OnComplete(p progress.Progress)
+}
+
+// channelObserver forwards progress to a channel until ctx is canceled, after which
+// updates are dropped instead of blocking on a reader that has gone away
+type channelObserver struct {
+	ctx context.Context
+	ch  chan<- progress.Progress
+}
+
+// newChannelObserver adapts a progress channel to a ProgressObserver; a nil channel gives a nil observer
+func newChannelObserver(ctx context.Context, ch chan<- progress.Progress) ProgressObserver {
+	if ch == nil {
+		return nil
+	}
+	return channelObserver{ctx: ctx, ch: ch}
+}
+
+// OnProgress sends p to the channel
+func (o channelObserver) OnProgress(p progress.Progress) {
+	select {
+	case o.ch <- p:
+	case <-o.ctx.Done():
+	}
+}
+
+// OnComplete does nothing; channel readers learn about completion from the method returning
+func (o channelObserver) OnComplete(progress.Progress) {}
@@ -0,0 +1,93 @@
+package aws
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"s3downloader/internal/progress"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingObserver captures the callbacks it receives
+type recordingObserver struct {
+	mu        sync.Mutex
+	updates   []progress.Progress
+	completes []progress.Progress
+	late      int // OnProgress calls after OnComplete
+}
+
+func (o *recordingObserver) OnProgress(p progress.Progress) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.completes) > 0 {
+		o.late++
+	}
+	o.updates = append(o.updates, p)
+}
+
+func (o *recordingObserver) OnComplete(p progress.Progress) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.completes = append(o.completes, p)
+}
+
+func TestListAndDownloadObjectsWithObserver(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo", "c.txt": "charlie"})
+	d := newTestDownloader(client, newMemorySink())
+	d.config.MaxWorkers = 1 // Keeps the order of the callbacks predictable
+	observer := &recordingObserver{}
+
+	err := d.ListAndDownloadObjectsWithObserver(context.Background(), "bucket", "", "out", observer)
+
+	assert.NoError(t, err)
+	if assert.Len(t, observer.completes, 1) {
+		final := observer.completes[0]
+		assert.Equal(t, int64(3), final.FilesFound)
+		assert.Equal(t, int64(3), final.FilesDownloaded)
+		assert.True(t, final.ListingComplete)
+	}
+
+	// Counters only move forward and every object is reported as found and as downloaded
+	var found, downloaded []int64
+	var last progress.Progress
+	for _, p := range observer.updates {
+		assert.GreaterOrEqual(t, p.FilesFound, last.FilesFound)
+		assert.GreaterOrEqual(t, p.FilesDownloaded, last.FilesDownloaded)
+		if p.FilesFound > last.FilesFound {
+			found = append(found, p.FilesFound)
+		}
+		if p.FilesDownloaded > last.FilesDownloaded {
+			downloaded = append(downloaded, p.FilesDownloaded)
+		}
+		last = p
+	}
+	assert.Equal(t, []int64{1, 2, 3}, found)
+	assert.Equal(t, []int64{1, 2, 3}, downloaded)
+	assert.Equal(t, observer.completes[0].FilesDownloaded, last.FilesDownloaded)
+}
+
+func TestObserverCompletesOnCancel(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c", "d.txt": "d"})
+	client.delay = time.Hour
+	d := newTestDownloader(client, newMemorySink())
+	observer := &recordingObserver{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	err := d.ListAndDownloadObjectsWithObserver(ctx, "bucket", "", "out", observer)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, observer.completes, 1)
+
+	// Nothing is reported once the run has completed
+	time.Sleep(50 * time.Millisecond)
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+	assert.Zero(t, observer.late)
+}
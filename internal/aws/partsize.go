@@ -0,0 +1,23 @@
+package aws
+
+// partStrategy returns the part size and the number of parts downloaded in parallel for
+// an object of the given size, within the configured PartSize and Concurrency. Objects
+// of up to MinPartSize take a single request. Objects too small to keep Concurrency parts
+// of PartSize busy are split into smaller parts, down to MinPartSize, so that they still
+// download in parallel; larger ones use the configured part size and concurrency. An
+// unknown size, below zero, also gets the configured settings.
+func (c Config) partStrategy(size int64) (partSize int64, concurrency int) {
+	switch {
+	case size < 0 || size >= c.PartSize*int64(c.Concurrency):
+		return c.PartSize, c.Concurrency
+	case size <= MinPartSize:
+		return c.PartSize, 1
+	}
+	partSize = max(MinPartSize, ceilDiv(size, int64(c.Concurrency)))
+	return partSize, int(ceilDiv(size, partSize))
+}
+
+// ceilDiv divides a by b, rounding up
+func ceilDiv(a, b int64) int64 {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartStrategy(t *testing.T) {
+	const mb = 1024 * 1024
+	testCases := []struct {
+		name        string
+		size        int64
+		partSize    int64
+		concurrency int
+	}{
+		{"Unknown size", -1, 10 * mb, 10},
+		{"Empty", 0, 10 * mb, 1},
+		{"Tiny", 1024, 10 * mb, 1},
+		{"At the minimum part size", 5 * mb, 10 * mb, 1},
+		{"Just above the minimum part size", 5*mb + 1, 5 * mb, 2},
+		{"Medium keeps the minimum part size", 32 * mb, 5 * mb, 7},
+		{"Medium fills every part in flight", 80 * mb, 8 * mb, 10},
+		{"Just below the configured part size", 100*mb - 10, 10*mb - 1, 10},
+		{"Configured part size and concurrency", 100 * mb, 10 * mb, 10},
+		{"Multi-GB", 5 * 1024 * mb, 10 * mb, 10},
+	}
+
+	cfg := DefaultConfig()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			partSize, concurrency := cfg.partStrategy(tc.size)
+			assert.Equal(t, tc.partSize, partSize)
+			assert.Equal(t, tc.concurrency, concurrency)
+		})
+	}
+}
+
+func TestPartStrategyStaysWithinBounds(t *testing.T) {
+	cfg := ConservativeConfig()
+	for _, size := range []int64{0, 1, MinPartSize, MinPartSize + 1, 3 * MinPartSize, 1 << 40} {
+		partSize, concurrency := cfg.partStrategy(size)
+		assert.GreaterOrEqual(t, partSize, int64(MinPartSize))
+		assert.LessOrEqual(t, partSize, cfg.PartSize)
+		assert.GreaterOrEqual(t, concurrency, 1)
+		assert.LessOrEqual(t, concurrency, cfg.Concurrency)
+	}
+}
+
+func TestMediumObjectDownloadsInSmallerParts(t *testing.T) {
+	body := strings.Repeat("x", 3*MinPartSize-1)
+	client := newFakeS3(map[string]string{"medium.bin": body})
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+
+	var ranges []string
+	for _, get := range client.gets {
+		ranges = append(ranges, aws.StringValue(get.Range))
+	}
+	assert.ElementsMatch(t, []string{"bytes=0-5242879", "bytes=5242880-10485759", "bytes=10485760-15728639"}, ranges)
+	assert.Equal(t, int64(len(body)), d.Progress().TotalBytes)
+}
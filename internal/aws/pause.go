@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"context"
+	"sync"
+)
+
+// pauseGate holds workers back between files while a download is paused
+type pauseGate struct {
+	mu      sync.Mutex
+	resumed chan struct{} // Closed on resume; nil while not paused
+}
+
+func (g *pauseGate) pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed == nil {
+		g.resumed = make(chan struct{})
+	}
+}
+
+func (g *pauseGate) resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed != nil {
+		close(g.resumed)
+		g.resumed = nil
+	}
+}
+
+func (g *pauseGate) paused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.resumed != nil
+}
+
+// wait blocks while the gate is paused, reporting false if ctx is done first
+func (g *pauseGate) wait(ctx context.Context) bool {
+	g.mu.Lock()
+	resumed := g.resumed
+	g.mu.Unlock()
+	if resumed == nil {
+		return true
+	}
+	select {
+	case <-resumed:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Pause stops the workers from starting new files. Files already downloading finish,
+// and listed objects stay queued until Resume is called.
+func (d *Downloader) Pause() {
+	d.pause.pause()
+}
+
+// Resume lets the workers continue with the queued files after Pause
+func (d *Downloader) Resume() {
+	d.pause.resume()
+}
+
+// Paused reports whether the download is paused
+func (d *Downloader) Paused() bool {
+	return d.pause.paused()
+}
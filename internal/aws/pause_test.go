@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPauseAndResume(t *testing.T) {
+	objects := make(map[string]string)
+	for i := 0; i < 8; i++ {
+		objects[fmt.Sprintf("file-%d.txt", i)] = "content"
+	}
+	client := newFakeS3(objects)
+	client.delay = 20 * time.Millisecond
+	d := newTestDownloader(client, newMemorySink())
+	d.config.MaxWorkers = 2
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+	}()
+
+	assert.Eventually(t, func() bool { return d.Progress().FilesDownloaded >= 1 }, time.Second, time.Millisecond)
+	d.Pause()
+	assert.True(t, d.Paused())
+
+	// Files that were already downloading finish, then nothing else starts
+	time.Sleep(100 * time.Millisecond)
+	paused := d.Progress().FilesDownloaded
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, paused, d.Progress().FilesDownloaded)
+	assert.Less(t, paused, int64(len(objects)))
+
+	d.Resume()
+	assert.False(t, d.Paused())
+
+	select {
+	case err := <-errChan:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("download did not finish after resuming")
+	}
+	assert.Equal(t, int64(len(objects)), d.Progress().FilesDownloaded)
+	assert.Len(t, client.lists, 1) // Resuming does not list again
+}
+
+func TestStopWhilePaused(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "a", "b.txt": "b"})
+	d := newTestDownloader(client, newMemorySink())
+	d.Pause()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := d.ListAndDownloadObjects(ctx, "bucket", "", "out", nil)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int64(0), d.Progress().FilesDownloaded)
+	assert.False(t, d.Paused()) // The next run starts unpaused
+}
@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"os"
+
+	"s3downloader/pkg/fileutils"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// executableTypes are the Content-Types of scripts and programs, which
+// MarkExecutable makes executable
+var executableTypes = map[string]bool{
+	"application/x-sh":          true,
+	"application/x-shellscript": true,
+	"text/x-sh":                 true,
+	"text/x-shellscript":        true,
+	"application/x-executable":  true,
+	"application/x-elf":         true,
+	"application/x-mach-binary": true,
+}
+
+// defaultExecutableBase is made executable when MarkExecutable applies and no
+// FileMode is set
+const defaultExecutableBase os.FileMode = 0o644
+
+// dirMode returns the permissions of directories created by the downloader
+func (d *Downloader) dirMode() os.FileMode {
+	if d.config.DirMode == 0 {
+		return fileutils.DefaultDirMode
+	}
+	return d.config.DirMode
+}
+
+// isExecutableType reports whether contentType, ignoring its parameters, is a
+// script or program
+func isExecutableType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && executableTypes[mediaType]
+}
+
+// executableMode adds the execute bit for everyone who may read a file with mode
+func executableMode(mode os.FileMode) os.FileMode {
+	return mode | (mode&0o444)>>2
+}
+
+// setFileMode applies FileMode to the downloaded obj at localPath and, with
+// MarkExecutable, makes it executable if its Content-Type is a script or program
+func (d *Downloader) setFileMode(ctx context.Context, bucket string, obj target, localPath string) error {
+	key := aws.StringValue(obj.Key)
+	mode := d.config.FileMode
+	if d.config.MarkExecutable {
+		out, err := d.headObject(ctx, bucket, &obj)
+		if err != nil {
+			return fmt.Errorf("failed to get content type of '%s': %w", key, err)
+		}
+		if isExecutableType(aws.StringValue(out.ContentType)) {
+			if mode == 0 {
+				mode = defaultExecutableBase
+			}
+			mode = executableMode(mode)
+		}
+	}
+	if mode == 0 {
+		return nil
+	}
+	if err := d.sink.Chmod(localPath, mode); err != nil {
+		return fmt.Errorf("failed to set permissions of '%s': %w", key, err)
+	}
+	return nil
+}
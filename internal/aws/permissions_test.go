@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsExecutableType(t *testing.T) {
+	testCases := []struct {
+		contentType string
+		want        bool
+	}{
+		{"application/x-sh", true},
+		{"text/x-shellscript; charset=utf-8", true},
+		{"Application/X-Executable", true},
+		{"text/plain", false},
+		{"application/octet-stream", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.contentType, func(t *testing.T) {
+			assert.Equal(t, tc.want, isExecutableType(tc.contentType))
+		})
+	}
+}
+
+func TestExecutableMode(t *testing.T) {
+	assert.Equal(t, os.FileMode(0o755), executableMode(0o644))
+	assert.Equal(t, os.FileMode(0o750), executableMode(0o640))
+	assert.Equal(t, os.FileMode(0o700), executableMode(0o600))
+	assert.Equal(t, os.FileMode(0o555), executableMode(0o444))
+}
+
+func TestDownloadAppliesPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permissions do not apply on Windows")
+	}
+
+	testCases := []struct {
+		name           string
+		fileMode       os.FileMode
+		dirMode        os.FileMode
+		markExecutable bool
+		wantData       os.FileMode
+		wantScript     os.FileMode
+		wantDir        os.FileMode
+	}{
+		{"Configured modes", 0o600, 0o700, false, 0o600, 0o600, 0o700},
+		{"Executable scripts", 0o640, 0o750, true, 0o640, 0o750, 0o750},
+		{"Executable without a file mode", 0, 0o700, true, 0, 0o755, 0o700},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{"bin/run.sh": "#!/bin/sh\n", "bin/data.csv": "a,b\n"})
+			client.types["bin/run.sh"] = "application/x-sh"
+			client.types["bin/data.csv"] = "text/csv"
+			downloadPath := filepath.Join(t.TempDir(), "out")
+			d := newTestDownloader(client, LocalSink{})
+			d.config.FileMode = tc.fileMode
+			d.config.DirMode = tc.dirMode
+			d.config.MarkExecutable = tc.markExecutable
+
+			assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil))
+
+			perm := func(name string) os.FileMode {
+				info, err := os.Stat(filepath.Join(downloadPath, name))
+				assert.NoError(t, err)
+				return info.Mode().Perm()
+			}
+			if tc.wantData != 0 {
+				assert.Equal(t, tc.wantData, perm("bin/data.csv"))
+			} else {
+				assert.Zero(t, perm("bin/data.csv")&0o111, "files are not executable by default")
+			}
+			assert.Equal(t, tc.wantScript, perm("bin/run.sh"))
+			assert.Equal(t, tc.wantDir, perm("bin"))
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package aws
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// newestObjects returns the n most recently modified objects, newest first.
+// Objects with the same timestamp are ordered by key so the selection is stable.
+func newestObjects(objs []*s3.Object, n int) []*s3.Object {
+	sort.Slice(objs, func(i, j int) bool {
+		ti, tj := aws.TimeValue(objs[i].LastModified), aws.TimeValue(objs[j].LastModified)
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return aws.StringValue(objs[i].Key) < aws.StringValue(objs[j].Key)
+	})
+	if len(objs) > n {
+		objs = objs[:n]
+	}
+	return objs
+}
@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewestObjects(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	object := func(key string, hours int) *s3.Object {
+		return &s3.Object{Key: aws.String(key), LastModified: aws.Time(base.Add(time.Duration(hours) * time.Hour))}
+	}
+	keys := func(objs []*s3.Object) []string {
+		var result []string
+		for _, obj := range objs {
+			result = append(result, aws.StringValue(obj.Key))
+		}
+		return result
+	}
+
+	objs := []*s3.Object{object("a", 1), object("b", 3), object("d", 2), object("c", 2), object("e", 0)}
+
+	assert.Equal(t, []string{"b", "c", "d"}, keys(newestObjects(objs, 3)))
+	assert.Equal(t, []string{"b", "c", "d", "a", "e"}, keys(newestObjects(objs, 10)))
+}
+
+func TestListAndDownloadObjectsMaxRecentFiles(t *testing.T) {
+	client := newFakeS3(map[string]string{
+		"logs/1.log": "1",
+		"logs/2.log": "2",
+		"logs/3.log": "3",
+		"logs/4.log": "4",
+		"logs/5.log": "5",
+	})
+	for i, key := range []string{"logs/1.log", "logs/2.log", "logs/3.log", "logs/4.log", "logs/5.log"} {
+		client.times[key] = client.modified.Add(time.Duration(i) * time.Minute)
+	}
+	client.pageSize = 2
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+	d.config.MaxRecentFiles = 2
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "logs/", "out", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), d.Progress().FilesFound)
+	assert.Len(t, client.gets, 2)
+	for _, key := range []string{"1", "2", "3"} {
+		assert.False(t, sink.Exists(filepath.Join("out", "logs", key+".log")))
+	}
+	assert.Equal(t, "4", sink.contents(filepath.Join("out", "logs", "4.log")))
+	assert.Equal(t, "5", sink.contents(filepath.Join("out", "logs", "5.log")))
+}
@@ -0,0 +1,31 @@
+package aws
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+)
+
+// Regions lists the AWS regions of the commercial, China and GovCloud
+// partitions, sorted by name. It comes from the SDK's endpoint metadata, so it
+// grows with SDK updates rather than by hand.
+var Regions = partitionRegions(endpoints.AwsPartition(), endpoints.AwsCnPartition(), endpoints.AwsUsGovPartition())
+
+// partitionRegions returns the sorted region IDs of the given partitions
+func partitionRegions(partitions ...endpoints.Partition) []string {
+	var regions []string
+	for _, p := range partitions {
+		for id := range p.Regions() {
+			regions = append(regions, id)
+		}
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// KnownRegion reports whether region is one of Regions. S3-compatible services
+// often use names of their own, so an unknown region is not necessarily wrong.
+func KnownRegion(region string) bool {
+	i := sort.SearchStrings(Regions, region)
+	return i < len(Regions) && Regions[i] == region
+}
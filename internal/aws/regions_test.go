@@ -0,0 +1,36 @@
+package aws
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegions(t *testing.T) {
+	assert.NotEmpty(t, Regions)
+	assert.True(t, sort.StringsAreSorted(Regions))
+	for _, region := range []string{"us-east-1", "us-west-2", "eu-west-1", "eu-central-1", "ap-southeast-2", "cn-north-1", "us-gov-west-1"} {
+		assert.Contains(t, Regions, region)
+	}
+}
+
+func TestKnownRegion(t *testing.T) {
+	testCases := []struct {
+		region string
+		want   bool
+	}{
+		{"eu-west-1", true},
+		{"us-east-1", true},
+		{"us-east1", false},
+		{"EU-WEST-1", false},
+		{"", false},
+		{"zz-nowhere-9", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.region, func(t *testing.T) {
+			assert.Equal(t, tc.want, KnownRegion(tc.region))
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package aws
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// Outcomes of an object in the JSON report
+const (
+	statusDownloaded = "downloaded"
+	statusSkipped    = "skipped"
+	statusError      = "error"
+)
+
+// reportEntry is the result of a single object in the JSON report
+type reportEntry struct {
+	Bucket    string `json:"bucket,omitempty"` // Set in runs over several buckets
+	Key       string `json:"key"`
+	VersionID string `json:"versionId,omitempty"`
+	Size      int64  `json:"size"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// reportTotals summarizes the entries of a JSON report
+type reportTotals struct {
+	Objects    int64 `json:"objects"`
+	Downloaded int64 `json:"downloaded"`
+	Skipped    int64 `json:"skipped"`
+	Errors     int64 `json:"errors"`
+	Bytes      int64 `json:"bytes"`
+}
+
+// reportWriter streams per-object results to a JSON file as workers finish them,
+// so that a huge run does not keep its results in memory. The file is a single
+// object with an "objects" array followed by "totals", "elapsedSeconds" and,
+// for a failed run, "error".
+type reportWriter struct {
+	mu     sync.Mutex
+	file   *os.File
+	w      *bufio.Writer
+	start  time.Time
+	totals reportTotals
+	err    error // First write error, reported by close
+	closed bool
+}
+
+// newReportWriter creates the report file at path and writes its header
+func newReportWriter(path string) (*reportWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report '%s': %w", path, err)
+	}
+	r := &reportWriter{file: f, w: bufio.NewWriter(f), start: time.Now()}
+	_, r.err = r.w.WriteString(`{"objects":[`)
+	return r, nil
+}
+
+// add records the outcome of file; it is a no-op on a nil report or after close
+func (r *reportWriter) add(file target, status string, err error) {
+	if r == nil {
+		return
+	}
+	entry := reportEntry{
+		Bucket:    file.bucket,
+		Key:       aws.StringValue(file.Key),
+		VersionID: aws.StringValue(file.versionID),
+		Size:      aws.Int64Value(file.Size),
+		Status:    status,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	line, marshalErr := json.Marshal(entry)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || r.err != nil {
+		return
+	}
+	if marshalErr != nil {
+		r.err = marshalErr
+		return
+	}
+
+	sep := ",\n"
+	if r.totals.Objects == 0 {
+		sep = "\n"
+	}
+	if _, r.err = r.w.WriteString(sep); r.err == nil {
+		_, r.err = r.w.Write(line)
+	}
+
+	r.totals.Objects++
+	switch status {
+	case statusDownloaded:
+		r.totals.Downloaded++
+		r.totals.Bytes += entry.Size
+	case statusSkipped:
+		r.totals.Skipped++
+	case statusError:
+		r.totals.Errors++
+	}
+}
+
+// close writes the totals and the run's error, if any, and closes the file.
+// Results of workers that finish later are dropped.
+func (r *reportWriter) close(runErr error) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	footer := struct {
+		Totals         reportTotals `json:"totals"`
+		ElapsedSeconds float64      `json:"elapsedSeconds"`
+		Error          string       `json:"error,omitempty"`
+	}{Totals: r.totals, ElapsedSeconds: time.Since(r.start).Seconds()}
+	if runErr != nil {
+		footer.Error = runErr.Error()
+	}
+	tail, err := json.Marshal(footer)
+	if r.err == nil {
+		r.err = err
+	}
+	if r.err == nil {
+		// Splice the footer's fields into the top-level object after the array
+		_, r.err = fmt.Fprintf(r.w, "\n],%s\n", tail[1:])
+	}
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	if closeErr := r.file.Close(); r.err == nil {
+		r.err = closeErr
+	}
+	if r.err != nil {
+		return fmt.Errorf("failed to write report '%s': %w", r.file.Name(), r.err)
+	}
+	return nil
+}
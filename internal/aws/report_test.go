@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportWritesObjectResultsAndTotals(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo!", "broken.txt": "x", "cold.txt": "ice"})
+	client.failures["broken.txt"] = -1
+	client.classes["cold.txt"] = s3.StorageClassGlacier
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	d := newTestDownloader(client, newMemorySink())
+	d.config.ReportPath = reportPath
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+	assert.Error(t, err)
+
+	data, readErr := os.ReadFile(reportPath)
+	assert.NoError(t, readErr)
+
+	var report struct {
+		Objects        []reportEntry `json:"objects"`
+		Totals         reportTotals  `json:"totals"`
+		ElapsedSeconds float64       `json:"elapsedSeconds"`
+		Error          string        `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &report))
+
+	sort.Slice(report.Objects, func(i, j int) bool { return report.Objects[i].Key < report.Objects[j].Key })
+	if assert.Len(t, report.Objects, 4) {
+		assert.Equal(t, reportEntry{Key: "a.txt", Size: 5, Status: statusDownloaded}, report.Objects[0])
+		assert.Equal(t, reportEntry{Key: "b.txt", Size: 6, Status: statusDownloaded}, report.Objects[1])
+		assert.Equal(t, "broken.txt", report.Objects[2].Key)
+		assert.Equal(t, statusError, report.Objects[2].Status)
+		assert.Contains(t, report.Objects[2].Error, "InternalError")
+		assert.Equal(t, reportEntry{Key: "cold.txt", Size: 3, Status: statusSkipped}, report.Objects[3])
+	}
+	assert.Equal(t, reportTotals{Objects: 4, Downloaded: 2, Skipped: 1, Errors: 1, Bytes: 11}, report.Totals)
+	assert.GreaterOrEqual(t, report.ElapsedSeconds, 0.0)
+	assert.Equal(t, err.Error(), report.Error)
+}
+
+func TestReportWithoutObjects(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	d := newTestDownloader(newFakeS3(nil), newMemorySink())
+	d.config.ReportPath = reportPath
+
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+
+	data, err := os.ReadFile(reportPath)
+	assert.NoError(t, err)
+	var report map[string]any
+	assert.NoError(t, json.Unmarshal(data, &report))
+	assert.Empty(t, report["objects"])
+	assert.NotContains(t, report, "error")
+}
@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// requesterPaysHeader is sent on operations whose input has no RequestPayer field
+const requesterPaysHeader = "x-amz-request-payer"
+
+// requestPayer returns the RequestPayer value for the downloader's requests
+func (d *Downloader) requestPayer() *string {
+	if d.config.RequesterPays {
+		return aws.String(s3.RequestPayerRequester)
+	}
+	return nil
+}
+
+// requestOptions returns options for operations that cannot carry the request
+// settings in their input, such as HeadBucket
+func (d *Downloader) requestOptions() []request.Option {
+	if d.config.RequesterPays {
+		return []request.Option{request.WithSetRequestHeaders(map[string]string{requesterPaysHeader: s3.RequestPayerRequester})}
+	}
+	return nil
+}
+
+// listObjectsInput builds the ListObjectsV2 request for a prefix with the downloader's request options
+func (d *Downloader) listObjectsInput(bucket, prefix string) *s3.ListObjectsV2Input {
+	return &s3.ListObjectsV2Input{
+		Bucket:       aws.String(bucket),
+		Prefix:       aws.String(prefix),
+		RequestPayer: d.requestPayer(),
+	}
+}
+
+// getObjectInput builds the GetObject request for a key with the downloader's request
+// options; a nil versionID fetches the current version
+func (d *Downloader) getObjectInput(bucket string, key, versionID *string) *s3.GetObjectInput {
+	input := &s3.GetObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          key,
+		VersionId:    versionID,
+		RequestPayer: d.requestPayer(),
+	}
+	if d.sseKey != nil {
+		input.SSECustomerAlgorithm = aws.String(d.sseKey.algorithm)
+		input.SSECustomerKey = aws.String(d.sseKey.key)
+		input.SSECustomerKeyMD5 = aws.String(d.sseKey.keyMD5)
+	}
+	return input
+}
+
+// headObjectInput builds the HeadObject request for a key with the downloader's request
+// options; a nil versionID describes the current version
+func (d *Downloader) headObjectInput(bucket string, key, versionID *string) *s3.HeadObjectInput {
+	input := &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          key,
+		VersionId:    versionID,
+		RequestPayer: d.requestPayer(),
+	}
+	if d.sseKey != nil {
+		input.SSECustomerAlgorithm = aws.String(d.sseKey.algorithm)
+		input.SSECustomerKey = aws.String(d.sseKey.key)
+		input.SSECustomerKeyMD5 = aws.String(d.sseKey.keyMD5)
+	}
+	return input
+}
+
+// getObjectTaggingInput builds the GetObjectTagging request for a key with the downloader's
+// request options; a nil versionID reads the tags of the current version
+func (d *Downloader) getObjectTaggingInput(bucket string, key, versionID *string) *s3.GetObjectTaggingInput {
+	return &s3.GetObjectTaggingInput{
+		Bucket:       aws.String(bucket),
+		Key:          key,
+		VersionId:    versionID,
+		RequestPayer: d.requestPayer(),
+	}
+}
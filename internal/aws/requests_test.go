@@ -0,0 +1,61 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequesterPays(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo"})
+	d := newTestDownloader(client, newMemorySink())
+	d.config.RequesterPays = true
+
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+	_, err := d.DownloadObjects(context.Background(), "bucket", []string{"a.txt"}, "keys", nil)
+	assert.NoError(t, err)
+
+	assert.NotEmpty(t, client.lists)
+	for _, input := range client.lists {
+		assert.Equal(t, "requester", aws.StringValue(input.RequestPayer))
+	}
+	assert.NotEmpty(t, client.gets)
+	for _, input := range client.gets {
+		assert.Equal(t, "requester", aws.StringValue(input.RequestPayer))
+	}
+	assert.NotEmpty(t, client.heads)
+	for _, input := range client.heads {
+		assert.Equal(t, "requester", aws.StringValue(input.RequestPayer))
+	}
+}
+
+func TestRequesterPaysDisabledByDefault(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha"})
+	d := newTestDownloader(client, newMemorySink())
+
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+	assert.Nil(t, client.lists[0].RequestPayer)
+	assert.Nil(t, client.gets[0].RequestPayer)
+}
+
+func TestValidateBucketExistsRequesterPays(t *testing.T) {
+	var payer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payer = r.Header.Get(requesterPaysHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.Endpoint = server.URL
+	cfg.RequesterPays = true
+	d, err := NewDownloaderWithConfig("us-east-1", "AKID", "SECRET", cfg)
+	assert.NoError(t, err)
+
+	assert.NoError(t, d.ValidateBucketExists(context.Background(), "bucket"))
+	assert.Equal(t, "requester", payer)
+}
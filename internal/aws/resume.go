@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// resumeFile downloads obj with a single sequential GetObject so that an interrupted
+// transfer leaves a contiguous partial file, which the next run continues with a
+// ranged request. A local file larger than the object is downloaded again from
+// scratch. It reports skipped when the local file is already complete.
+func (d *Downloader) resumeFile(ctx context.Context, bucket string, obj target, localPath string, timeout time.Duration) (bool, error) {
+	key := aws.StringValue(obj.Key)
+	size := aws.Int64Value(obj.Size)
+
+	offset, err := d.sink.Size(localPath)
+	switch {
+	case err != nil:
+		offset = 0 // No partial file yet
+	case offset == size:
+		return true, nil
+	case offset > size:
+		offset = 0 // Not a prefix of this object, start over
+	}
+
+	// Bytes already on disk are not downloaded again
+	if t := d.tracker.Load(); t != nil && offset > 0 {
+		t.TotalBytesExpected.Add(-offset)
+	}
+
+	var f WriteAtCloser
+	if offset == 0 {
+		f, err = d.sink.Create(localPath)
+	} else {
+		f, err = d.sink.Open(localPath)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to open file '%s': %w", key, err)
+	}
+	defer f.Close()
+
+	downloadCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	input := d.getObjectInput(bucket, obj.Key, obj.versionID)
+	if offset > 0 {
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-", offset))
+	}
+	out, err := d.s3.GetObjectWithContext(downloadCtx, input)
+	if err != nil {
+		return false, fmt.Errorf("failed to download '%s': %w", key, err)
+	}
+	defer out.Body.Close()
+
+	// The partial file is kept when the transfer breaks off so the next run can resume from it
+	if _, err := streamCopy(io.NewOffsetWriter(d.throttle(downloadCtx, d.countBytes(f, key)), offset), out.Body); err != nil {
+		return false, fmt.Errorf("failed to download '%s': %w", key, err)
+	}
+
+	written, err := d.sink.Size(localPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to check size of '%s': %w", key, err)
+	}
+	if written != size {
+		// Not a prefix of the object either, so the next run must not continue it
+		f.Close()
+		d.sink.Remove(localPath)
+		return false, fmt.Errorf("incomplete download of '%s': got %d bytes, expected %d", key, written, size)
+	}
+	return false, nil
+}
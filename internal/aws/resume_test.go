@@ -0,0 +1,92 @@
+package aws
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResumePartial(t *testing.T) {
+	const body = "0123456789abcdef"
+	localPath := filepath.Join("out", "big.bin")
+
+	testCases := []struct {
+		name          string
+		existing      *string
+		expectedRange *string
+		skipped       bool
+	}{
+		{"No local file", nil, nil, false},
+		{"Already complete", aws.String(body), nil, true},
+		{"Partially complete", aws.String(body[:6]), aws.String("bytes=6-"), false},
+		{"Larger than expected", aws.String(body + "garbage"), nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{"big.bin": body})
+			sink := newMemorySink()
+			if tc.existing != nil {
+				sink.files[localPath] = aws.NewWriteAtBuffer([]byte(*tc.existing))
+			}
+			d := newTestDownloader(client, sink)
+			d.config.ResumePartial = true
+
+			assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+
+			assert.Equal(t, body, sink.contents(localPath))
+			if tc.skipped {
+				assert.Empty(t, client.gets)
+				assert.Equal(t, int64(1), d.Progress().FilesSkipped)
+				return
+			}
+			assert.Len(t, client.gets, 1)
+			assert.Equal(t, tc.expectedRange, client.gets[0].Range)
+			assert.Equal(t, int64(0), d.Progress().FilesSkipped)
+		})
+	}
+}
+
+func TestResumePartialRemovesMismatchedFile(t *testing.T) {
+	client := newFakeS3(map[string]string{"big.bin": "0123456789"})
+	client.sizes["big.bin"] = 36 // Rewritten with other content after listing
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+	d.config.ResumePartial = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+	assert.ErrorContains(t, err, "incomplete download of 'big.bin': got 10 bytes, expected 36")
+	assert.False(t, sink.Exists(filepath.Join("out", "big.bin")), "the next run starts over instead of appending to it")
+}
+
+func TestResumePartialKeepsGzipEncodingOverHTTP(t *testing.T) {
+	stored := gzipped(t, "hello, world")
+	server := httptest.NewServer(newGzipS3Handler("a.txt", stored))
+	t.Cleanup(server.Close)
+
+	cfg := DefaultConfig()
+	cfg.Endpoint = server.URL
+	cfg.ResumePartial = true
+	d, err := NewDownloaderWithConfig("us-east-1", "AKID", "SECRET", cfg)
+	assert.NoError(t, err)
+
+	downloadPath := t.TempDir()
+	localPath := filepath.Join(downloadPath, "a.txt")
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil))
+	data, err := os.ReadFile(localPath)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, string(data), "saved as stored from offset 0")
+
+	// A resumed run appends the rest of the stored bytes
+	assert.NoError(t, os.WriteFile(localPath, []byte(stored[:10]), 0o600))
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil))
+	data, err = os.ReadFile(localPath)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, string(data))
+}
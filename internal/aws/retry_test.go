@@ -0,0 +1,103 @@
+package aws
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileRetries(t *testing.T) {
+	testCases := []struct {
+		name         string
+		failures     int
+		retries      int
+		expectedGets int
+		expectErr    bool
+	}{
+		{"Succeeds after two failures", 2, 3, 3, false},
+		{"Fails persistently", -1, 2, 3, true},
+		{"Retries disabled", 1, 0, 1, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{"flaky.txt": "flaky"})
+			client.failures["flaky.txt"] = tc.failures
+			sink := newMemorySink()
+			d := newTestDownloader(client, sink)
+			d.config.FileRetries = tc.retries
+			d.config.FileRetryBackoff = time.Millisecond
+
+			err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+			assert.Len(t, client.gets, tc.expectedGets)
+			if tc.expectErr {
+				assert.ErrorContains(t, err, "flaky.txt")
+				assert.Equal(t, int64(1), d.Progress().ErrorCount)
+				assert.False(t, sink.Exists(filepath.Join("out", "flaky.txt")))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, int64(0), d.Progress().ErrorCount)
+			assert.Equal(t, "flaky", sink.contents(filepath.Join("out", "flaky.txt")))
+		})
+	}
+}
+
+func TestFileRetriesCanceled(t *testing.T) {
+	client := newFakeS3(map[string]string{"flaky.txt": "flaky"})
+	client.failures["flaky.txt"] = -1
+	d := newTestDownloader(client, newMemorySink())
+	d.config.FileRetries = 5
+	d.config.FileRetryBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err := d.transferWithRetries(ctx, "bucket", d.newTransferManager(), newTarget(client.object("flaky.txt")), "flaky.txt")
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Len(t, client.gets, 1)
+}
+
+func TestFailFast(t *testing.T) {
+	objects := make(map[string]string)
+	for _, key := range []string{"a.txt", "b.txt", "c.txt", "d.txt", "e.txt", "f.txt", "g.txt", "h.txt"} {
+		objects[key] = key
+	}
+
+	testCases := []struct {
+		name     string
+		failFast bool
+	}{
+		{"Fail fast", true},
+		{"Keep going", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(objects)
+			client.failures["a.txt"] = -1
+			client.delay = 20 * time.Millisecond
+			d := newTestDownloader(client, newMemorySink())
+			d.config.MaxWorkers = 1
+			d.config.FailFast = tc.failFast
+
+			err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+			assert.ErrorContains(t, err, "a.txt")
+			assert.Equal(t, int64(1), d.Progress().ErrorCount)
+			if tc.failFast {
+				assert.ErrorContains(t, err, "fail-fast")
+				assert.Less(t, len(client.gets), len(objects))
+				return
+			}
+			assert.NotContains(t, err.Error(), "fail-fast")
+			assert.Len(t, client.gets, len(objects))
+		})
+	}
+}
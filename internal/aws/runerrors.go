@@ -0,0 +1,72 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// runErrors collects the errors of a run from the producer and the workers.
+// Adding never blocks, so no error is lost however many objects fail at once.
+type runErrors struct {
+	mu      sync.Mutex
+	errs    []error
+	logf    func(format string, args ...any)
+	onFirst func() // Called with the first error, to stop a fail-fast run
+}
+
+// newRunErrors creates an empty collector that logs every error with logf
+func newRunErrors(logf func(format string, args ...any), onFirst func()) *runErrors {
+	return &runErrors{logf: logf, onFirst: onFirst}
+}
+
+// add records err; nil errors are ignored
+func (r *runErrors) add(err error) {
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, context.Canceled) {
+		r.logf("Error: %v", err)
+	}
+	r.mu.Lock()
+	r.errs = append(r.errs, err)
+	first := len(r.errs) == 1
+	r.mu.Unlock()
+	if first && r.onFirst != nil {
+		r.onFirst()
+	}
+}
+
+// first returns the earliest error, or nil if there was none
+func (r *runErrors) first() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.errs) == 0 {
+		return nil
+	}
+	return r.errs[0]
+}
+
+// count returns the number of errors, leaving out cancellations caused by
+// stopping the run
+func (r *runErrors) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, err := range r.errs {
+		if !errors.Is(err, context.Canceled) {
+			n++
+		}
+	}
+	return n
+}
+
+// err returns the first error, noting the total when there were several
+func (r *runErrors) err() error {
+	first := r.first()
+	if n := r.count(); n > 1 {
+		return fmt.Errorf("%w (%d errors in total)", first, n)
+	}
+	return first
+}
@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunErrors(t *testing.T) {
+	var logged []string
+	stopped := 0
+	r := newRunErrors(func(format string, args ...any) { logged = append(logged, fmt.Sprintf(format, args...)) }, func() { stopped++ })
+	assert.NoError(t, r.err())
+
+	first := errors.New("first")
+	r.add(nil)
+	r.add(first)
+	r.add(context.Canceled)
+	r.add(errors.New("second"))
+
+	assert.Equal(t, 1, stopped)
+	assert.Equal(t, first, r.first())
+	assert.Equal(t, 2, r.count())
+	assert.EqualError(t, r.err(), "first (2 errors in total)")
+	assert.ErrorIs(t, r.err(), first)
+	assert.Equal(t, []string{"Error: first", "Error: second"}, logged)
+}
+
+func TestRunErrorsConcurrentAdds(t *testing.T) {
+	r := newRunErrors(func(string, ...any) {}, nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.add(errors.New("failed"))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 10000, r.count())
+}
+
+func TestEveryErrorIsRecorded(t *testing.T) {
+	const objects = 200
+	files := make(map[string]string, objects)
+	for i := 0; i < objects; i++ {
+		files[fmt.Sprintf("file%03d.txt", i)] = "content"
+	}
+	client := newFakeS3(files)
+	for key := range files {
+		client.failures[key] = -1
+	}
+	d := newTestDownloader(client, newMemorySink())
+	d.config.MaxWorkers = 2
+	logged := &recordingLog{}
+	d.SetLogSink(logged)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("download did not finish")
+	}
+
+	assert.ErrorContains(t, err, fmt.Sprintf("(%d errors in total)", objects))
+	assert.Equal(t, int64(objects), d.Progress().ErrorCount)
+	assert.Len(t, d.Failures(), objects)
+	assert.Len(t, logged.lines, objects)
+}
@@ -0,0 +1,94 @@
+package aws
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// s3HostPattern matches the hostnames of AWS S3 endpoints, capturing the bucket of
+// virtual-hosted URLs such as my-bucket.s3.eu-west-1.amazonaws.com and the endpoint
+// labels. Path-style hostnames like s3.amazonaws.com capture no bucket.
+var s3HostPattern = regexp.MustCompile(`^(?:(.+)\.)?(s3(?:[.-][a-z0-9-]+)*)\.amazonaws\.com(?:\.cn)?$`)
+
+// regionPattern matches AWS region names such as eu-west-1 or us-gov-east-1
+var regionPattern = regexp.MustCompile(`^[a-z]{2}(?:-[a-z]+)+-\d+$`)
+
+// consoleHost serves the S3 pages of the AWS console
+const consoleHost = "s3.console.aws.amazon.com"
+
+// S3Location is the bucket, key prefix and, when the URL names one, region of an S3 URI
+type S3Location struct {
+	Bucket string
+	Prefix string
+	Region string
+}
+
+// ParseS3URI extracts the bucket and key prefix from an s3://bucket/prefix URI, from
+// the virtual-hosted or path-style https URL of an object or folder on AWS, or from
+// the URL of a bucket page in the S3 console
+func ParseS3URI(uri string) (bucket, prefix string, err error) {
+	loc, err := ParseS3Location(uri)
+	return loc.Bucket, loc.Prefix, err
+}
+
+// ParseS3Location is ParseS3URI that also returns the region named by https URLs
+func ParseS3Location(uri string) (S3Location, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return S3Location{}, fmt.Errorf("invalid S3 URI '%s': %w", uri, err)
+	}
+
+	var loc S3Location
+	path := strings.TrimPrefix(u.Path, "/")
+	host := strings.ToLower(u.Hostname())
+	switch scheme := strings.ToLower(u.Scheme); {
+	case scheme == "s3":
+		loc.Bucket, loc.Prefix = u.Host, path
+	case (scheme == "http" || scheme == "https") && host == consoleHost:
+		// https://s3.console.aws.amazon.com/s3/buckets/my-bucket?region=eu-west-1&prefix=logs/
+		rest, ok := strings.CutPrefix(path, "s3/buckets/")
+		if !ok {
+			rest, ok = strings.CutPrefix(path, "s3/object/")
+		}
+		if !ok {
+			return S3Location{}, fmt.Errorf("'%s' is not the console page of a bucket", uri)
+		}
+		loc.Bucket, _, _ = strings.Cut(rest, "/")
+		loc.Prefix = u.Query().Get("prefix")
+		loc.Region = u.Query().Get("region")
+	case scheme == "http" || scheme == "https":
+		m := s3HostPattern.FindStringSubmatch(host)
+		if m == nil {
+			return S3Location{}, fmt.Errorf("'%s' is not an Amazon S3 URL", uri)
+		}
+		if m[1] != "" {
+			loc.Bucket, loc.Prefix = m[1], path
+		} else {
+			loc.Bucket, loc.Prefix, _ = strings.Cut(path, "/")
+		}
+		loc.Region = endpointRegion(m[2])
+	default:
+		return S3Location{}, fmt.Errorf("'%s' is not an S3 URI, expected s3://bucket/prefix", uri)
+	}
+
+	if loc.Bucket == "" {
+		return S3Location{}, fmt.Errorf("S3 URI '%s' does not name a bucket", uri)
+	}
+	return loc, nil
+}
+
+// endpointRegion finds the region in the labels of an S3 endpoint such as
+// "s3.eu-west-1", "s3-us-west-2" or "s3.dualstack.us-east-1"; global endpoints
+// have none
+func endpointRegion(endpoint string) string {
+	for _, label := range strings.Split(endpoint, ".") {
+		label = strings.TrimPrefix(label, "s3-website-")
+		label = strings.TrimPrefix(label, "s3-")
+		if regionPattern.MatchString(label) {
+			return label
+		}
+	}
+	return ""
+}
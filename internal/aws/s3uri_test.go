@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseS3URI(t *testing.T) {
+	testCases := []struct {
+		name       string
+		uri        string
+		wantBucket string
+		wantPrefix string
+		wantErr    bool
+	}{
+		{"S3 URI", "s3://my-bucket/some/prefix/", "my-bucket", "some/prefix/", false},
+		{"S3 URI of a bucket", "s3://my-bucket", "my-bucket", "", false},
+		{"S3 URI with surrounding space", "  s3://my-bucket/a.txt\n", "my-bucket", "a.txt", false},
+		{"Upper case scheme", "S3://my-bucket/x", "my-bucket", "x", false},
+		{"Virtual-hosted URL", "https://my-bucket.s3.amazonaws.com/key/file.txt", "my-bucket", "key/file.txt", false},
+		{"Regional virtual-hosted URL", "https://my-bucket.s3.eu-west-1.amazonaws.com/logs/", "my-bucket", "logs/", false},
+		{"Legacy regional URL", "https://my-bucket.s3-us-west-2.amazonaws.com/a", "my-bucket", "a", false},
+		{"Bucket with dots", "https://my.dotted.bucket.s3.amazonaws.com/a", "my.dotted.bucket", "a", false},
+		{"Path-style URL", "https://s3.amazonaws.com/my-bucket/key/file.txt", "my-bucket", "key/file.txt", false},
+		{"Regional path-style URL", "https://s3.eu-central-1.amazonaws.com/my-bucket", "my-bucket", "", false},
+		{"Escaped key", "https://my-bucket.s3.amazonaws.com/my%20folder/a%2Bb.txt", "my-bucket", "my folder/a+b.txt", false},
+		{"China region", "https://my-bucket.s3.cn-north-1.amazonaws.com.cn/x", "my-bucket", "x", false},
+		{"Missing bucket", "s3://", "", "", true},
+		{"Missing bucket in path-style URL", "https://s3.amazonaws.com/", "", "", true},
+		{"Single slash", "s3:/my-bucket/x", "", "", true},
+		{"No scheme", "my-bucket/prefix", "", "", true},
+		{"Other scheme", "ftp://my-bucket/prefix", "", "", true},
+		{"Other host", "https://example.com/my-bucket/x", "", "", true},
+		{"Unparseable", "s3://my bucket%zz", "", "", true},
+		{"Empty", "", "", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bucket, prefix, err := ParseS3URI(tc.uri)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantBucket, bucket)
+			assert.Equal(t, tc.wantPrefix, prefix)
+		})
+	}
+}
+
+func TestParseS3Location(t *testing.T) {
+	testCases := []struct {
+		name string
+		uri  string
+		want S3Location
+	}{
+		{"S3 URI has no region", "s3://my-bucket/logs/", S3Location{Bucket: "my-bucket", Prefix: "logs/"}},
+		{"Global endpoint", "https://my-bucket.s3.amazonaws.com/a", S3Location{Bucket: "my-bucket", Prefix: "a"}},
+		{"Virtual-hosted", "https://my-bucket.s3.eu-west-1.amazonaws.com/a", S3Location{Bucket: "my-bucket", Prefix: "a", Region: "eu-west-1"}},
+		{"Legacy dash endpoint", "https://my-bucket.s3-us-west-2.amazonaws.com/a", S3Location{Bucket: "my-bucket", Prefix: "a", Region: "us-west-2"}},
+		{"Path-style", "https://s3.ap-southeast-2.amazonaws.com/my-bucket/data/", S3Location{Bucket: "my-bucket", Prefix: "data/", Region: "ap-southeast-2"}},
+		{"Dual-stack", "https://my-bucket.s3.dualstack.us-east-1.amazonaws.com/a", S3Location{Bucket: "my-bucket", Prefix: "a", Region: "us-east-1"}},
+		{"GovCloud", "https://s3.us-gov-west-1.amazonaws.com/my-bucket", S3Location{Bucket: "my-bucket", Region: "us-gov-west-1"}},
+		{"China", "https://my-bucket.s3.cn-north-1.amazonaws.com.cn/a", S3Location{Bucket: "my-bucket", Prefix: "a", Region: "cn-north-1"}},
+		{"Console bucket page", "https://s3.console.aws.amazon.com/s3/buckets/my-bucket?region=eu-central-1&prefix=exports/2024/&showversions=false",
+			S3Location{Bucket: "my-bucket", Prefix: "exports/2024/", Region: "eu-central-1"}},
+		{"Console object page", "https://s3.console.aws.amazon.com/s3/object/my-bucket?region=us-east-1&prefix=a/b.txt",
+			S3Location{Bucket: "my-bucket", Prefix: "a/b.txt", Region: "us-east-1"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loc, err := ParseS3Location(tc.uri)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, loc)
+		})
+	}
+
+	_, err := ParseS3Location("https://s3.console.aws.amazon.com/s3/home?region=us-east-1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package aws
+
+import (
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"s3downloader/internal/metrics"
+	"s3downloader/pkg/fileutils"
+)
+
+// WriteAtCloser is a download destination that accepts out-of-order writes
+type WriteAtCloser interface {
+	io.WriterAt
+	io.Closer
+}
+
+// countingWriter adds the number of bytes written to a progress counter and, when
+// file is set, reports the object's own progress while it downloads
+type countingWriter struct {
+	WriteAtCloser
+	count   *atomic.Int64
+	file    *fileProgress
+	metrics *metrics.Metrics
+}
+
+// WriteAt writes p at off and counts the bytes written
+func (w countingWriter) WriteAt(p []byte, off int64) (int, error) {
+	n, err := w.WriteAtCloser.WriteAt(p, off)
+	w.count.Add(int64(n))
+	w.metrics.AddBytes(int64(n))
+	w.file.add(int64(n))
+	return n, err
+}
+
+// Sink abstracts where downloaded objects are written
+type Sink interface {
+	Create(path string) (WriteAtCloser, error)
+	Open(path string) (WriteAtCloser, error)
+	Size(path string) (int64, error)
+	Remove(path string) error
+	Exists(path string) bool
+	Mkdir(path string, mode os.FileMode) error
+	Chtimes(path string, mtime time.Time) error
+	Chmod(path string, mode os.FileMode) error
+	Rename(oldPath, newPath string) error
+}
+
+// LocalSink writes downloaded objects to the local filesystem
+type LocalSink struct{}
+
+// Create creates or truncates the file at path
+func (LocalSink) Create(path string) (WriteAtCloser, error) {
+	return os.Create(path)
+}
+
+// Open opens the existing file at path for writing without truncating it
+func (LocalSink) Open(path string) (WriteAtCloser, error) {
+	return os.OpenFile(path, os.O_WRONLY, 0)
+}
+
+// Size returns the size of the file at path
+func (LocalSink) Size(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Remove deletes the file at path
+func (LocalSink) Remove(path string) error {
+	return os.Remove(path)
+}
+
+// Exists checks if a regular file exists at path; directories do not count
+func (LocalSink) Exists(path string) bool {
+	return fileutils.IsFile(path)
+}
+
+// Mkdir creates the directory at path and any missing parents with mode
+func (LocalSink) Mkdir(path string, mode os.FileMode) error {
+	return fileutils.EnsureDirectoryExistsMode(path, mode)
+}
+
+// Chtimes sets the access and modification times of the file at path
+func (LocalSink) Chtimes(path string, mtime time.Time) error {
+	return os.Chtimes(path, mtime, mtime)
+}
+
+// Chmod sets the permissions of the file at path
+func (LocalSink) Chmod(path string, mode os.FileMode) error {
+	return os.Chmod(path, mode)
+}
+
+// Rename moves the file at oldPath to newPath, replacing any file there
+func (LocalSink) Rename(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
@@ -0,0 +1,145 @@
+package aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"s3downloader/internal/progress"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+// memorySink keeps downloaded files in memory
+type memorySink struct {
+	mu    sync.Mutex
+	files map[string]*aws.WriteAtBuffer
+	dirs  map[string]bool
+}
+
+func newMemorySink() *memorySink {
+	return &memorySink{files: make(map[string]*aws.WriteAtBuffer), dirs: make(map[string]bool)}
+}
+
+type memoryFile struct {
+	*aws.WriteAtBuffer
+}
+
+func (memoryFile) Close() error { return nil }
+
+func (m *memorySink) Create(path string) (WriteAtCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	buf := &aws.WriteAtBuffer{}
+	m.files[path] = buf
+	return memoryFile{buf}, nil
+}
+
+func (m *memorySink) Open(path string) (WriteAtCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	buf, ok := m.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return memoryFile{buf}, nil
+}
+
+func (m *memorySink) Size(path string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	buf, ok := m.files[path]
+	if !ok {
+		return 0, os.ErrNotExist
+	}
+	return int64(len(buf.Bytes())), nil
+}
+
+func (m *memorySink) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, path)
+	return nil
+}
+
+func (m *memorySink) Exists(path string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.files[path]
+	return ok
+}
+
+func (m *memorySink) Mkdir(path string, mode os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dirs[path] = true
+	return nil
+}
+
+func (m *memorySink) Chtimes(path string, mtime time.Time) error {
+	return nil
+}
+
+func (m *memorySink) Chmod(path string, mode os.FileMode) error {
+	return nil
+}
+
+func (m *memorySink) Rename(oldPath, newPath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	buf, ok := m.files[oldPath]
+	if !ok {
+		return os.ErrNotExist
+	}
+	delete(m.files, oldPath)
+	m.files[newPath] = buf
+	return nil
+}
+
+func (m *memorySink) contents(path string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if buf, ok := m.files[path]; ok {
+		return string(buf.Bytes())
+	}
+	return ""
+}
+
+// drain consumes progress updates until the channel is closed
+func drain(progressChan chan progress.Progress) chan progress.Progress {
+	last := make(chan progress.Progress, 1)
+	go func() {
+		var p progress.Progress
+		for p = range progressChan {
+		}
+		last <- p
+	}()
+	return last
+}
+
+func TestListAndDownloadObjectsWithMemorySink(t *testing.T) {
+	client := newFakeS3(map[string]string{
+		"data/a.txt":     "alpha",
+		"data/sub/b.txt": "bravo",
+		"other/c.txt":    "charlie",
+	})
+	sink := newMemorySink()
+	sink.files[filepath.Join("out", "data", "sub", "b.txt")] = aws.NewWriteAtBuffer([]byte("existing"))
+	d := newTestDownloader(client, sink)
+
+	progressChan := make(chan progress.Progress, 1)
+	last := drain(progressChan)
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "data/", "out", progressChan)
+	close(progressChan)
+	<-last
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alpha", sink.contents(filepath.Join("out", "data", "a.txt")))
+	assert.Equal(t, "existing", sink.contents(filepath.Join("out", "data", "sub", "b.txt")))
+	assert.False(t, sink.Exists(filepath.Join("out", "other", "c.txt")))
+	assert.True(t, sink.dirs[filepath.Join("out", "data", "sub")])
+}
@@ -0,0 +1,98 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"s3downloader/pkg/fileutils"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// InsufficientSpaceError aborts a run whose listed objects do not fit on the
+// download volume
+type InsufficientSpaceError struct {
+	Path      string // Download path
+	Needed    int64  // Bytes the objects listed so far add to the volume
+	Available int64  // Free bytes on the volume when the run started
+	Margin    int64  // Bytes that were to remain free
+}
+
+func (e *InsufficientSpaceError) Error() string {
+	return fmt.Sprintf("not enough free space for '%s': the listed objects need %.1f MB and %.1f MB must remain free, but only %.1f MB are available",
+		e.Path, megabytes(e.Needed), megabytes(e.Margin), megabytes(e.Available))
+}
+
+func megabytes(n int64) float64 {
+	return float64(n) / (1024 * 1024)
+}
+
+// checkFreeSpace fails when needed bytes plus the margin exceed the available space
+func checkFreeSpace(path string, needed, available, margin int64) error {
+	if needed+margin > available {
+		return &InsufficientSpaceError{Path: path, Needed: needed, Available: available, Margin: margin}
+	}
+	return nil
+}
+
+// spaceBudget adds up the disk space taken by the listed objects and compares it
+// to the free space measured when the run started. Producers such as
+// DownloadObjects enqueue from several goroutines, so it is safe for concurrent use.
+type spaceBudget struct {
+	sink      Sink
+	path      string
+	available int64
+	margin    int64
+	mu        sync.Mutex
+	needed    int64
+}
+
+// newSpaceBudget measures the free space of the volume holding downloadPath. It
+// returns nil, which disables the check, when CheckFreeSpace is off, the sink is
+// not the local filesystem or the free space cannot be measured.
+func (d *Downloader) newSpaceBudget(downloadPath string) *spaceBudget {
+	if !d.config.CheckFreeSpace {
+		return nil
+	}
+	if _, ok := d.sink.(LocalSink); !ok {
+		return nil
+	}
+	available, err := fileutils.AvailableSpace(existingParent(downloadPath))
+	if err != nil {
+		return nil
+	}
+	return &spaceBudget{sink: d.sink, path: downloadPath, available: available, margin: d.config.FreeSpaceMargin}
+}
+
+// existingParent returns path or its closest existing ancestor, since the
+// download path is only created once files arrive
+func existingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// reserve accounts for obj being written to localPath, net of the file it
+// replaces, and fails once the objects listed so far no longer fit
+func (b *spaceBudget) reserve(obj target, localPath string) error {
+	if b == nil {
+		return nil
+	}
+	size := aws.Int64Value(obj.Size)
+	if existing, err := b.sink.Size(localPath); err == nil {
+		size -= existing
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.needed += size
+	return checkFreeSpace(b.path, b.needed, b.available, b.margin)
+}
@@ -0,0 +1,105 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckFreeSpace(t *testing.T) {
+	testCases := []struct {
+		name      string
+		needed    int64
+		available int64
+		margin    int64
+		fits      bool
+	}{
+		{"Fits with margin", 50, 100, 10, true},
+		{"Exactly fills up to the margin", 90, 100, 10, true},
+		{"Eats into the margin", 91, 100, 10, false},
+		{"Larger than the volume", 200, 100, 0, false},
+		{"No margin", 100, 100, 0, true},
+		{"Margin alone does not fit", 0, 5, 10, false},
+		{"Replacing larger files frees space", -20, 0, 10, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkFreeSpace("out", tc.needed, tc.available, tc.margin)
+			if tc.fits {
+				assert.NoError(t, err)
+				return
+			}
+			var spaceErr *InsufficientSpaceError
+			if assert.ErrorAs(t, err, &spaceErr) {
+				assert.Equal(t, InsufficientSpaceError{Path: "out", Needed: tc.needed, Available: tc.available, Margin: tc.margin}, *spaceErr)
+			}
+		})
+	}
+}
+
+func TestSpaceBudgetCountsReplacedFiles(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "old.bin"), make([]byte, 40), 0o644))
+	b := &spaceBudget{sink: LocalSink{}, path: dir, available: 100, margin: 10}
+
+	object := func(size int64) target {
+		return target{Object: &s3.Object{Key: aws.String("key"), Size: aws.Int64(size)}}
+	}
+	assert.NoError(t, b.reserve(object(60), filepath.Join(dir, "new.bin")))
+	// Overwriting the existing file only needs the difference
+	assert.NoError(t, b.reserve(object(70), filepath.Join(dir, "old.bin")))
+	assert.Equal(t, int64(90), b.needed)
+	assert.Error(t, b.reserve(object(1), filepath.Join(dir, "other.bin")))
+}
+
+func TestListAndDownloadObjectsStopsWhenDiskIsFull(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo"})
+	dir := t.TempDir()
+	d := newTestDownloader(client, LocalSink{})
+	d.config.FreeSpaceMargin = math.MaxInt64 / 2 // More than any volume has
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "", filepath.Join(dir, "missing", "out"), nil)
+	var spaceErr *InsufficientSpaceError
+	assert.True(t, errors.As(err, &spaceErr))
+	assert.Empty(t, client.gets)
+	assert.NoDirExists(t, filepath.Join(dir, "missing"))
+
+	// Turning the check off downloads anyway
+	d.config.CheckFreeSpace = false
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", filepath.Join(dir, "out"), nil))
+	assert.Len(t, client.gets, 2)
+}
+
+// DownloadObjects enqueues from one goroutine per worker; run with -race
+func TestDownloadObjectsChecksFreeSpaceConcurrently(t *testing.T) {
+	objects := make(map[string]string)
+	var keys []string
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("file%02d.txt", i)
+		objects[key] = key
+		keys = append(keys, key)
+	}
+	client := newFakeS3(objects)
+	d := newTestDownloader(client, LocalSink{})
+	d.config.MaxWorkers = 8
+	assert.True(t, d.config.CheckFreeSpace)
+
+	missing, err := d.DownloadObjects(context.Background(), "bucket", keys, t.TempDir(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.Equal(t, int64(len(keys)), d.Progress().FilesDownloaded)
+
+	d.config.FreeSpaceMargin = math.MaxInt64 / 2
+	_, err = d.DownloadObjects(context.Background(), "bucket", keys, t.TempDir(), nil)
+	var spaceErr *InsufficientSpaceError
+	assert.True(t, errors.As(err, &spaceErr))
+}
@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// sseKeyLength is the key size required by the AES256 SSE-C algorithm
+const sseKeyLength = 32
+
+// sseCustomerKey holds a validated SSE-C key ready to be sent with requests
+type sseCustomerKey struct {
+	algorithm string
+	key       string // Raw key bytes; the SDK base64-encodes them on the wire
+	keyMD5    string // Base64-encoded MD5 digest of the raw key
+}
+
+// resolveSSECustomerKey validates the SSE-C settings of cfg. The key may be given
+// base64-encoded or as raw bytes; a nil result means SSE-C is not used.
+func resolveSSECustomerKey(cfg Config) (*sseCustomerKey, error) {
+	if cfg.SSECustomerKey == "" {
+		return nil, nil
+	}
+
+	algorithm := cfg.SSECustomerAlgorithm
+	if algorithm == "" {
+		algorithm = s3.ServerSideEncryptionAes256
+	}
+	if algorithm != s3.ServerSideEncryptionAes256 {
+		return nil, fmt.Errorf("unsupported SSE-C algorithm '%s'", algorithm)
+	}
+
+	key := cfg.SSECustomerKey
+	if decoded, err := base64.StdEncoding.DecodeString(key); err == nil && len(decoded) == sseKeyLength {
+		key = string(decoded)
+	}
+	if len(key) != sseKeyLength {
+		return nil, fmt.Errorf("invalid SSE-C key: %s requires a %d-byte key", algorithm, sseKeyLength)
+	}
+
+	sum := md5.Sum([]byte(key))
+	return &sseCustomerKey{
+		algorithm: algorithm,
+		key:       key,
+		keyMD5:    base64.StdEncoding.EncodeToString(sum[:]),
+	}, nil
+}
@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSSECustomerKey(t *testing.T) {
+	raw := strings.Repeat("k", sseKeyLength)
+	encoded := base64.StdEncoding.EncodeToString([]byte(raw))
+
+	testCases := []struct {
+		name      string
+		cfg       Config
+		wantKey   string
+		wantErr   string
+		wantNoKey bool
+	}{
+		{"No key", Config{}, "", "", true},
+		{"Raw key", Config{SSECustomerKey: raw}, raw, "", false},
+		{"Base64 key", Config{SSECustomerKey: encoded}, raw, "", false},
+		{"Short key", Config{SSECustomerKey: "too-short"}, "", "requires a 32-byte key", false},
+		{"Unsupported algorithm", Config{SSECustomerKey: raw, SSECustomerAlgorithm: "aws:kms"}, "", "unsupported SSE-C algorithm", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := resolveSSECustomerKey(tc.cfg)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			if tc.wantNoKey {
+				assert.Nil(t, key)
+				return
+			}
+			sum := md5.Sum([]byte(tc.wantKey))
+			assert.Equal(t, "AES256", key.algorithm)
+			assert.Equal(t, tc.wantKey, key.key)
+			assert.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), key.keyMD5)
+		})
+	}
+}
+
+func TestNewDownloaderRejectsMalformedSSECustomerKey(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SSECustomerKey = "not-a-valid-key"
+	_, err := NewDownloaderWithConfig("eu-west-1", "AKID", "SECRET", cfg)
+	assert.ErrorContains(t, err, "invalid SSE-C key")
+}
+
+func TestGetObjectInputWithSSECustomerKey(t *testing.T) {
+	client := newFakeS3(map[string]string{"secret.txt": "classified"})
+	d := newTestDownloader(client, newMemorySink())
+	key, err := resolveSSECustomerKey(Config{SSECustomerKey: strings.Repeat("k", sseKeyLength)})
+	assert.NoError(t, err)
+	d.sseKey = key
+
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+
+	assert.NotEmpty(t, client.gets)
+	for _, input := range client.gets {
+		assert.Equal(t, "AES256", aws.StringValue(input.SSECustomerAlgorithm))
+		assert.Equal(t, key.key, aws.StringValue(input.SSECustomerKey))
+		assert.Equal(t, key.keyMD5, aws.StringValue(input.SSECustomerKeyMD5))
+	}
+}
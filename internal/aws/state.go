@@ -0,0 +1,140 @@
+package aws
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// stateEntry is a line of the state file, recording an object that was completed
+type stateEntry struct {
+	Bucket    string `json:"bucket,omitempty"` // Set in runs over several buckets
+	Key       string `json:"key"`
+	VersionID string `json:"versionId,omitempty"`
+	ETag      string `json:"etag,omitempty"`
+}
+
+// stateLog is an append-only log of completed objects that lets a later run with
+// the same state file skip them. Lines that cannot be parsed, such as one cut
+// short by a crash, are ignored when the log is loaded.
+type stateLog struct {
+	mu   sync.Mutex
+	file *os.File
+	done map[stateEntry]struct{}
+	err  error // First write error, reported by close
+}
+
+// openStateLog loads the completed objects recorded at path and opens it for appending
+func openStateLog(path string) (*stateLog, error) {
+	l := &stateLog{done: make(map[stateEntry]struct{})}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file '%s': %w", path, err)
+	}
+	complete, err := l.load(f)
+	if err == nil && !complete {
+		// Start on a fresh line after a partial one
+		_, err = f.WriteString("\n")
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read state file '%s': %w", path, err)
+	}
+	l.file = f
+	return l, nil
+}
+
+// load reads the entries of r, reporting whether the last line was complete
+func (l *stateLog) load(r io.Reader) (bool, error) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" && strings.HasSuffix(line, "\n") {
+			var entry stateEntry
+			if json.Unmarshal([]byte(line), &entry) == nil && entry.Key != "" {
+				l.done[entry] = struct{}{}
+			}
+		}
+		if err == io.EOF {
+			return line == "", nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// stateEntryFor identifies obj in the log by key, version and content
+func stateEntryFor(obj target) stateEntry {
+	return stateEntry{
+		Bucket:    obj.bucket,
+		Key:       aws.StringValue(obj.Key),
+		VersionID: aws.StringValue(obj.versionID),
+		ETag:      strings.Trim(aws.StringValue(obj.ETag), `"`),
+	}
+}
+
+// completed reports whether obj, unchanged since, was completed by an earlier run;
+// it is false on a nil log
+func (l *stateLog) completed(obj target) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.done[stateEntryFor(obj)]
+	return ok
+}
+
+// add records obj as completed; it is a no-op on a nil log. Each line is written
+// straight to the file so that it survives a crash of the app.
+func (l *stateLog) add(obj target) {
+	if l == nil {
+		return
+	}
+	entry := stateEntryFor(obj)
+	line, err := json.Marshal(entry)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil || l.file == nil {
+		return
+	}
+	if err == nil {
+		_, err = l.file.Write(append(line, '\n'))
+	}
+	if err != nil {
+		l.err = err
+		return
+	}
+	l.done[entry] = struct{}{}
+}
+
+// close closes the state file
+func (l *stateLog) close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	if l.err != nil {
+		err = l.err
+	}
+	name := l.file.Name()
+	l.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to write state file '%s': %w", name, err)
+	}
+	return nil
+}
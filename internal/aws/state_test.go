@@ -0,0 +1,95 @@
+package aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateFileRecordsCompletedObjects(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "dir/b.txt": "bravo"})
+	client.etags["a.txt"] = "etag-a"
+	stateFile := filepath.Join(t.TempDir(), "state.log")
+	d := newTestDownloader(client, newMemorySink())
+	d.config.StateFile = stateFile
+
+	assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+
+	data, err := os.ReadFile(stateFile)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	assert.ElementsMatch(t, []string{
+		`{"key":"a.txt","etag":"etag-a"}`,
+		`{"key":"dir/b.txt","etag":"` + strings.Trim(*client.object("dir/b.txt").ETag, `"`) + `"}`,
+	}, lines)
+}
+
+func TestStateFileSkipsCompletedObjectsOnNextRun(t *testing.T) {
+	client := newFakeS3(map[string]string{"a.txt": "alpha", "b.txt": "bravo", "c.txt": "charlie"})
+	stateFile := filepath.Join(t.TempDir(), "state.log")
+
+	// The first run fails on c.txt
+	client.failures["c.txt"] = 1
+	first := newTestDownloader(client, newMemorySink())
+	first.config.StateFile = stateFile
+	assert.Error(t, first.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+
+	// The second run, into an empty sink, only fetches what did not complete
+	client.gets = nil
+	sink := newMemorySink()
+	second := newTestDownloader(client, sink)
+	second.config.StateFile = stateFile
+	assert.NoError(t, second.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+
+	if assert.Len(t, client.gets, 1) {
+		assert.Equal(t, "c.txt", *client.gets[0].Key)
+	}
+	p := second.Progress()
+	assert.Equal(t, int64(3), p.FilesFound)
+	assert.Equal(t, int64(2), p.FilesSkipped)
+	assert.Equal(t, int64(3), p.FilesDownloaded)
+
+	// A changed object is downloaded again
+	client.gets = nil
+	client.objects["a.txt"] = []byte("alpha v2")
+	third := newTestDownloader(client, sink)
+	third.config.StateFile = stateFile
+	assert.NoError(t, third.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil))
+	if assert.Len(t, client.gets, 1) {
+		assert.Equal(t, "a.txt", *client.gets[0].Key)
+	}
+}
+
+func TestStateFileIgnoresCorruptLines(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.log")
+	content := `{"key":"a.txt","etag":"e1"}` + "\n" +
+		"not json\n" +
+		`{"etag":"no key"}` + "\n" +
+		"\n" +
+		`{"key":"b.txt","etag":"e2"}` + "\n" +
+		`{"key":"c.txt","et` // Cut short by a crash
+	assert.NoError(t, os.WriteFile(stateFile, []byte(content), 0o600))
+
+	l, err := openStateLog(stateFile)
+	assert.NoError(t, err)
+	assert.Equal(t, map[stateEntry]struct{}{
+		{Key: "a.txt", ETag: "e1"}: {},
+		{Key: "b.txt", ETag: "e2"}: {},
+	}, l.done)
+
+	// New entries start on their own line after the partial one
+	client := newFakeS3(map[string]string{"d.txt": "delta"})
+	client.etags["d.txt"] = "e4"
+	l.add(newTarget(client.object("d.txt")))
+	assert.NoError(t, l.close())
+
+	reloaded, err := openStateLog(stateFile)
+	assert.NoError(t, err)
+	assert.Contains(t, reloaded.done, stateEntry{Key: "d.txt", ETag: "e4"})
+	assert.Len(t, reloaded.done, 3)
+	assert.NoError(t, reloaded.close())
+}
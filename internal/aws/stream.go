@@ -0,0 +1,40 @@
+package aws
+
+import (
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// Objects are streamed to their local file and never held in memory as a whole.
+// Every download path moves data through a buffer of writeBufferSize: the
+// multipart transfer manager gets one per part in flight, and the sequential
+// GetObject paths (resume, gzip) one per object. At most MaxWorkers × Concurrency
+// buffers are in use at once, about 64MB with the default settings, whatever the size
+// of the objects. The throttling and counting writers pass each write straight
+// through, so they keep no data of their own, and counting a write allocates
+// nothing: the progress of an object lives in one fileProgress for its whole download.
+
+// writeBufferSize is the most data handed to the sink in one write
+const writeBufferSize = 64 * 1024
+
+// partBuffers gives each part of a multipart download a pooled write buffer
+var partBuffers = s3manager.NewPooledBufferedWriterReadFromProvider(writeBufferSize)
+
+// copyBuffers holds the buffers of the sequential download paths
+var copyBuffers = sync.Pool{
+	New: func() any {
+		buf := make([]byte, writeBufferSize)
+		return &buf
+	},
+}
+
+// streamCopy copies src to dst through a pooled buffer of writeBufferSize. Unlike
+// io.Copy it never lets src write itself to dst in a single call, so writes stay
+// bounded whatever the reader.
+func streamCopy(dst io.Writer, src io.Reader) (int64, error) {
+	buf := copyBuffers.Get().(*[]byte)
+	defer copyBuffers.Put(buf)
+	return io.CopyBuffer(dst, struct{ io.Reader }{src}, *buf)
+}
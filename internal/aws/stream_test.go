@@ -0,0 +1,136 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// probeSink records the size of every write instead of keeping the data, so
+// that a test can download objects far larger than it wants to hold
+type probeSink struct {
+	*memorySink
+	mu       sync.Mutex
+	sizes    map[string]int64
+	written  int64
+	maxWrite int
+}
+
+func newProbeSink() *probeSink {
+	return &probeSink{memorySink: newMemorySink(), sizes: make(map[string]int64)}
+}
+
+type probeFile struct {
+	sink *probeSink
+	path string
+}
+
+func (f probeFile) WriteAt(p []byte, off int64) (int, error) {
+	f.sink.mu.Lock()
+	defer f.sink.mu.Unlock()
+	f.sink.written += int64(len(p))
+	f.sink.maxWrite = max(f.sink.maxWrite, len(p))
+	f.sink.sizes[f.path] = max(f.sink.sizes[f.path], off+int64(len(p)))
+	return len(p), nil
+}
+
+func (probeFile) Close() error { return nil }
+
+func (s *probeSink) Create(path string) (WriteAtCloser, error) {
+	s.memorySink.Create(path)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sizes[path] = 0
+	return probeFile{sink: s, path: path}, nil
+}
+
+func (s *probeSink) Open(path string) (WriteAtCloser, error) {
+	if !s.Exists(path) {
+		return nil, os.ErrNotExist
+	}
+	return probeFile{sink: s, path: path}, nil
+}
+
+func (s *probeSink) Size(path string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	size, ok := s.sizes[path]
+	if !ok {
+		return 0, os.ErrNotExist
+	}
+	return size, nil
+}
+
+func (s *probeSink) Rename(oldPath, newPath string) error {
+	if err := s.memorySink.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sizes[newPath] = s.sizes[oldPath]
+	delete(s.sizes, oldPath)
+	return nil
+}
+
+func TestLargeObjectsStreamInBoundedWrites(t *testing.T) {
+	const size = 48 * 1024 * 1024
+	body := strings.Repeat("0123456789abcdef", size/16)
+
+	testCases := []struct {
+		name     string
+		object   string
+		encoding string
+		modify   func(*Downloader)
+	}{
+		{"Multipart", body, "", func(*Downloader) {}},
+		{"Throttled", body, "", func(d *Downloader) { d.limiter = newRateLimiter(1 << 40) }},
+		{"Resume", body, "", func(d *Downloader) { d.config.ResumePartial = true }},
+		{"Gzip", gzipped(t, body), "gzip", func(d *Downloader) { d.config.DecompressGzip = true }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{"large.bin": tc.object})
+			if tc.encoding != "" {
+				client.encodings["large.bin"] = tc.encoding
+			}
+			sink := newProbeSink()
+			d := newTestDownloader(client, sink)
+			d.config.CheckFreeSpace = false
+			tc.modify(d)
+
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+			runtime.ReadMemStats(&after)
+
+			assert.NoError(t, err)
+			size, err := sink.Size("out/large.bin")
+			assert.NoError(t, err)
+			assert.Equal(t, int64(len(body)), size)
+			assert.Equal(t, int64(len(body)), sink.written)
+			assert.LessOrEqual(t, sink.maxWrite, writeBufferSize)
+			// Far less than the object is allocated while it downloads
+			assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(len(body)/8))
+		})
+	}
+}
+
+func TestStreamCopyBoundsWrites(t *testing.T) {
+	sink := newProbeSink()
+	f, _ := sink.Create("file")
+	data := strings.Repeat("x", 5*writeBufferSize+1)
+
+	n, err := streamCopy(io.NewOffsetWriter(f, 0), strings.NewReader(data))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(data)), n)
+	assert.Equal(t, writeBufferSize, sink.maxWrite)
+}
@@ -0,0 +1,38 @@
+package aws
+
+import (
+	"context"
+	"path"
+	"strings"
+)
+
+// stripPrefix wraps produce so that objects are saved relative to prefix instead of
+// under their full key
+func stripPrefix(produce objectProducer, prefix string) objectProducer {
+	return func(ctx context.Context, enqueue func(target) bool) error {
+		return produce(ctx, func(obj target) bool {
+			obj.localKey = strippedKey(obj.localKey, prefix)
+			return enqueue(obj)
+		})
+	}
+}
+
+// strippedKey removes prefix from key. A prefix that ends in the middle of a name
+// is only stripped up to its last complete folder, so "exports/20" turns
+// "exports/2024/a.csv" into "2024/a.csv". Keys outside the prefix are kept whole,
+// and a key that is the prefix itself keeps its base name so the result is
+// never empty or absolute.
+func strippedKey(key, prefix string) string {
+	rel, ok := strings.CutPrefix(key, prefix)
+	if !ok {
+		return key
+	}
+	if !strings.HasSuffix(prefix, "/") && !strings.HasPrefix(rel, "/") {
+		rel = strings.TrimPrefix(key, prefix[:strings.LastIndex(prefix, "/")+1])
+	}
+	rel = strings.TrimLeft(rel, "/")
+	if rel == "" {
+		return path.Base(key)
+	}
+	return rel
+}
@@ -0,0 +1,51 @@
+package aws
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrippedKey(t *testing.T) {
+	testCases := []struct {
+		name     string
+		key      string
+		prefix   string
+		expected string
+	}{
+		{"Trailing slash", "exports/2024/a.csv", "exports/2024/", "a.csv"},
+		{"Trailing slash nested", "exports/2024/01/a.csv", "exports/2024/", "01/a.csv"},
+		{"Without trailing slash", "exports/2024/a.csv", "exports/2024", "a.csv"},
+		{"Partial folder name", "exports/2024/a.csv", "exports/20", "2024/a.csv"},
+		{"Partial top-level name", "exports/a.csv", "exp", "exports/a.csv"},
+		{"Key outside prefix", "other/a.csv", "exports/", "other/a.csv"},
+		{"Key equals prefix", "exports/a.csv", "exports/a.csv", "a.csv"},
+		{"Folder marker", "exports/2024/", "exports/2024/", "2024"},
+		{"Empty prefix", "exports/a.csv", "", "exports/a.csv"},
+		{"Leading slashes after prefix", "exports//a.csv", "exports/", "a.csv"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, strippedKey(tc.key, tc.prefix))
+		})
+	}
+}
+
+func TestListAndDownloadObjectsStripPrefix(t *testing.T) {
+	client := newFakeS3(map[string]string{
+		"exports/2024/a.csv":    "a",
+		"exports/2024/01/b.csv": "b",
+	})
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+	d.config.StripPrefix = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "exports/2024/", "out", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a", sink.contents(filepath.Join("out", "a.csv")))
+	assert.Equal(t, "b", sink.contents(filepath.Join("out", "01", "b.csv")))
+}
@@ -0,0 +1,27 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// matchesTags reports whether obj carries every tag of TagFilters with the same value
+func (d *Downloader) matchesTags(ctx context.Context, bucket string, obj target) (bool, error) {
+	out, err := d.s3.GetObjectTaggingWithContext(ctx, d.getObjectTaggingInput(bucket, obj.Key, obj.versionID))
+	if err != nil {
+		return false, fmt.Errorf("failed to get tags of '%s': %w", aws.StringValue(obj.Key), err)
+	}
+
+	tags := make(map[string]string, len(out.TagSet))
+	for _, tag := range out.TagSet {
+		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	for key, want := range d.config.TagFilters {
+		if value, ok := tags[key]; !ok || value != want {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,53 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagFilters(t *testing.T) {
+	testCases := []struct {
+		name       string
+		filters    map[string]string
+		wantFiles  []string
+		wantLookup bool
+	}{
+		{"No filters", nil, []string{"prod.csv", "staging.csv", "untagged.csv"}, false},
+		{"Matching tag", map[string]string{"environment": "prod"}, []string{"prod.csv"}, true},
+		{"All tags must match", map[string]string{"environment": "prod", "team": "data"}, []string{"prod.csv"}, true},
+		{"Non-matching tag set", map[string]string{"environment": "prod", "team": "web"}, nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{"prod.csv": "p", "staging.csv": "s", "untagged.csv": "u"})
+			client.tags["prod.csv"] = map[string]string{"environment": "prod", "team": "data"}
+			client.tags["staging.csv"] = map[string]string{"environment": "staging", "team": "data"}
+			sink := newMemorySink()
+			d := newTestDownloader(client, sink)
+			d.config.TagFilters = tc.filters
+
+			err := d.ListAndDownloadObjects(context.Background(), "bucket", "", "out", nil)
+
+			assert.NoError(t, err)
+			var got []string
+			for _, key := range []string{"prod.csv", "staging.csv", "untagged.csv"} {
+				if sink.Exists("out/" + key) {
+					got = append(got, key)
+				}
+			}
+			assert.Equal(t, tc.wantFiles, got)
+
+			p := d.Progress()
+			assert.Equal(t, int64(len(tc.wantFiles)), p.FilesDownloaded)
+			assert.Equal(t, int64(3-len(tc.wantFiles)), p.TagFiltered)
+			if tc.wantLookup {
+				assert.Len(t, client.taggings, 3)
+			} else {
+				assert.Empty(t, client.taggings)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package aws
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// newRateLimiter returns a limiter allowing bytesPerSec bytes per second with a
+// one second burst, or nil when bytesPerSec is zero (unlimited)
+func newRateLimiter(bytesPerSec int64) *rate.Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSec), int(bytesPerSec))
+}
+
+// throttledWriter delays writes so that all writers sharing the limiter stay under its rate
+type throttledWriter struct {
+	WriteAtCloser
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+// throttle wraps w with the downloader's rate limiter, if one is configured
+func (d *Downloader) throttle(ctx context.Context, w WriteAtCloser) WriteAtCloser {
+	if d.limiter == nil {
+		return w
+	}
+	return &throttledWriter{WriteAtCloser: w, ctx: ctx, limiter: d.limiter}
+}
+
+// WriteAt waits for enough tokens before writing each burst-sized chunk of p
+func (w *throttledWriter) WriteAt(p []byte, off int64) (int, error) {
+	written := 0
+	for written < len(p) {
+		chunk := min(len(p)-written, w.limiter.Burst())
+		if err := w.limiter.WaitN(w.ctx, chunk); err != nil {
+			return written, err
+		}
+		n, err := w.WriteAtCloser.WriteAt(p[written:written+chunk], off+int64(written))
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
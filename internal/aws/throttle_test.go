@@ -0,0 +1,36 @@
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottledWriter(t *testing.T) {
+	const rate = 1000 // bytes per second, with a burst of one second
+	d := &Downloader{limiter: newRateLimiter(rate)}
+	buf := &aws.WriteAtBuffer{}
+	w := d.throttle(context.Background(), memoryFile{buf})
+
+	data := make([]byte, 2500)
+	start := time.Now()
+	n, err := w.WriteAt(data, 0)
+	elapsed := time.Since(start)
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), n)
+	assert.Len(t, buf.Bytes(), len(data))
+	// The first second's worth is covered by the burst, the remaining 1500 bytes take 1.5s
+	assert.GreaterOrEqual(t, elapsed, 1400*time.Millisecond)
+}
+
+func TestThrottleUnlimited(t *testing.T) {
+	assert.Nil(t, newRateLimiter(0))
+
+	d := &Downloader{}
+	f := memoryFile{&aws.WriteAtBuffer{}}
+	assert.Equal(t, WriteAtCloser(f), d.throttle(context.Background(), f))
+}
@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"s3downloader/internal/redact"
+)
+
+// newHTTPClient builds the client every request of the downloader goes through. It uses
+// cfg.ProxyURL if set and otherwise the HTTPS_PROXY, HTTP_PROXY and NO_PROXY variables.
+func newHTTPClient(cfg Config) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	// Objects must arrive as stored: Go would otherwise ask for gzip, decompress
+	// gzip-encoded objects on the fly and hide that from decompressObject and resumeFile
+	transport.DisableCompression = true
+	if cfg.ProxyURL != "" {
+		proxy, err := parseProxyURL(cfg.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		if password, ok := proxy.User.Password(); ok {
+			redact.Register(password)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// parseProxyURL checks that a proxy is an absolute http, https or socks5 URL with a host
+func parseProxyURL(proxy string) (*url.URL, error) {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL '%s': must be an http, https or socks5 URL with a host", u.Redacted())
+	}
+	return u, nil
+}
+
+// configureTLS trusts the certificates in cfg.CACertFile on top of the client's root CAs,
+// those of AWS_CA_BUNDLE or the system ones, and disables verification with cfg.InsecureSkipVerify.
+// The session replaces the root CAs when it loads AWS_CA_BUNDLE, so this must run after it.
+func configureTLS(client *http.Client, cfg Config) error {
+	if cfg.CACertFile == "" && !cfg.InsecureSkipVerify {
+		return nil
+	}
+	transport := client.Transport.(*http.Transport)
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	// Only on explicit request; every run warns about it
+	tlsConfig.InsecureSkipVerify = cfg.InsecureSkipVerify
+	if cfg.CACertFile != "" {
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA certificate file: %w", err)
+		}
+		pool := tlsConfig.RootCAs
+		if pool == nil {
+			if pool, err = x509.SystemCertPool(); err != nil {
+				pool = x509.NewCertPool()
+			}
+		} else {
+			pool = pool.Clone()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no PEM certificates found in CA certificate file '%s'", cfg.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+	return nil
+}
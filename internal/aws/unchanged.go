@@ -0,0 +1,30 @@
+package aws
+
+import (
+	"os"
+	"strings"
+
+	"s3downloader/pkg/fileutils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// localFileUnchanged reports whether the local file at path already holds the content
+// of obj. Single-part ETags are the MD5 of the content and are compared directly;
+// multipart ETags (containing a dash) are not, so those fall back to comparing the
+// size and requiring the local file to be at least as new as the object.
+func localFileUnchanged(path string, obj *s3.Object) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() != aws.Int64Value(obj.Size) {
+		return false
+	}
+
+	etag := strings.Trim(aws.StringValue(obj.ETag), `"`)
+	if etag == "" || strings.Contains(etag, "-") {
+		return obj.LastModified != nil && !info.ModTime().Before(*obj.LastModified)
+	}
+
+	sum, err := fileutils.ComputeFileChecksum(path, "md5")
+	return err == nil && sum == etag
+}
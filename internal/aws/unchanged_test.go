@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipUnchanged(t *testing.T) {
+	const body = "current content"
+
+	testCases := []struct {
+		name       string
+		etag       string
+		local      string
+		localMtime time.Duration // Relative to the object's LastModified
+		downloaded bool
+	}{
+		{"Matching ETag", "", body, -time.Hour, false},
+		{"Mismatched ETag", "", "outdate content", time.Hour, true},
+		{"Different size", "", "short", time.Hour, true},
+		{"Multipart ETag, local newer", "0123456789abcdef0123456789abcdef-2", body, time.Hour, false},
+		{"Multipart ETag, local older", "0123456789abcdef0123456789abcdef-2", body, -time.Hour, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{"file.txt": body})
+			if tc.etag != "" {
+				client.etags["file.txt"] = tc.etag
+			}
+			downloadPath := t.TempDir()
+			localPath := filepath.Join(downloadPath, "file.txt")
+			assert.NoError(t, os.WriteFile(localPath, []byte(tc.local), 0o600))
+			mtime := client.modified.Add(tc.localMtime)
+			assert.NoError(t, os.Chtimes(localPath, mtime, mtime))
+
+			d := newTestDownloader(client, LocalSink{})
+			d.config.SkipUnchanged = true
+			assert.NoError(t, d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil))
+
+			data, err := os.ReadFile(localPath)
+			assert.NoError(t, err)
+			if tc.downloaded {
+				assert.Len(t, client.gets, 1)
+				assert.Equal(t, body, string(data))
+				assert.Equal(t, int64(0), d.Progress().FilesSkipped)
+			} else {
+				assert.Empty(t, client.gets)
+				assert.Equal(t, tc.local, string(data))
+				assert.Equal(t, int64(1), d.Progress().FilesSkipped)
+			}
+		})
+	}
+}
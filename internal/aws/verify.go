@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"s3downloader/pkg/fileutils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// checkFile reads the local copy of obj once for everything that needs its digest:
+// verification against S3 when verify is set, and the SHA256 for the manifest when
+// one is written, which is returned
+func (d *Downloader) checkFile(ctx context.Context, bucket string, obj target, localPath string, verify bool) (string, error) {
+	key := aws.StringValue(obj.Key)
+
+	var algos []string
+	algo, expected := "", ""
+	if verify {
+		var err error
+		if algo, expected, err = d.expectedChecksum(ctx, bucket, obj); err != nil {
+			return "", err
+		}
+		if algo != "" {
+			algos = append(algos, algo)
+		}
+	}
+	if d.config.ManifestPath != "" {
+		algos = append(algos, "sha256")
+	}
+	if len(algos) == 0 {
+		return "", nil
+	}
+
+	sums, err := fileutils.ComputeFileChecksums(localPath, algos...)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash '%s': %w", key, err)
+	}
+	if algo != "" && sums[algo] != expected {
+		d.sink.Remove(localPath) // Do not leave corrupted files behind
+		return "", fmt.Errorf("checksum mismatch for '%s': expected %s %s, got %s", key, algo, expected, sums[algo])
+	}
+	return sums["sha256"], nil
+}
+
+// expectedChecksum returns the digest the downloaded file must have: the object's
+// single-part ETag (an MD5 digest) or, for multipart objects, the full-object
+// SHA256 checksum reported by HeadObject. Objects offering neither cannot be
+// verified and return an empty algorithm.
+func (d *Downloader) expectedChecksum(ctx context.Context, bucket string, obj target) (algo, expected string, err error) {
+	key := aws.StringValue(obj.Key)
+
+	algo, expected = "md5", strings.Trim(aws.StringValue(obj.ETag), `"`)
+	if expected != "" && !strings.Contains(expected, "-") {
+		return algo, expected, nil
+	}
+
+	input := d.headObjectInput(bucket, obj.Key, obj.versionID)
+	input.ChecksumMode = aws.String(s3.ChecksumModeEnabled)
+	out, err := d.s3.HeadObjectWithContext(ctx, input)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get checksum of '%s': %w", key, err)
+	}
+
+	// Checksums of multipart uploads are composite ("<checksum>-<parts>") and
+	// do not describe the whole content
+	checksum := aws.StringValue(out.ChecksumSHA256)
+	if checksum == "" || strings.Contains(checksum, "-") {
+		return "", "", nil
+	}
+	digest, err := base64.StdEncoding.DecodeString(checksum)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid SHA256 checksum for '%s': %w", key, err)
+	}
+	return "sha256", hex.EncodeToString(digest), nil
+}
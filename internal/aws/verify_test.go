@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	const body = "verified content"
+	sum := sha256.Sum256([]byte(body))
+	goodSHA := base64.StdEncoding.EncodeToString(sum[:])
+	badSHA := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	testCases := []struct {
+		name     string
+		etag     string
+		checksum string
+		wantErr  string
+	}{
+		{"Single-part ETag matches", "", "", ""},
+		{"Single-part ETag mismatch", "00000000000000000000000000000000", "", "checksum mismatch"},
+		{"Multipart with matching SHA256", "abc-2", goodSHA, ""},
+		{"Multipart with mismatched SHA256", "abc-2", badSHA, "checksum mismatch"},
+		{"Multipart with composite SHA256", "abc-2", badSHA + "-2", ""},
+		{"Multipart without checksum", "abc-2", "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeS3(map[string]string{"file.txt": body})
+			if tc.etag != "" {
+				client.etags["file.txt"] = tc.etag
+			}
+			if tc.checksum != "" {
+				client.checksums["file.txt"] = tc.checksum
+			}
+			downloadPath := t.TempDir()
+			d := newTestDownloader(client, LocalSink{})
+			d.config.VerifyChecksum = true
+
+			err := d.ListAndDownloadObjects(context.Background(), "bucket", "", downloadPath, nil)
+
+			localPath := filepath.Join(downloadPath, "file.txt")
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				assert.NoFileExists(t, localPath)
+				assert.Equal(t, int64(1), d.Progress().ErrorCount)
+				return
+			}
+			assert.NoError(t, err)
+			data, err := os.ReadFile(localPath)
+			assert.NoError(t, err)
+			assert.Equal(t, body, string(data))
+			assert.Equal(t, int64(0), d.Progress().ErrorCount)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// DownloadObjectVersion downloads one version of an object to localPath
+func (d *Downloader) DownloadObjectVersion(ctx context.Context, bucket, key, versionID, localPath string) error {
+	if err := d.sink.Mkdir(filepath.Dir(localPath), d.dirMode()); err != nil {
+		return fmt.Errorf("failed to create directory for '%s': %w", key, err)
+	}
+	input := d.getObjectInput(bucket, aws.String(key), aws.String(versionID))
+	_, err := d.downloadFile(ctx, d.newTransferManager(), input, -1, localPath, largeFileTimeout)
+	return err
+}
+
+// listVersions produces every version under prefix. Current versions keep their
+// key as local name; non-current versions get their version ID added so they do
+// not overwrite each other. Delete markers have no content and are ignored.
+func (d *Downloader) listVersions(bucket, prefix string) objectProducer {
+	return func(ctx context.Context, enqueue func(target) bool) error {
+		input := &s3.ListObjectVersionsInput{
+			Bucket:       aws.String(bucket),
+			Prefix:       aws.String(prefix),
+			RequestPayer: d.requestPayer(),
+		}
+		err := d.s3.ListObjectVersionsPagesWithContext(ctx, input, func(page *s3.ListObjectVersionsOutput, lastPage bool) bool {
+			for _, v := range page.Versions {
+				key := aws.StringValue(v.Key)
+				if !keyMatches(key, d.config.IncludePatterns, d.config.ExcludePatterns) {
+					continue
+				}
+				obj := &s3.Object{Key: v.Key, Size: v.Size, ETag: v.ETag, LastModified: v.LastModified, StorageClass: v.StorageClass}
+				t := target{Object: obj, versionID: v.VersionId, localKey: key}
+				if !aws.BoolValue(v.IsLatest) {
+					t.localKey = versionedKey(key, aws.StringValue(v.VersionId))
+				}
+				if !enqueue(t) {
+					return false
+				}
+			}
+			return !lastPage
+		})
+		if err != nil {
+			return fmt.Errorf("error listing object versions: %w", err)
+		}
+		return nil
+	}
+}
+
+// versionedKey inserts the version ID before the extension, so "dir/report.csv"
+// becomes "dir/report.<versionID>.csv"
+func versionedKey(key, versionID string) string {
+	ext := path.Ext(key)
+	return strings.TrimSuffix(key, ext) + "." + versionID + ext
+}
@@ -0,0 +1,54 @@
+package aws
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadObjectVersion(t *testing.T) {
+	client := newFakeS3(nil)
+	client.addVersion("report.csv", "v1", "old", false)
+	client.addVersion("report.csv", "v2", "new", true)
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+
+	localPath := filepath.Join("out", "report.csv")
+	err := d.DownloadObjectVersion(context.Background(), "bucket", "report.csv", "v1", localPath)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "old", sink.contents(localPath))
+	if assert.NotEmpty(t, client.gets) {
+		assert.Equal(t, "v1", aws.StringValue(client.gets[0].VersionId))
+	}
+}
+
+func TestListAndDownloadObjectsVersions(t *testing.T) {
+	client := newFakeS3(nil)
+	client.addVersion("dir/report.csv", "v1", "first", false)
+	client.addVersion("dir/report.csv", "v2", "second", false)
+	client.addVersion("dir/report.csv", "v3", "third", true)
+	sink := newMemorySink()
+	d := newTestDownloader(client, sink)
+	d.config.ListVersions = true
+
+	err := d.ListAndDownloadObjects(context.Background(), "bucket", "dir/", "out", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "third", sink.contents(filepath.Join("out", "dir", "report.csv")))
+	assert.Equal(t, "first", sink.contents(filepath.Join("out", "dir", "report.v1.csv")))
+	assert.Equal(t, "second", sink.contents(filepath.Join("out", "dir", "report.v2.csv")))
+	assert.Equal(t, int64(3), d.Progress().FilesFound)
+	for _, input := range client.gets {
+		assert.NotNil(t, input.VersionId)
+	}
+}
+
+func TestVersionedKey(t *testing.T) {
+	assert.Equal(t, "dir/report.abc.csv", versionedKey("dir/report.csv", "abc"))
+	assert.Equal(t, "dir/README.abc", versionedKey("dir/README", "abc"))
+	assert.Equal(t, "archive.tar.abc.gz", versionedKey("archive.tar.gz", "abc"))
+}
@@ -0,0 +1,306 @@
+// Package cli runs downloads from the command line without a display. It must
+// not import Fyne, so that the headless binary does not link the GUI.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"s3downloader/internal/aws"
+	"s3downloader/internal/config"
+	"s3downloader/internal/logging"
+	"s3downloader/internal/metrics"
+	"s3downloader/internal/progress"
+	"s3downloader/internal/redact"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Exit codes of Run
+const (
+	ExitOK    = 0
+	ExitError = 1 // The download failed or was interrupted
+	ExitUsage = 2 // The arguments were invalid
+)
+
+// defaultInterval is how often progress is printed
+const defaultInterval = 2 * time.Second
+
+// Downloader is the part of aws.Downloader used by the CLI
+type Downloader interface {
+	ListAndDownloadObjects(ctx context.Context, bucket, prefix, downloadPath string, progressChan chan<- progress.Progress) error
+	Progress() progress.Progress
+	SetLogger(logger *logging.Logger)
+	SetMetrics(m *metrics.Metrics)
+}
+
+// Options are the settings of a headless download
+type Options struct {
+	Bucket             string
+	Prefix             string
+	Path               string
+	Region             string
+	Overwrite          bool
+	AccessKey          string
+	SecretKey          string
+	SessionToken       string
+	Profile            string
+	Anonymous          bool
+	Endpoint           string
+	Accelerate         bool
+	Proxy              string
+	CACert             string
+	InsecureSkipVerify bool
+	Performance        string
+	Download           config.Options // Further tuning from a config file
+	LogFile            string
+	LogLevel           string
+	MetricsAddr        string // Address such as ":9090" to serve metrics on, empty for none
+	JSON               bool   // Print progress as JSON lines instead of text
+}
+
+// ParseArgs reads the options from command-line arguments. Flags override a
+// -config file, which overrides the environment variables read by
+// config.FromEnv. Usage, warnings and errors are written to output.
+func ParseArgs(args []string, output io.Writer) (Options, error) {
+	// A first pass only looks for -config; the second reports any errors
+	var configPath string
+	probe := newFlagSet(&Options{}, Options{}, &configPath, io.Discard)
+	_ = probe.Parse(args)
+
+	f := config.FromEnv()
+	if configPath != "" {
+		var warnings []string
+		var err error
+		f, warnings, err = config.ReadConfig(configPath, f)
+		for _, warning := range warnings {
+			fmt.Fprintf(output, "Warning: %s\n", warning)
+		}
+		if err != nil {
+			return Options{}, err
+		}
+	}
+	defaults := fileOptions(f, Options{Performance: aws.PresetBalanced})
+
+	o := defaults
+	fs := newFlagSet(&o, defaults, &configPath, output)
+	if err := fs.Parse(args); err != nil {
+		return Options{}, err
+	}
+	if fs.NArg() > 0 {
+		return Options{}, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if o.Bucket == "" || o.Path == "" {
+		return Options{}, errors.New("-bucket and -path are required")
+	}
+	if err := o.File().Validate(); err != nil {
+		return Options{}, err
+	}
+	return o, nil
+}
+
+// newFlagSet binds the flags to o, with their defaults taken from defaults
+func newFlagSet(o *Options, defaults Options, configPath *string, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("s3-downloader-cli", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(configPath, "config", "", "YAML or JSON file with the download settings; other flags override it")
+	fs.StringVar(&o.Bucket, "bucket", defaults.Bucket, "bucket to download from (required)")
+	fs.StringVar(&o.Prefix, "prefix", defaults.Prefix, "only download keys starting with this prefix")
+	fs.StringVar(&o.Path, "path", defaults.Path, "local directory to download to (required)")
+	fs.StringVar(&o.Region, "region", defaults.Region, "region of the bucket")
+	fs.BoolVar(&o.Overwrite, "overwrite", defaults.Overwrite, "download objects again even if the local file exists")
+	fs.StringVar(&o.AccessKey, "access-key", defaults.AccessKey, "AWS access key; the default credential chain is used without one")
+	fs.StringVar(&o.SecretKey, "secret-key", defaults.SecretKey, "AWS secret key")
+	fs.StringVar(&o.SessionToken, "session-token", defaults.SessionToken, "session token of temporary credentials")
+	fs.StringVar(&o.Profile, "profile", defaults.Profile, "profile from the shared credentials file, used without access keys")
+	fs.BoolVar(&o.Anonymous, "anonymous", defaults.Anonymous, "send unsigned requests without credentials, for public buckets")
+	fs.StringVar(&o.Endpoint, "endpoint", defaults.Endpoint, "URL of an S3-compatible service")
+	fs.BoolVar(&o.Accelerate, "accelerate", defaults.Accelerate, "use S3 Transfer Acceleration")
+	fs.StringVar(&o.Proxy, "proxy", defaults.Proxy, "HTTP(S) proxy URL; defaults to HTTPS_PROXY and HTTP_PROXY")
+	fs.StringVar(&o.CACert, "ca-cert", defaults.CACert, "PEM file of extra CA certificates to trust")
+	fs.BoolVar(&o.InsecureSkipVerify, "insecure-skip-verify", defaults.InsecureSkipVerify, "accept any TLS certificate, for testing only")
+	fs.StringVar(&o.Performance, "performance", defaults.Performance, "performance preset: Conservative, Balanced or Aggressive")
+	fs.StringVar(&o.LogFile, "log-file", defaults.LogFile, "write a JSON log of the download and every object to this file")
+	fs.StringVar(&o.LogLevel, "log-level", defaults.LogLevel, "lowest level written to the log: debug, info, warn or error")
+	fs.BoolVar(&o.JSON, "json", defaults.JSON, "print progress as one JSON object per line, for scripts")
+	fs.StringVar(&o.MetricsAddr, "metrics-addr", defaults.MetricsAddr, "serve Prometheus metrics on this address, such as :9090, under "+metrics.Path)
+	return fs
+}
+
+// fileOptions returns o with the values of the config file f
+func fileOptions(f config.File, o Options) Options {
+	o.Bucket = f.Bucket
+	o.Prefix = f.Prefix
+	o.Path = f.Path
+	o.Region = f.Region
+	o.Overwrite = f.Overwrite
+	o.AccessKey = f.Credentials.AccessKey
+	o.SecretKey = f.Credentials.SecretKey
+	o.SessionToken = f.Credentials.SessionToken
+	o.Profile = f.Credentials.Profile
+	o.Anonymous = f.Credentials.Anonymous
+	o.Endpoint = f.Download.Endpoint
+	o.Accelerate = f.Download.Accelerate
+	o.Proxy = f.Download.Proxy
+	o.CACert = f.Download.CACert
+	o.InsecureSkipVerify = f.Download.InsecureSkipVerify
+	if f.Download.Performance != "" {
+		o.Performance = f.Download.Performance
+	}
+	o.Download = f.Download
+	o.LogFile = f.Log.File
+	o.LogLevel = f.Log.Level
+	return o
+}
+
+// File returns the options in the form of a config file
+func (o Options) File() config.File {
+	f := config.File{
+		Bucket:    o.Bucket,
+		Prefix:    o.Prefix,
+		Path:      o.Path,
+		Region:    o.Region,
+		Overwrite: o.Overwrite,
+		Credentials: config.Credentials{
+			AccessKey:    o.AccessKey,
+			SecretKey:    o.SecretKey,
+			SessionToken: o.SessionToken,
+			Profile:      o.Profile,
+			Anonymous:    o.Anonymous,
+		},
+		Download: o.Download,
+		Log:      config.Log{File: o.LogFile, Level: o.LogLevel},
+	}
+	f.Download.Performance = o.Performance
+	f.Download.Endpoint = o.Endpoint
+	f.Download.Accelerate = o.Accelerate
+	f.Download.Proxy = o.Proxy
+	f.Download.CACert = o.CACert
+	f.Download.InsecureSkipVerify = o.InsecureSkipVerify
+	return f
+}
+
+// Config returns the downloader configuration for the options
+func (o Options) Config() aws.Config {
+	return o.File().AWSConfig()
+}
+
+// NewDownloader creates an S3 downloader for the options
+func NewDownloader(o Options) (Downloader, error) {
+	return aws.NewDownloaderWithConfig(o.Region, o.AccessKey, o.SecretKey, o.Config())
+}
+
+// Runner runs a headless download
+type Runner struct {
+	Stdout        io.Writer
+	Stderr        io.Writer
+	NewDownloader func(Options) (Downloader, error)
+	Interval      time.Duration // How often progress is printed, defaults to 2 seconds or progress.UpdateInterval for JSON
+}
+
+// Run downloads what args ask for, printing progress, and returns the exit code.
+// Credentials in anything it prints are masked.
+func (r Runner) Run(ctx context.Context, args []string) int {
+	r.Stdout = redact.NewWriter(r.Stdout)
+	r.Stderr = redact.NewWriter(r.Stderr)
+	o, err := ParseArgs(args, r.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	if err != nil {
+		fmt.Fprintf(r.Stderr, "Error: %v\n", err)
+		return ExitUsage
+	}
+
+	logger, err := config.Log{File: o.LogFile, Level: o.LogLevel}.Open("")
+	if err != nil {
+		fmt.Fprintf(r.Stderr, "Error: %v\n", err)
+		return ExitError
+	}
+	defer logger.Close()
+
+	d, err := r.NewDownloader(o)
+	if err != nil {
+		logger.Error("failed to create downloader", "error", err)
+		fmt.Fprintf(r.Stderr, "Error: failed to create downloader: %v\n", err)
+		return ExitError
+	}
+	d.SetLogger(logger)
+
+	// With -json stdout only carries progress lines
+	info := r.Stdout
+	if o.JSON {
+		info = r.Stderr
+	}
+
+	if o.MetricsAddr != "" {
+		metricsCtx, stopMetrics := context.WithCancel(ctx)
+		defer stopMetrics()
+		reg := prometheus.NewRegistry()
+		d.SetMetrics(metrics.New(reg))
+		addr, err := metrics.Serve(metricsCtx, o.MetricsAddr, reg)
+		if err != nil {
+			fmt.Fprintf(r.Stderr, "Error: %v\n", err)
+			return ExitError
+		}
+		fmt.Fprintf(info, "Serving metrics on http://%s%s\n", addr, metrics.Path)
+	}
+
+	interval := r.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+		if o.JSON {
+			interval = progress.UpdateInterval
+		}
+	}
+	fmt.Fprintf(info, "Downloading s3://%s/%s to %s\n", o.Bucket, o.Prefix, o.Path)
+	start := time.Now()
+	printProgress := func(p progress.Progress, done bool, err error) {
+		if o.JSON {
+			writeJSON(r.Stdout, newJSONLine(p, time.Now(), time.Since(start), done, err))
+			return
+		}
+		fmt.Fprintln(r.Stdout, formatProgress(p))
+	}
+
+	stop := make(chan struct{})
+	printed := make(chan struct{})
+	go func() {
+		defer close(printed)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				printProgress(d.Progress(), false, nil)
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	err = d.ListAndDownloadObjects(ctx, o.Bucket, o.Prefix, o.Path, nil)
+	close(stop)
+	<-printed
+
+	p := d.Progress()
+	printProgress(p, true, err)
+	elapsed := time.Since(start).Round(time.Second)
+	if err != nil {
+		fmt.Fprintf(r.Stderr, "Error: failed after %s: %v\n", elapsed, err)
+		return ExitError
+	}
+	fmt.Fprintf(info, "Finished in %s: %d downloaded, %d skipped, %d errors\n",
+		elapsed, p.FilesDownloaded-p.FilesSkipped, p.FilesSkipped, p.ErrorCount)
+	return ExitOK
+}
+
+// formatProgress describes a progress snapshot on one line
+func formatProgress(p progress.Progress) string {
+	return fmt.Sprintf("Files: %d/%d done, %d skipped, %d errors; %.1f MB",
+		p.Completed(), p.FilesFound, p.FilesSkipped, p.ErrorCount, float64(p.TotalBytes)/(1024*1024))
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"s3downloader/internal/progress"
+)
+
+// jsonLine is a progress snapshot as printed with -json. The last line of a
+// run has Done set and, if the run failed, Error.
+type jsonLine struct {
+	Time time.Time `json:"time"`
+	progress.Progress
+	BytesPerSec float64 `json:"bytesPerSec"` // Average since the run started
+	Done        bool    `json:"done"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// newJSONLine describes p, taken at now after elapsed of downloading
+func newJSONLine(p progress.Progress, now time.Time, elapsed time.Duration, done bool, err error) jsonLine {
+	line := jsonLine{Time: now.UTC(), Progress: p, Done: done}
+	if elapsed > 0 {
+		line.BytesPerSec = float64(p.TotalBytes) / elapsed.Seconds()
+	}
+	if err != nil {
+		line.Error = err.Error()
+	}
+	return line
+}
+
+// writeJSON prints line as a single line of JSON
+func writeJSON(w io.Writer, line jsonLine) {
+	// Encoding the plain struct cannot fail, and a closed stdout has no one to tell
+	_ = json.NewEncoder(w).Encode(line)
+}
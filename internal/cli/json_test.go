@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"s3downloader/internal/progress"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// jsonLines decodes every line of out as a jsonLine
+func jsonLines(t *testing.T, out string) []jsonLine {
+	var lines []jsonLine
+	for _, text := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		var line jsonLine
+		assert.NoError(t, json.Unmarshal([]byte(text), &line), text)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestRunJSON(t *testing.T) {
+	d := &mockDownloader{duration: 50 * time.Millisecond}
+	code, stdout, stderr, _ := run(t, context.Background(), d, "-bucket", "b", "-path", "out", "-json")
+
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stderr, "Downloading s3://b/ to out\n", "messages stay off stdout")
+	lines := jsonLines(t, stdout)
+	if !assert.GreaterOrEqual(t, len(lines), 2, "progress while downloading and a summary") {
+		return
+	}
+	assert.Equal(t, int64(1), lines[0].FilesDownloaded)
+	assert.False(t, lines[0].Done)
+	assert.Greater(t, lines[0].BytesPerSec, 0.0)
+	assert.WithinDuration(t, time.Now(), lines[0].Time, time.Minute)
+
+	last := lines[len(lines)-1]
+	assert.True(t, last.Done)
+	assert.Empty(t, last.Error)
+	assert.Equal(t, progress.Progress{FilesFound: 2, FilesDownloaded: 2, FilesSkipped: 1, TotalBytes: 2 * 1024 * 1024}, last.Progress)
+}
+
+func TestRunJSONFailure(t *testing.T) {
+	d := &mockDownloader{err: errors.New("access denied")}
+	code, stdout, _, _ := run(t, context.Background(), d, "-bucket", "b", "-path", "out", "-json")
+
+	assert.Equal(t, ExitError, code)
+	lines := jsonLines(t, stdout)
+	last := lines[len(lines)-1]
+	assert.True(t, last.Done)
+	assert.Equal(t, "access denied", last.Error)
+}
+
+func TestNewJSONLine(t *testing.T) {
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	line := newJSONLine(progress.Progress{FilesFound: 3, TotalBytes: 4096}, now, 2*time.Second, false, nil)
+
+	data, err := json.Marshal(line)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `{"time":"2024-05-06T07:08:09Z","phase":"","filesFound":3,`)
+	assert.Contains(t, string(data), `"totalBytes":4096,`)
+	assert.Contains(t, string(data), `"bytesPerSec":2048,"done":false}`)
+
+	assert.Zero(t, newJSONLine(progress.Progress{TotalBytes: 10}, now, 0, true, nil).BytesPerSec, "no speed before any time passed")
+}
@@ -0,0 +1,257 @@
+// Package config reads download jobs from YAML or JSON files, so that both the
+// GUI and the command-line downloader can run the same repeatable job.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+
+	"s3downloader/internal/aws"
+	"s3downloader/internal/logging"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is a download job as written in a config file
+type File struct {
+	Bucket      string      `yaml:"bucket"`
+	Prefix      string      `yaml:"prefix"`
+	Path        string      `yaml:"path"`
+	Region      string      `yaml:"region"`
+	Overwrite   bool        `yaml:"overwrite"`
+	Credentials Credentials `yaml:"credentials"`
+	Download    Options     `yaml:"download"`
+	Log         Log         `yaml:"log"`
+}
+
+// Credentials selects how to authenticate. Without keys the default AWS
+// credential chain or the named profile is used.
+type Credentials struct {
+	AccessKey    string `yaml:"accessKey"`
+	SecretKey    string `yaml:"secretKey"`
+	SessionToken string `yaml:"sessionToken"`
+	Profile      string `yaml:"profile"`
+	Anonymous    bool   `yaml:"anonymous"` // Send unsigned requests to a public bucket instead
+}
+
+// Log configures the structured log of downloads, see package logging
+type Log struct {
+	File  string `yaml:"file"`
+	Level string `yaml:"level"` // debug, info, warn or error
+}
+
+// Open opens the log file, or fallback when none is set; without either it
+// returns a nil logger, which discards events. The level defaults to info.
+func (l Log) Open(fallback string) (*logging.Logger, error) {
+	path := l.File
+	if path == "" {
+		path = fallback
+	}
+	if path == "" {
+		return nil, nil
+	}
+	level := slog.LevelInfo
+	if l.Level != "" {
+		var err error
+		if level, err = logging.ParseLevel(l.Level); err != nil {
+			return nil, err
+		}
+	}
+	return logging.Open(path, level, logging.DefaultMaxSize, logging.DefaultMaxFiles)
+}
+
+// Options tune the download; values left out keep those of the performance preset
+type Options struct {
+	Performance        string   `yaml:"performance"`
+	Endpoint           string   `yaml:"endpoint"`
+	Proxy              string   `yaml:"proxy"`  // HTTP(S) proxy URL; empty uses HTTPS_PROXY and HTTP_PROXY
+	CACert             string   `yaml:"caCert"` // PEM file of extra CA certificates to trust
+	InsecureSkipVerify bool     `yaml:"insecureSkipVerify"`
+	PathStyle          bool     `yaml:"pathStyle"`
+	Accelerate         bool     `yaml:"accelerate"` // Use S3 Transfer Acceleration
+	RequesterPays      bool     `yaml:"requesterPays"`
+	MaxWorkers         int      `yaml:"maxWorkers"`
+	Concurrency        int      `yaml:"concurrency"`
+	PartSize           Size     `yaml:"partSize"` // Bytes, or with a unit such as 16MiB
+	MaxSpeed           Speed    `yaml:"maxSpeed"` // MB/s, or with a unit such as 512KiB
+	Include            []string `yaml:"include"`
+	Exclude            []string `yaml:"exclude"`
+	SkipUnchanged      bool     `yaml:"skipUnchanged"`
+	Resume             bool     `yaml:"resume"`
+	Verify             bool     `yaml:"verify"`
+	Flatten            bool     `yaml:"flatten"`
+	StripPrefix        bool     `yaml:"stripPrefix"`
+	Versions           bool     `yaml:"versions"`
+	DecompressGzip     bool     `yaml:"decompressGzip"`
+	FailFast           bool     `yaml:"failFast"`
+	Mirror             bool     `yaml:"mirror"`
+	ReportPath         string   `yaml:"reportPath"`
+	ManifestPath       string   `yaml:"manifestPath"`
+	StateFile          string   `yaml:"stateFile"`
+}
+
+// LoadConfig reads and validates the config file at path. JSON files work as
+// well, since JSON is valid YAML. Fields the file has but File does not are
+// returned as warnings, so that typos do not go unnoticed.
+func LoadConfig(path string) (File, []string, error) {
+	f, warnings, err := ReadConfig(path, File{})
+	if err != nil {
+		return File{}, warnings, err
+	}
+	if err := f.Validate(); err != nil {
+		return File{}, warnings, fmt.Errorf("invalid config '%s': %w", path, err)
+	}
+	return f, warnings, nil
+}
+
+// ReadConfig reads the config file at path on top of defaults, so that fields
+// the file leaves out keep their default. Unlike LoadConfig it does not
+// validate the result, for callers that still add settings of their own.
+func ReadConfig(path string, defaults File) (File, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, nil, fmt.Errorf("failed to read config '%s': %w", path, err)
+	}
+	f, warnings, err := decode(data, defaults)
+	if err != nil {
+		return File{}, warnings, fmt.Errorf("invalid config '%s': %w", path, err)
+	}
+	return f, warnings, nil
+}
+
+// Parse decodes and validates a config file's content
+func Parse(data []byte) (File, []string, error) {
+	f, warnings, err := decode(data, File{})
+	if err != nil {
+		return File{}, warnings, err
+	}
+	return f, warnings, f.Validate()
+}
+
+// decode reads a config file's content on top of f
+func decode(data []byte, f File) (File, []string, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return File{}, nil, err
+	}
+	if len(node.Content) == 0 {
+		return f, nil, nil // Empty file
+	}
+	if err := node.Decode(&f); err != nil {
+		return File{}, nil, err
+	}
+	return f, unknownFields(node.Content[0], reflect.TypeOf(f), ""), nil
+}
+
+// unknownFields lists the keys of the mapping node that have no field in t
+func unknownFields(node *yaml.Node, t reflect.Type, parent string) []string {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var warnings []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		name := key
+		if parent != "" {
+			name = parent + "." + key
+		}
+		field, ok := fieldByTag(t, key)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("unknown field '%s' on line %d", name, node.Content[i].Line))
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			warnings = append(warnings, unknownFields(node.Content[i+1], field.Type, name)...)
+		}
+	}
+	return warnings
+}
+
+// fieldByTag finds the field of t with the given yaml name
+func fieldByTag(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if tag, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); tag == name {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// Validate reports missing required fields and invalid values
+func (f File) Validate() error {
+	var errs []error
+	if f.Bucket == "" {
+		errs = append(errs, errors.New("bucket is required"))
+	}
+	if f.Path == "" {
+		errs = append(errs, errors.New("path is required"))
+	}
+	if p := f.Download.Performance; p != "" && !validPreset(p) {
+		errs = append(errs, fmt.Errorf("unknown performance preset '%s', use one of %s", p, strings.Join(aws.PerformancePresets, ", ")))
+	}
+	if f.Log.Level != "" {
+		if _, err := logging.ParseLevel(f.Log.Level); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if f.Download.MaxSpeed < 0 {
+		errs = append(errs, errors.New("maxSpeed must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// validPreset reports whether name is one of the performance presets
+func validPreset(name string) bool {
+	for _, preset := range aws.PerformancePresets {
+		if preset == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AWSConfig returns the downloader configuration for the file
+func (f File) AWSConfig() aws.Config {
+	o := f.Download
+	cfg := aws.PresetConfig(o.Performance)
+	if o.MaxWorkers > 0 {
+		cfg.MaxWorkers = o.MaxWorkers
+	}
+	if o.Concurrency > 0 {
+		cfg.Concurrency = o.Concurrency
+	}
+	if o.PartSize > 0 {
+		cfg.PartSize = int64(o.PartSize)
+	}
+	cfg.MaxBytesPerSec = int64(float64(o.MaxSpeed) * 1024 * 1024)
+	cfg.Overwrite = f.Overwrite
+	cfg.Endpoint = o.Endpoint
+	cfg.ProxyURL = o.Proxy
+	cfg.CACertFile = o.CACert
+	cfg.InsecureSkipVerify = o.InsecureSkipVerify
+	cfg.PathStyle = o.PathStyle
+	cfg.Accelerate = o.Accelerate
+	cfg.RequesterPays = o.RequesterPays
+	cfg.IncludePatterns = o.Include
+	cfg.ExcludePatterns = o.Exclude
+	cfg.SkipUnchanged = o.SkipUnchanged
+	cfg.ResumePartial = o.Resume
+	cfg.VerifyChecksum = o.Verify
+	cfg.Flatten = o.Flatten
+	cfg.StripPrefix = o.StripPrefix
+	cfg.ListVersions = o.Versions
+	cfg.DecompressGzip = o.DecompressGzip
+	cfg.FailFast = o.FailFast
+	cfg.Mirror = o.Mirror
+	cfg.ReportPath = o.ReportPath
+	cfg.ManifestPath = o.ManifestPath
+	cfg.StateFile = o.StateFile
+	cfg.SessionToken = f.Credentials.SessionToken
+	cfg.Profile = f.Credentials.Profile
+	cfg.Anonymous = f.Credentials.Anonymous
+	return cfg
+}
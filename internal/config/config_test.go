@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"s3downloader/internal/aws"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigYAML(t *testing.T) {
+	f, warnings, err := LoadConfig(filepath.Join("testdata", "job.yaml"))
+
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "app-logs", f.Bucket)
+	assert.Equal(t, "production/2024/", f.Prefix)
+	assert.Equal(t, "/var/backups/app-logs", f.Path)
+	assert.Equal(t, "eu-west-1", f.Region)
+	assert.Equal(t, "backup", f.Credentials.Profile)
+
+	cfg := f.AWSConfig()
+	assert.Equal(t, aws.AggressiveConfig().MaxWorkers, cfg.MaxWorkers)
+	assert.Equal(t, int64(50*1024*1024), cfg.MaxBytesPerSec)
+	assert.Equal(t, []string{"*.gz"}, cfg.IncludePatterns)
+	assert.True(t, cfg.SkipUnchanged)
+	assert.True(t, cfg.VerifyChecksum)
+	assert.Equal(t, "/var/backups/app-logs/report.json", cfg.ReportPath)
+	assert.Equal(t, "backup", cfg.Profile)
+}
+
+func TestLoadConfigJSONWarnsAboutUnknownFields(t *testing.T) {
+	f, warnings, err := LoadConfig(filepath.Join("testdata", "job.json"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"unknown field 'credentails' on line 5",
+		"unknown field 'download.verfiy' on line 6",
+	}, warnings)
+	assert.Equal(t, 20, f.AWSConfig().MaxWorkers)
+	assert.False(t, f.Download.Verify)
+}
+
+func TestParseValidation(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"Missing bucket", "path: out", "bucket is required"},
+		{"Missing bucket and path", "region: eu-west-1", "bucket is required\npath is required"},
+		{"Unknown preset", "bucket: b\npath: out\ndownload:\n  performance: Turbo", "unknown performance preset 'Turbo', use one of Conservative, Balanced, Aggressive"},
+		{"Unknown log level", "bucket: b\npath: out\nlog:\n  level: loud", "invalid log level 'loud'"},
+		{"Negative speed", "bucket: b\npath: out\ndownload:\n  maxSpeed: -1", "maxSpeed must not be negative"},
+		{"Wrong type", "bucket: b\npath: out\noverwrite: sometimes", "cannot unmarshal"},
+		{"Empty file", "", "bucket is required"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := Parse([]byte(tc.data))
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	_, _, err := LoadConfig(filepath.Join("testdata", "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read config")
+}
+
+func TestLogOpen(t *testing.T) {
+	logger, err := Log{}.Open("")
+	assert.NoError(t, err)
+	assert.Nil(t, logger, "logging is off without a file")
+
+	_, err = Log{Level: "loud"}.Open(filepath.Join(t.TempDir(), "app.log"))
+	assert.ErrorContains(t, err, "invalid log level")
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger, err = Log{Level: "warn"}.Open(path)
+	assert.NoError(t, err)
+	logger.Info("dropped")
+	logger.Warn("kept")
+	assert.NoError(t, logger.Close())
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "dropped")
+	assert.Contains(t, string(data), `"msg":"kept"`)
+}
+
+func TestParseSizes(t *testing.T) {
+	testCases := []struct {
+		name         string
+		download     string
+		wantPartSize int64
+		wantMaxBytes int64
+		wantErr      string
+	}{
+		{"Plain numbers", "partSize: 8388608\n  maxSpeed: 2.5", 8 * 1024 * 1024, 5 * 512 * 1024, ""},
+		{"IEC units", "partSize: 16MiB\n  maxSpeed: 512KiB", 16 * 1024 * 1024, 512 * 1024, ""},
+		{"SI units", "partSize: 10MB\n  maxSpeed: 1.5mb", 10000000, 1500000, ""},
+		{"Quoted", "partSize: \"1GiB\"", 1 << 30, 0, ""},
+		{"Missing unit", "partSize: \"1024\"", 0, 0, "line 5: invalid size '1024': add a unit such as MB or MiB"},
+		{"Unknown unit", "maxSpeed: 10 bananas", 0, 0, "line 5: invalid size '10 bananas': unknown unit 'bananas'"},
+		{"Negative size", "partSize: -5MB", 0, 0, "line 5: invalid size '-5MB': must not be negative"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, _, err := Parse([]byte("bucket: b\npath: out\nregion: eu-west-1\ndownload:\n  " + tc.download))
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			cfg := f.AWSConfig()
+			if tc.wantPartSize > 0 {
+				assert.Equal(t, tc.wantPartSize, cfg.PartSize)
+			}
+			assert.Equal(t, tc.wantMaxBytes, cfg.MaxBytesPerSec)
+		})
+	}
+}
+
+func TestParseSizesInJSON(t *testing.T) {
+	f, _, err := Parse([]byte(`{"bucket": "b", "path": "out", "download": {"partSize": "32MiB", "maxSpeed": 4}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, Size(32*1024*1024), f.Download.PartSize)
+	assert.Equal(t, Speed(4), f.Download.MaxSpeed)
+}
@@ -0,0 +1,37 @@
+package config
+
+import "os"
+
+// Environment variables read by FromEnv. The AWS ones are those of the AWS
+// CLI and SDKs, so that existing container setups work unchanged.
+const (
+	EnvBucket       = "S3DL_BUCKET"           // Bucket to download from
+	EnvPrefix       = "S3DL_PREFIX"           // Only download keys starting with this prefix
+	EnvPath         = "S3DL_PATH"             // Local directory to download to
+	EnvRegion       = "AWS_REGION"            // Region of the bucket
+	EnvAccessKey    = "AWS_ACCESS_KEY_ID"     // Access key of static credentials
+	EnvSecretKey    = "AWS_SECRET_ACCESS_KEY" // Secret key of static credentials
+	EnvSessionToken = "AWS_SESSION_TOKEN"     // Session token of temporary credentials
+	EnvLogFile      = "S3DL_LOG_FILE"         // Structured log file
+	EnvLogLevel     = "S3DL_LOG_LEVEL"        // Lowest level written to the log
+)
+
+// FromEnv returns the settings given by environment variables; the others are
+// left empty. Settings from a config file or arguments take precedence over them.
+func FromEnv() File {
+	return File{
+		Bucket: os.Getenv(EnvBucket),
+		Prefix: os.Getenv(EnvPrefix),
+		Path:   os.Getenv(EnvPath),
+		Region: os.Getenv(EnvRegion),
+		Credentials: Credentials{
+			AccessKey:    os.Getenv(EnvAccessKey),
+			SecretKey:    os.Getenv(EnvSecretKey),
+			SessionToken: os.Getenv(EnvSessionToken),
+		},
+		Log: Log{
+			File:  os.Getenv(EnvLogFile),
+			Level: os.Getenv(EnvLogLevel),
+		},
+	}
+}
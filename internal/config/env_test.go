@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(EnvBucket, "env-bucket")
+	t.Setenv(EnvPrefix, "env/")
+	t.Setenv(EnvPath, "/data")
+	t.Setenv(EnvRegion, "ap-south-1")
+	t.Setenv(EnvAccessKey, "AKID")
+	t.Setenv(EnvSecretKey, "SECRET")
+	t.Setenv(EnvSessionToken, "TOKEN")
+	t.Setenv(EnvLogFile, "/var/log/s3dl.log")
+	t.Setenv(EnvLogLevel, "debug")
+
+	assert.Equal(t, File{
+		Bucket:      "env-bucket",
+		Prefix:      "env/",
+		Path:        "/data",
+		Region:      "ap-south-1",
+		Credentials: Credentials{AccessKey: "AKID", SecretKey: "SECRET", SessionToken: "TOKEN"},
+		Log:         Log{File: "/var/log/s3dl.log", Level: "debug"},
+	}, FromEnv())
+
+	for _, name := range []string{EnvBucket, EnvPrefix, EnvPath, EnvRegion, EnvAccessKey, EnvSecretKey, EnvSessionToken, EnvLogFile, EnvLogLevel} {
+		os.Unsetenv(name)
+	}
+	assert.Equal(t, File{}, FromEnv())
+}
+
+func TestReadConfigOverEnv(t *testing.T) {
+	t.Setenv(EnvBucket, "env-bucket")
+	t.Setenv(EnvPath, "/data")
+	t.Setenv(EnvRegion, "ap-south-1")
+	t.Setenv(EnvAccessKey, "AKID")
+	path := filepath.Join(t.TempDir(), "job.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("bucket: file-bucket\ncredentials:\n  profile: backup\n"), 0o600))
+
+	f, warnings, err := ReadConfig(path, FromEnv())
+
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "file-bucket", f.Bucket, "the file overrides the environment")
+	assert.Equal(t, "/data", f.Path, "the environment fills in what the file leaves out")
+	assert.Equal(t, "ap-south-1", f.Region)
+	assert.Equal(t, Credentials{AccessKey: "AKID", Profile: "backup"}, f.Credentials)
+}
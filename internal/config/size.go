@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+
+	"s3downloader/pkg/fileutils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Size is a number of bytes, written either as a plain number of bytes or with
+// a unit such as "16MiB" or "10MB"
+type Size int64
+
+// UnmarshalYAML accepts a number of bytes or a size with a unit
+func (s *Size) UnmarshalYAML(node *yaml.Node) error {
+	if node.Tag == "!!int" {
+		var n int64
+		if err := node.Decode(&n); err != nil {
+			return err
+		}
+		*s = Size(n)
+		return nil
+	}
+	n, err := fileutils.ParseSize(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*s = Size(n)
+	return nil
+}
+
+// Speed is a download rate in MB/s, written either as a plain number of MB/s or
+// as a size per second with a unit such as "512KiB"
+type Speed float64
+
+// UnmarshalYAML accepts a number of MB/s or a size with a unit
+func (s *Speed) UnmarshalYAML(node *yaml.Node) error {
+	if node.Tag == "!!int" || node.Tag == "!!float" {
+		var mbps float64
+		if err := node.Decode(&mbps); err != nil {
+			return err
+		}
+		*s = Speed(mbps)
+		return nil
+	}
+	n, err := fileutils.ParseSize(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*s = Speed(float64(n) / (1024 * 1024))
+	return nil
+}
@@ -0,0 +1,67 @@
+package crash
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"s3downloader/internal/redact"
+)
+
+// fileName is the name of the crash log written when the app panics
+const fileName = "s3downloader-crash.log"
+
+// LogPath returns where the crash log is written: the user cache directory when
+// available, otherwise the system temp directory
+func LogPath() string {
+	cacheDir, err := os.UserCacheDir()
+	return resolveLogPath(cacheDir, err)
+}
+
+// resolveLogPath picks the crash log location from the result of os.UserCacheDir
+func resolveLogPath(cacheDir string, err error) string {
+	if err != nil || cacheDir == "" {
+		return filepath.Join(os.TempDir(), fileName)
+	}
+	return filepath.Join(cacheDir, "s3downloader", fileName)
+}
+
+// stderr receives the crash report when the crash log cannot be written
+var stderr io.Writer = os.Stderr
+
+// Write records a recovered panic value of any type and its stack trace in the
+// crash log, together with build and platform details, and returns the path
+// written. If the log cannot be written the report goes to stderr instead, so
+// that the stack trace is not lost.
+func Write(r interface{}, stack []byte, version, commit string) (path string, err error) {
+	text := report(r, stack, version, commit, time.Now())
+	path = LogPath()
+	defer func() {
+		if err != nil {
+			io.WriteString(stderr, text)
+		}
+	}()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return path, err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return path, err
+	}
+	if _, err := io.WriteString(file, text); err != nil {
+		file.Close()
+		return path, err
+	}
+	return path, file.Close()
+}
+
+// report formats the crash report for the panic value r, whatever its type, with
+// any credentials in it masked
+func report(r interface{}, stack []byte, version, commit string, now time.Time) string {
+	return redact.String(fmt.Sprintf("s3downloader %s (%s)\nTime: %s\nOS: %s/%s, Go: %s\n\npanic: %v\n\n%s",
+		version, commit, now.Format(time.RFC3339), runtime.GOOS, runtime.GOARCH, runtime.Version(), r, stack))
+}
@@ -0,0 +1,112 @@
+// Package logging writes a structured audit trail of downloads and user
+// actions as JSON lines, one object per event.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"s3downloader/internal/redact"
+)
+
+// fileName is the name of the log file in the default location
+const fileName = "s3downloader.log"
+
+// Defaults of the log file rotation
+const (
+	DefaultMaxSize  = 10 * 1024 * 1024 // Bytes
+	DefaultMaxFiles = 5
+)
+
+// Logger writes leveled events. A nil *Logger discards everything, so that
+// callers need no checks when logging is off.
+type Logger struct {
+	slog   *slog.Logger
+	closer io.Closer
+}
+
+// New creates a logger writing JSON lines of at least level to w, with any
+// credentials in them masked
+func New(w io.Writer, level slog.Level) *Logger {
+	return &Logger{slog: slog.New(slog.NewJSONHandler(redact.NewWriter(w), &slog.HandlerOptions{Level: level}))}
+}
+
+// Open creates a logger appending to the file at path, rotated once it grows
+// beyond maxSize bytes with at most maxFiles old files kept
+func Open(path string, level slog.Level, maxSize int64, maxFiles int) (*Logger, error) {
+	file, err := OpenRotatingFile(path, maxSize, maxFiles)
+	if err != nil {
+		return nil, err
+	}
+	l := New(file, level)
+	l.closer = file
+	return l, nil
+}
+
+// DefaultPath returns where the log is written unless configured otherwise:
+// the user cache directory when available, otherwise the system temp directory
+func DefaultPath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil || cacheDir == "" {
+		return filepath.Join(os.TempDir(), fileName)
+	}
+	return filepath.Join(cacheDir, "s3downloader", fileName)
+}
+
+// ParseLevel converts a level name such as "info" or "DEBUG"
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return 0, fmt.Errorf("invalid log level '%s': use debug, info, warn or error", name)
+	}
+	return level, nil
+}
+
+// With returns a logger adding the given attributes to every event
+func (l *Logger) With(args ...any) *Logger {
+	if l == nil {
+		return nil
+	}
+	return &Logger{slog: l.slog.With(args...)}
+}
+
+// Debug logs details that are only useful when investigating a problem
+func (l *Logger) Debug(msg string, args ...any) {
+	l.log(slog.LevelDebug, msg, args)
+}
+
+// Info logs a normal event
+func (l *Logger) Info(msg string, args ...any) {
+	l.log(slog.LevelInfo, msg, args)
+}
+
+// Warn logs a problem that did not stop the operation
+func (l *Logger) Warn(msg string, args ...any) {
+	l.log(slog.LevelWarn, msg, args)
+}
+
+// Error logs a failed operation
+func (l *Logger) Error(msg string, args ...any) {
+	l.log(slog.LevelError, msg, args)
+}
+
+// log writes an event with alternating key and value arguments
+func (l *Logger) log(level slog.Level, msg string, args []any) {
+	if l == nil {
+		return
+	}
+	l.slog.Log(context.Background(), level, msg, args...)
+}
+
+// Close closes the log file of a logger created by Open
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// RotatingFile appends to a log file and, when a write would take it beyond
+// maxSize, renames it to path.1, shifting older files up to path.<maxFiles> and
+// deleting the oldest
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+// OpenRotatingFile opens the log file at path for appending, creating it and
+// its directory if needed. maxSize of zero or less disables rotation.
+func OpenRotatingFile(path string, maxSize int64, maxFiles int) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	r := &RotatingFile{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the current log file and records its size
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log '%s': %w", r.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log '%s': %w", r.path, err)
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if p does not fit. A single write larger
+// than maxSize still goes into one file.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the old files up by one and starts a new log file
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to rotate log '%s': %w", r.path, err)
+	}
+	r.file = nil
+	if r.maxFiles > 0 {
+		os.Remove(r.backup(r.maxFiles))
+		for i := r.maxFiles - 1; i >= 1; i-- {
+			os.Rename(r.backup(i), r.backup(i+1))
+		}
+		if err := os.Rename(r.path, r.backup(1)); err != nil {
+			return fmt.Errorf("failed to rotate log '%s': %w", r.path, err)
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return fmt.Errorf("failed to rotate log '%s': %w", r.path, err)
+	}
+	return r.open()
+}
+
+// backup returns the path of the i-th old log file
+func (r *RotatingFile) backup(i int) string {
+	return fmt.Sprintf("%s.%d", r.path, i)
+}
+
+// Close closes the log file
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	r, err := OpenRotatingFile(path, 10, 2)
+	assert.NoError(t, err)
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := r.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, r.Close())
+
+	read := func(p string) string {
+		data, err := os.ReadFile(p)
+		assert.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "fourth\n", read(path))
+	assert.Equal(t, "third\n", read(path+".1"))
+	assert.Equal(t, "second\n", read(path+".2"))
+	assert.NoFileExists(t, path+".3", "only maxFiles old files are kept")
+
+	// Reopening appends and counts the existing size
+	r, err = OpenRotatingFile(path, 10, 2)
+	assert.NoError(t, err)
+	_, err = r.Write([]byte("fifth\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, "fifth\n", read(path))
+	assert.Equal(t, "fourth\n", read(path+".1"))
+
+	_, err = r.Write([]byte("closed\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := Open(path, slog.LevelDebug, DefaultMaxSize, DefaultMaxFiles)
+	assert.NoError(t, err)
+	l.Debug("download started", "bucket", "my-bucket")
+	assert.NoError(t, l.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(data), "\n"))
+	assert.Contains(t, string(data), `"level":"DEBUG","msg":"download started","bucket":"my-bucket"`)
+}
@@ -0,0 +1,134 @@
+// Package metrics exposes download counters in the Prometheus text format so
+// that unattended runs can be scraped.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path is where Serve exposes the metrics
+const Path = "/metrics"
+
+// rateWindow is the shortest interval the throughput gauge is averaged over;
+// scrapes closer together than this repeat the last value
+const rateWindow = time.Second
+
+// Metrics counts the outcomes of downloads. A nil *Metrics ignores every
+// update, so that callers need no checks when metrics are off.
+type Metrics struct {
+	downloaded prometheus.Counter
+	skipped    prometheus.Counter
+	errors     prometheus.Counter
+	bytes      prometheus.Counter
+
+	total    atomic.Int64 // Bytes so far, sampled by the throughput gauge
+	mu       sync.Mutex
+	lastTime time.Time
+	lastSize int64
+	rate     float64
+	now      func() time.Time
+}
+
+// New creates the metrics and registers them with reg
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		downloaded: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "s3downloader_files_downloaded_total",
+			Help: "Objects downloaded.",
+		}),
+		skipped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "s3downloader_files_skipped_total",
+			Help: "Objects skipped because an up-to-date local copy exists.",
+		}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "s3downloader_errors_total",
+			Help: "Objects that failed to download.",
+		}),
+		bytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "s3downloader_bytes_downloaded_total",
+			Help: "Bytes written to downloaded files.",
+		}),
+		now: time.Now,
+	}
+	m.lastTime = m.now()
+	throughput := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "s3downloader_throughput_bytes_per_second",
+		Help: "Download speed since the previous scrape.",
+	}, m.throughput)
+	reg.MustRegister(m.downloaded, m.skipped, m.errors, m.bytes, throughput)
+	return m
+}
+
+// FileDownloaded counts a downloaded object
+func (m *Metrics) FileDownloaded() {
+	if m != nil {
+		m.downloaded.Inc()
+	}
+}
+
+// FileSkipped counts an object that did not need downloading
+func (m *Metrics) FileSkipped() {
+	if m != nil {
+		m.skipped.Inc()
+	}
+}
+
+// Error counts a failed object
+func (m *Metrics) Error() {
+	if m != nil {
+		m.errors.Inc()
+	}
+}
+
+// AddBytes counts n bytes written
+func (m *Metrics) AddBytes(n int64) {
+	if m != nil && n > 0 {
+		m.bytes.Add(float64(n))
+		m.total.Add(n)
+	}
+}
+
+// throughput returns the bytes per second since the last sample
+func (m *Metrics) throughput() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if elapsed := now.Sub(m.lastTime); elapsed >= rateWindow {
+		size := m.total.Load()
+		m.rate = float64(size-m.lastSize) / elapsed.Seconds()
+		m.lastTime, m.lastSize = now, size
+	}
+	return m.rate
+}
+
+// Serve exposes the metrics of reg on addr, such as ":9090", under Path until
+// ctx is canceled. It returns once the address is listening.
+func Serve(ctx context.Context, addr string, reg prometheus.Gatherer) (net.Addr, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serve metrics on '%s': %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Path, promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			listener.Close()
+		}
+	}()
+	return listener.Addr(), nil
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThroughput(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	now := m.lastTime
+	m.now = func() time.Time { return now }
+
+	m.AddBytes(4 * 1024)
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, 2048.0, m.throughput())
+
+	// Scrapes within the window repeat the last rate
+	m.AddBytes(1024)
+	now = now.Add(100 * time.Millisecond)
+	assert.Equal(t, 2048.0, m.throughput())
+
+	now = now.Add(900 * time.Millisecond)
+	assert.Equal(t, 1024.0, m.throughput())
+	now = now.Add(time.Second)
+	assert.Equal(t, 0.0, m.throughput(), "idle")
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	assert.NotPanics(t, func() {
+		m.FileDownloaded()
+		m.FileSkipped()
+		m.Error()
+		m.AddBytes(10)
+	})
+}
+
+func TestServe(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := New(reg)
+	m.FileDownloaded()
+	m.FileDownloaded()
+	m.FileSkipped()
+	m.Error()
+	m.AddBytes(1500)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	addr, err := Serve(ctx, "127.0.0.1:0", reg)
+	assert.NoError(t, err)
+
+	resp, err := http.Get("http://" + addr.String() + Path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	for _, line := range []string{
+		"s3downloader_files_downloaded_total 2",
+		"s3downloader_files_skipped_total 1",
+		"s3downloader_errors_total 1",
+		"s3downloader_bytes_downloaded_total 1500",
+		"# TYPE s3downloader_throughput_bytes_per_second gauge",
+	} {
+		assert.Contains(t, string(body), line+"\n")
+	}
+
+	_, err = Serve(ctx, addr.String(), reg)
+	assert.ErrorContains(t, err, "failed to serve metrics")
+}
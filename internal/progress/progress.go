@@ -1,8 +1,127 @@
 package progress
 
-// Progress struct to track the progress of download operations
+import (
+	"sync/atomic"
+	"time"
+)
+
+// UpdateInterval is how often progress displays sample the counters during a download
+const UpdateInterval = 500 * time.Millisecond
+
+// Phases reported through the Progress struct
+const (
+	PhaseDownloading = "downloading"
+	PhaseCleaning    = "cleaning"
+)
+
+// Progress struct to track the progress of download operations. Every object
+// found ends up either processed (FilesDownloaded, which includes FilesSkipped),
+// filtered out (TagFiltered, TypeFiltered) or failed (ErrorCount).
 type Progress struct {
-	FilesFound      int64
-	FilesDownloaded int64
-	FilesSkipped    int64
+	Phase              string       `json:"phase"`
+	FilesFound         int64        `json:"filesFound"`      // Objects queued for download so far
+	FilesDownloaded    int64        `json:"filesDownloaded"` // Objects processed: downloaded, or skipped because an up-to-date copy exists
+	FilesSkipped       int64        `json:"filesSkipped"`    // The part of FilesDownloaded that was skipped without transferring data
+	FilesScanned       int64        `json:"filesScanned"`
+	FilesDeleted       int64        `json:"filesDeleted"`
+	ErrorCount         int64        `json:"errorCount"`
+	WarningCount       int64        `json:"warningCount"`
+	ArchivedSkipped    int64        `json:"archivedSkipped"` // Archived objects passed over while listing; not part of FilesFound
+	TagFiltered        int64        `json:"tagFiltered"`     // Found objects left out for not matching the tag filters
+	TypeFiltered       int64        `json:"typeFiltered"`    // Found objects left out for not matching the content types
+	FilesRestoring     int64        `json:"filesRestoring"`
+	TotalBytes         int64        `json:"totalBytes"`
+	TotalBytesExpected int64        `json:"totalBytesExpected"` // Size of the objects found so far that still count towards the download
+	ListingComplete    bool         `json:"listingComplete"`    // All objects have been found, so TotalBytesExpected only shrinks from here
+	CurrentFile        FileProgress `json:"currentFile"`
+}
+
+// Completed returns the number of found objects that need no further work: those
+// downloaded, skipped or filtered out. It reaches FilesFound once a run without
+// errors is over, however many objects were skipped.
+func (p Progress) Completed() int64 {
+	return p.FilesDownloaded + p.TagFiltered + p.TypeFiltered
+}
+
+// FileProgress describes the object that most recently received data
+type FileProgress struct {
+	Key   string `json:"key"`
+	Bytes int64  `json:"bytes"` // Bytes of the object written so far
+}
+
+// Tracker holds live progress counters that workers update concurrently
+// and readers sample with Snapshot
+type Tracker struct {
+	phase              atomic.Value
+	FilesFound         atomic.Int64
+	FilesDownloaded    atomic.Int64
+	FilesSkipped       atomic.Int64
+	FilesScanned       atomic.Int64
+	FilesDeleted       atomic.Int64
+	ErrorCount         atomic.Int64
+	WarningCount       atomic.Int64
+	ArchivedSkipped    atomic.Int64
+	TagFiltered        atomic.Int64
+	TypeFiltered       atomic.Int64
+	FilesRestoring     atomic.Int64
+	TotalBytes         atomic.Int64
+	TotalBytesExpected atomic.Int64
+	ListingComplete    atomic.Bool
+	currentFile        atomic.Pointer[FileCounter]
+}
+
+// NewTracker creates a Tracker starting in the given phase
+func NewTracker(phase string) *Tracker {
+	t := &Tracker{}
+	t.SetPhase(phase)
+	return t
+}
+
+// SetPhase switches the phase reported by the tracker
+func (t *Tracker) SetPhase(phase string) {
+	t.phase.Store(phase)
+}
+
+// FileCounter counts the bytes written for the object Key; it is meant to be embedded
+// in the per-object state of a download and must not be copied once in use
+type FileCounter struct {
+	Key   string
+	bytes atomic.Int64
+}
+
+// AddFileBytes counts n more bytes written for the object of c, makes it the current
+// file and returns its total so far. It allocates nothing, so it suits every write.
+func (t *Tracker) AddFileBytes(c *FileCounter, n int64) int64 {
+	bytes := c.bytes.Add(n)
+	if t.currentFile.Load() != c {
+		t.currentFile.Store(c)
+	}
+	return bytes
+}
+
+// Snapshot returns the current value of all counters
+func (t *Tracker) Snapshot() Progress {
+	phase, _ := t.phase.Load().(string)
+	var current FileProgress
+	if f := t.currentFile.Load(); f != nil {
+		current = FileProgress{Key: f.Key, Bytes: f.bytes.Load()}
+	}
+	return Progress{
+		Phase:              phase,
+		FilesFound:         t.FilesFound.Load(),
+		FilesDownloaded:    t.FilesDownloaded.Load(),
+		FilesSkipped:       t.FilesSkipped.Load(),
+		FilesScanned:       t.FilesScanned.Load(),
+		FilesDeleted:       t.FilesDeleted.Load(),
+		ErrorCount:         t.ErrorCount.Load(),
+		WarningCount:       t.WarningCount.Load(),
+		ArchivedSkipped:    t.ArchivedSkipped.Load(),
+		TagFiltered:        t.TagFiltered.Load(),
+		TypeFiltered:       t.TypeFiltered.Load(),
+		FilesRestoring:     t.FilesRestoring.Load(),
+		TotalBytes:         t.TotalBytes.Load(),
+		TotalBytesExpected: t.TotalBytesExpected.Load(),
+		ListingComplete:    t.ListingComplete.Load(),
+		CurrentFile:        current,
+	}
 }
@@ -0,0 +1,112 @@
+// Package redact masks AWS credentials in text before it is logged or written
+// to a crash report, so that errors wrapping them cannot leak them.
+package redact
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Mask replaces secret keys, session tokens and registered secrets
+const Mask = "[REDACTED]"
+
+// minSecretLength is the shortest registered secret that is masked; shorter
+// values would mask ordinary words
+const minSecretLength = 6
+
+var (
+	// accessKeyID matches AWS access key IDs by their documented prefixes
+	accessKeyID = regexp.MustCompile(`\b(?:AKIA|ASIA|AIDA|AROA|AIPA|ANPA|ANVA|AGPA)[A-Z0-9]{16}\b`)
+
+	// base64Run matches runs of the characters of secret keys and session tokens
+	base64Run = regexp.MustCompile(`[A-Za-z0-9/+]{40,}={0,2}`)
+
+	mu      sync.RWMutex
+	secrets []string // Registered secrets, longest first
+)
+
+// Register adds exact values to mask wherever they appear, such as the secret
+// key and session token a downloader was given. This also covers credentials of
+// S3-compatible services that do not look like AWS ones.
+func Register(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, v := range values {
+		if len(v) < minSecretLength || contains(secrets, v) {
+			continue
+		}
+		secrets = append(secrets, v)
+	}
+	// Longer secrets first, so that one containing another is masked whole
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// contains reports whether list holds v
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// String masks the registered secrets and anything that looks like AWS
+// credentials in s. Access key IDs keep their 4-character type prefix.
+// Secret keys are recognized as 40 base64 characters mixing upper and lower
+// case letters and digits, and session tokens as 100 or more of them, so that
+// ordinary words and hex digests such as commit IDs are kept.
+func String(s string) string {
+	mu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	mu.RUnlock()
+
+	s = accessKeyID.ReplaceAllStringFunc(s, func(id string) string {
+		return id[:4] + strings.Repeat("*", len(id)-4)
+	})
+	return base64Run.ReplaceAllStringFunc(s, func(run string) string {
+		n := len(strings.TrimRight(run, "="))
+		if (n == 40 || n >= 100) && mixed(run) {
+			return Mask
+		}
+		return run
+	})
+}
+
+// mixed reports whether s has upper and lower case letters and digits, as
+// random keys do
+func mixed(s string) bool {
+	var upper, lower, digit bool
+	for _, r := range s {
+		upper = upper || unicode.IsUpper(r)
+		lower = lower || unicode.IsLower(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	return upper && lower && digit
+}
+
+// writer masks credentials in everything written through it
+type writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a writer masking credentials with String before writing
+// to w. Each Write is masked on its own, so a secret split across two writes
+// is not caught; loggers writing whole lines at a time are safe.
+func NewWriter(w io.Writer) io.Writer {
+	return writer{w: w}
+}
+
+// Write writes the masked p and reports all of p as written on success
+func (w writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, String(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,114 @@
+// Package secrets keeps credentials in the operating system's keyring: the
+// Keychain on macOS, the Credential Manager on Windows and the Secret Service
+// (GNOME Keyring, KWallet) on Linux.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+)
+
+// service is the name secrets are filed under in the keyring
+const service = "s3downloader"
+
+// ErrNotFound is returned by Get for names without a stored secret
+var ErrNotFound = errors.New("secret not found")
+
+// Store keeps secrets by name
+type Store interface {
+	Get(name string) (string, error)
+	Set(name, secret string) error
+	Delete(name string) error // Deleting a missing secret returns ErrNotFound
+}
+
+// Keyring stores secrets in the operating system's keyring. Its methods fail when
+// there is none, such as on a Linux machine without a Secret Service.
+type Keyring struct{}
+
+// Get returns the secret stored under name
+func (Keyring) Get(name string) (string, error) {
+	secret, err := keyring.Get(service, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret '%s' from the system keyring: %w", name, keyringError(err))
+	}
+	return secret, nil
+}
+
+// Set stores secret under name, replacing any previous one
+func (Keyring) Set(name, secret string) error {
+	if err := keyring.Set(service, name, secret); err != nil {
+		return fmt.Errorf("failed to store secret '%s' in the system keyring: %w", name, keyringError(err))
+	}
+	return nil
+}
+
+// Delete removes the secret stored under name
+func (Keyring) Delete(name string) error {
+	if err := keyring.Delete(service, name); err != nil {
+		return fmt.Errorf("failed to delete secret '%s' from the system keyring: %w", name, keyringError(err))
+	}
+	return nil
+}
+
+// keyringError translates the keyring's error for a missing secret to ErrNotFound
+func keyringError(err error) error {
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Memory is a Store kept in memory, for tests. When Err is set every
+// operation fails with it, like a keyring that is not available.
+type Memory struct {
+	mu      sync.Mutex
+	secrets map[string]string
+	Err     error
+}
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{secrets: make(map[string]string)}
+}
+
+// Get returns the secret stored under name
+func (m *Memory) Get(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return "", m.Err
+	}
+	secret, ok := m.secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+// Set stores secret under name, replacing any previous one
+func (m *Memory) Set(name, secret string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	m.secrets[name] = secret
+	return nil
+}
+
+// Delete removes the secret stored under name
+func (m *Memory) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if _, ok := m.secrets[name]; !ok {
+		return ErrNotFound
+	}
+	delete(m.secrets, name)
+	return nil
+}
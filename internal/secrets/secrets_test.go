@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zalando/go-keyring"
+)
+
+// testStore runs the behavior every Store shares against s
+func testStore(t *testing.T, s Store) {
+	_, err := s.Get("prod")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, s.Delete("prod"), ErrNotFound)
+
+	assert.NoError(t, s.Set("prod", "s3cr3t"))
+	assert.NoError(t, s.Set("minio", "minio123"))
+	secret, err := s.Get("prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", secret)
+
+	assert.NoError(t, s.Set("prod", "rotated"))
+	secret, err = s.Get("prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "rotated", secret)
+
+	assert.NoError(t, s.Delete("prod"))
+	_, err = s.Get("prod")
+	assert.ErrorIs(t, err, ErrNotFound)
+	secret, err = s.Get("minio")
+	assert.NoError(t, err)
+	assert.Equal(t, "minio123", secret)
+}
+
+func TestMemory(t *testing.T) {
+	testStore(t, NewMemory())
+}
+
+func TestMemoryUnavailable(t *testing.T) {
+	unavailable := errors.New("no keyring")
+	m := NewMemory()
+	assert.NoError(t, m.Set("prod", "s3cr3t"))
+	m.Err = unavailable
+
+	_, err := m.Get("prod")
+	assert.ErrorIs(t, err, unavailable)
+	assert.ErrorIs(t, m.Set("prod", "other"), unavailable)
+	assert.ErrorIs(t, m.Delete("prod"), unavailable)
+}
+
+func TestKeyring(t *testing.T) {
+	keyring.MockInit() // Replaces the system keyring for the rest of this package's tests
+	testStore(t, Keyring{})
+}
+
+func TestKeyringUnavailable(t *testing.T) {
+	unavailable := errors.New("no secret service")
+	keyring.MockInitWithError(unavailable)
+	defer keyring.MockInit()
+
+	err := Keyring{}.Set("prod", "s3cr3t")
+	assert.ErrorIs(t, err, unavailable)
+	assert.EqualError(t, err, "failed to store secret 'prod' in the system keyring: no secret service")
+	_, err = Keyring{}.Get("prod")
+	assert.ErrorIs(t, err, unavailable)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
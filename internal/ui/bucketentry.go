@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// BucketEntry is the bucket field, with a drop-down of recently used buckets.
+// Pasted text is offered to OnPaste first, so that an S3 URI can fill in the
+// bucket and prefix instead of being inserted.
+type BucketEntry struct {
+	widget.SelectEntry
+	OnPaste func(text string) bool // Reports whether it handled the text
+}
+
+// NewBucketEntry creates an empty bucket field
+func NewBucketEntry() *BucketEntry {
+	e := &BucketEntry{}
+	e.ExtendBaseWidget(e)
+	e.Wrapping = fyne.TextTruncate
+	return e
+}
+
+// TypedShortcut handles pasting before the entry's default shortcuts
+func (e *BucketEntry) TypedShortcut(shortcut fyne.Shortcut) {
+	if paste, ok := shortcut.(*fyne.ShortcutPaste); ok && e.OnPaste != nil && !e.Disabled() {
+		if e.OnPaste(paste.Clipboard.Content()) {
+			return
+		}
+	}
+	e.SelectEntry.TypedShortcut(shortcut)
+}
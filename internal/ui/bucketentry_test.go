@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasteS3URIIntoBucketEntry(t *testing.T) {
+	test.NewApp()
+	u := &UIManager{window: test.NewWindow(nil), components: NewComponents()}
+	u.components.BucketEntry.OnPaste = u.fillFromS3URI
+	clipboard := test.NewClipboard()
+	paste := func(text string) {
+		clipboard.SetContent(text)
+		u.components.BucketEntry.TypedShortcut(&fyne.ShortcutPaste{Clipboard: clipboard})
+	}
+
+	paste("s3://my-bucket/some/prefix/")
+	assert.Equal(t, "my-bucket", u.components.BucketEntry.Text)
+	assert.Equal(t, "some/prefix/", u.components.PrefixEntry.Text)
+
+	// Malformed URIs are reported and not inserted
+	paste("s3:///missing-bucket")
+	assert.Equal(t, "my-bucket", u.components.BucketEntry.Text)
+
+	// Regional URLs also fill in the region
+	paste("https://other-bucket.s3.us-east-2.amazonaws.com/data/")
+	assert.Equal(t, "other-bucket", u.components.BucketEntry.Text)
+	assert.Equal(t, "data/", u.components.PrefixEntry.Text)
+	assert.Equal(t, "us-east-2", u.components.AwsRegionEntry.Text)
+
+	// Anything else is pasted as usual
+	u.components.BucketEntry.SetText("")
+	paste("plain-bucket")
+	assert.Equal(t, "plain-bucket", u.components.BucketEntry.Text)
+	assert.Equal(t, "data/", u.components.PrefixEntry.Text)
+}
+
+func TestBucketEntrySplitsURIs(t *testing.T) {
+	test.NewApp()
+	u := &UIManager{window: test.NewWindow(nil), components: NewComponents()}
+	u.components.BucketEntry.OnChanged = u.splitBucketURI
+
+	// A URI being typed stays as it is until it names a bucket
+	test.Type(u.components.BucketEntry, "s3://")
+	assert.Equal(t, "s3://", u.components.BucketEntry.Text)
+
+	u.components.BucketEntry.SetText("https://s3.eu-west-3.amazonaws.com/my-bucket/logs/2024/")
+	assert.Equal(t, "my-bucket", u.components.BucketEntry.Text)
+	assert.Equal(t, "logs/2024/", u.components.PrefixEntry.Text)
+	assert.Equal(t, "eu-west-3", u.components.AwsRegionEntry.Text)
+
+	u.components.BucketEntry.SetText("s3://plain/x")
+	assert.Equal(t, "plain", u.components.BucketEntry.Text)
+	assert.Equal(t, "x", u.components.PrefixEntry.Text)
+	assert.Equal(t, "eu-west-3", u.components.AwsRegionEntry.Text, "URIs without a region keep it")
+}
+
+func TestRecentBucketFillsRegion(t *testing.T) {
+	test.NewApp()
+	u := &UIManager{window: test.NewWindow(nil), components: NewComponents(), recent: NewRecentBuckets(maxRecentBuckets)}
+	u.components.BucketEntry.OnChanged = u.bucketChanged
+
+	u.components.BucketEntry.SetText("logs")
+	u.components.AwsRegionEntry.SetText("us-west-2")
+	u.rememberBucket("logs")
+	assert.Equal(t, []string{"logs"}, loadRecentBuckets(fyne.CurrentApp().Preferences()).Names())
+
+	u.components.AwsRegionEntry.SetText("eu-west-1")
+	u.components.BucketEntry.SetText("other")
+	assert.Equal(t, "eu-west-1", u.components.AwsRegionEntry.Text, "unknown buckets keep the region")
+
+	u.components.BucketEntry.SetText("logs")
+	assert.Equal(t, "us-west-2", u.components.AwsRegionEntry.Text)
+}
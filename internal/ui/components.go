@@ -1,50 +1,198 @@
 package ui
 
 import (
+	"s3downloader/internal/aws"
+
 	"fyne.io/fyne/v2/widget"
+	"github.com/aws/aws-sdk-go/service/s3"
 )
 
 // Components struct holds all the UI components for the application
 type Components struct {
-	BucketEntry       *widget.Entry
-	PrefixEntry       *widget.Entry
-	FilePathEntry     *widget.Entry
-	AwsAccessKeyEntry *widget.Entry
-	AwsSecretKeyEntry *widget.Entry
-	AwsRegionEntry    *widget.Entry
-	ShowSecretCheck   *widget.Check
-	OverwriteCheck    *widget.Check
-	DownloadButton    *widget.Button
-	StopButton        *widget.Button
-	StatusLabel       *widget.Label
-	ProgressBar       *widget.ProgressBar
+	BucketEntry             *BucketEntry
+	ProfileSelect           *widget.Select
+	SaveProfileButton       *widget.Button
+	DeleteProfileButton     *widget.Button
+	StoreSecretCheck        *widget.Check
+	PrefixEntry             *widget.Entry
+	IncludeEntry            *widget.Entry
+	ExcludeEntry            *widget.Entry
+	TagFilterEntry          *widget.Entry
+	ContentTypeEntry        *widget.Entry
+	MaxRecentEntry          *widget.Entry
+	FilePathEntry           *widget.Entry
+	AwsAccessKeyEntry       *widget.Entry
+	AwsSecretKeyEntry       *widget.Entry
+	AwsTokenEntry           *widget.Entry
+	AwsRegionEntry          *widget.SelectEntry
+	AwsProfileEntry         *widget.Entry
+	EndpointEntry           *widget.Entry
+	MaxSpeedEntry           *widget.Entry
+	ShowSecretCheck         *widget.Check
+	AnonymousCheck          *widget.Check
+	OverwriteCheck          *widget.Check
+	FlattenCheck            *widget.Check
+	StripPrefixCheck        *widget.Check
+	FolderMarkersCheck      *widget.Check
+	SkipUnchangedCheck      *widget.Check
+	IndexCheck              *widget.Check
+	ResumeCheck             *widget.Check
+	VersionsCheck           *widget.Check
+	PathStyleCheck          *widget.Check
+	AccelerateCheck         *widget.Check
+	CACertEntry             *widget.Entry
+	InsecureSkipVerifyCheck *widget.Check
+	RequesterPaysCheck      *widget.Check
+	VerifyCheck             *widget.Check
+	DecompressGzipCheck     *widget.Check
+	StripGzipSuffixCheck    *widget.Check
+	WriteMetadataCheck      *widget.Check
+	HeadBeforeDownloadCheck *widget.Check
+	CleanPartialsCheck      *widget.Check
+	FailFastCheck           *widget.Check
+	MirrorCheck             *widget.Check
+	DownloadArchivedCheck   *widget.Check
+	RestoreArchivedCheck    *widget.Check
+	RestoreTierSelect       *widget.Select
+	RestoreDaysEntry        *widget.Entry
+	ReportPathEntry         *widget.Entry
+	ManifestPathEntry       *widget.Entry
+	StateFileEntry          *widget.Entry
+	PerformanceSelect       *widget.Select
+	AdaptiveCheck           *widget.Check
+	NotifyCheck             *widget.Check
+	LoadKeysButton          *widget.Button
+	ClearKeysButton         *widget.Button
+	KeysLabel               *widget.Label
+	DestinationSizeLabel    *widget.Label
+	ValidateButton          *widget.Button
+	DownloadButton          *widget.Button
+	QueueButton             *widget.Button
+	StopButton              *widget.Button
+	RetryButton             *widget.Button
+	ErrorsButton            *widget.Button
+	PauseButton             *widget.Button
+	StatusLabel             *widget.Label
+	ProgressBar             *widget.ProgressBar
+	ElapsedLabel            *widget.Label
+	EtaLabel                *widget.Label
+	LogPanel                *LogPanel
+	CopyLogButton           *widget.Button
+	ClearLogButton          *widget.Button
+	QueueList               *widget.List
+	QueueLabel              *widget.Label
+	QueueProgressBar        *widget.ProgressBar
+	CancelJobButton         *widget.Button
 }
 
 // NewComponents initializes all the UI components
 func NewComponents() *Components {
 	c := &Components{
-		BucketEntry:       widget.NewEntry(),
-		PrefixEntry:       widget.NewEntry(),
-		FilePathEntry:     widget.NewEntry(),
-		AwsAccessKeyEntry: widget.NewEntry(),
-		AwsSecretKeyEntry: widget.NewPasswordEntry(),
-		AwsRegionEntry:    widget.NewEntry(),
-		ShowSecretCheck:   widget.NewCheck("Show Secret Key", nil),
-		OverwriteCheck:    widget.NewCheck("Overwrite existing files", nil),
-		DownloadButton:    widget.NewButton("Download", nil),
-		StopButton:        widget.NewButton("Stop", nil),
-		StatusLabel:       widget.NewLabel("Ready to download"),
-		ProgressBar:       widget.NewProgressBar(),
+		BucketEntry:             NewBucketEntry(),
+		ProfileSelect:           widget.NewSelect(nil, nil),
+		SaveProfileButton:       widget.NewButton("Save", nil),
+		DeleteProfileButton:     widget.NewButton("Delete", nil),
+		StoreSecretCheck:        widget.NewCheck("Store the secret key in the system keyring", nil),
+		PrefixEntry:             widget.NewEntry(),
+		IncludeEntry:            widget.NewEntry(),
+		ExcludeEntry:            widget.NewEntry(),
+		TagFilterEntry:          widget.NewEntry(),
+		ContentTypeEntry:        widget.NewEntry(),
+		MaxRecentEntry:          widget.NewEntry(),
+		FilePathEntry:           widget.NewEntry(),
+		AwsAccessKeyEntry:       widget.NewEntry(),
+		AwsSecretKeyEntry:       widget.NewPasswordEntry(),
+		AwsTokenEntry:           widget.NewPasswordEntry(),
+		AwsRegionEntry:          widget.NewSelectEntry(aws.Regions),
+		AwsProfileEntry:         widget.NewEntry(),
+		EndpointEntry:           widget.NewEntry(),
+		MaxSpeedEntry:           widget.NewEntry(),
+		ShowSecretCheck:         widget.NewCheck("Show Secret Key", nil),
+		AnonymousCheck:          widget.NewCheck("Anonymous (public bucket)", nil),
+		OverwriteCheck:          widget.NewCheck("Overwrite existing files", nil),
+		FlattenCheck:            widget.NewCheck("Flatten folders (save all files directly in the download path)", nil),
+		StripPrefixCheck:        widget.NewCheck("Save files relative to the prefix", nil),
+		FolderMarkersCheck:      widget.NewCheck("Create empty folders for folder placeholder objects", nil),
+		SkipUnchangedCheck:      widget.NewCheck("Re-download files that changed in S3 (compare ETag)", nil),
+		IndexCheck:              widget.NewCheck("Generate index.html", nil),
+		ResumeCheck:             widget.NewCheck("Resume partial downloads", nil),
+		VersionsCheck:           widget.NewCheck("Include previous versions (saved with their version ID)", nil),
+		PathStyleCheck:          widget.NewCheck("Use path-style addressing", nil),
+		AccelerateCheck:         widget.NewCheck("Use S3 Transfer Acceleration", nil),
+		CACertEntry:             widget.NewEntry(),
+		InsecureSkipVerifyCheck: widget.NewCheck("Skip TLS certificate verification (insecure)", nil),
+		RequesterPaysCheck:      widget.NewCheck("Requester pays (charges billed to your account)", nil),
+		VerifyCheck:             widget.NewCheck("Verify checksums after download", nil),
+		DecompressGzipCheck:     widget.NewCheck("Decompress gzip-encoded objects", nil),
+		StripGzipSuffixCheck:    widget.NewCheck("Remove .gz from decompressed file names", nil),
+		WriteMetadataCheck:      widget.NewCheck("Save object metadata as .meta.json files", nil),
+		HeadBeforeDownloadCheck: widget.NewCheck("Confirm object sizes before downloading", nil),
+		CleanPartialsCheck:      widget.NewCheck("Remove stale partial files before downloading", nil),
+		FailFastCheck:           widget.NewCheck("Stop on first error", nil),
+		MirrorCheck:             widget.NewCheck("Mirror: delete local files no longer in the bucket", nil),
+		DownloadArchivedCheck:   widget.NewCheck("Download archived objects (Glacier, Deep Archive)", nil),
+		RestoreArchivedCheck:    widget.NewCheck("Restore archived objects before downloading (can take hours)", nil),
+		RestoreTierSelect:       widget.NewSelect([]string{s3.TierStandard, s3.TierBulk, s3.TierExpedited}, nil),
+		RestoreDaysEntry:        widget.NewEntry(),
+		ReportPathEntry:         widget.NewEntry(),
+		ManifestPathEntry:       widget.NewEntry(),
+		StateFileEntry:          widget.NewEntry(),
+		PerformanceSelect:       widget.NewSelect(aws.PerformancePresets, nil),
+		AdaptiveCheck:           widget.NewCheck("Adapt parallel downloads to measured throughput", nil),
+		NotifyCheck:             widget.NewCheck("Send a notification when a download finishes", nil),
+		LoadKeysButton:          widget.NewButton("Load keys file", nil),
+		ClearKeysButton:         widget.NewButton("Clear keys", nil),
+		KeysLabel:               widget.NewLabel("No keys file loaded"),
+		DestinationSizeLabel:    widget.NewLabel(""),
+		ValidateButton:          widget.NewButton("Validate", nil),
+		DownloadButton:          widget.NewButton("Download", nil),
+		QueueButton:             widget.NewButton("Add to Queue", nil),
+		StopButton:              widget.NewButton("Stop", nil),
+		RetryButton:             widget.NewButton("Retry failed", nil),
+		ErrorsButton:            widget.NewButton("View errors", nil),
+		PauseButton:             widget.NewButton("Pause", nil),
+		StatusLabel:             widget.NewLabel("Ready to download"),
+		ProgressBar:             widget.NewProgressBar(),
+		ElapsedLabel:            widget.NewLabel("Elapsed: " + idleTime),
+		EtaLabel:                widget.NewLabel("Remaining: " + idleTime),
+		LogPanel:                NewLogPanel(maxLogLines),
+		CopyLogButton:           widget.NewButton("Copy log", nil),
+		ClearLogButton:          widget.NewButton("Clear log", nil),
+		QueueList:               widget.NewList(nil, nil, nil),
+		QueueLabel:              widget.NewLabel("Queue is empty"),
+		QueueProgressBar:        widget.NewProgressBar(),
+		CancelJobButton:         widget.NewButton("Cancel job", nil),
 	}
 
+	c.ProfileSelect.PlaceHolder = "Select a saved connection profile"
 	c.BucketEntry.SetPlaceHolder("Bucket Name")
 	c.PrefixEntry.SetPlaceHolder("Prefix (optional)")
+	c.IncludeEntry.SetPlaceHolder("Only keys matching, comma-separated (e.g. *.json)")
+	c.ExcludeEntry.SetPlaceHolder("Skip keys matching, comma-separated (e.g. logs/*)")
+	c.TagFilterEntry.SetPlaceHolder("Only objects with these tags, comma-separated (e.g. environment=prod)")
+	c.ContentTypeEntry.SetPlaceHolder("Only objects of these types, comma-separated (e.g. image/)")
+	c.MaxRecentEntry.SetPlaceHolder("All files")
 	c.FilePathEntry.SetPlaceHolder("Download Path")
 	c.AwsAccessKeyEntry.SetPlaceHolder("AWS Access Key (optional)")
 	c.AwsSecretKeyEntry.SetPlaceHolder("AWS Secret Key (optional)")
+	c.AwsTokenEntry.SetPlaceHolder("AWS Session Token (optional, for temporary credentials)")
+	c.AwsProfileEntry.SetPlaceHolder("AWS Profile (optional, used when no keys are given)")
+	c.EndpointEntry.SetPlaceHolder("Endpoint URL for S3-compatible services (optional)")
+	c.MaxSpeedEntry.SetPlaceHolder("Unlimited")
 	c.AwsRegionEntry.Text = "eu-west-1"
+	c.PerformanceSelect.SetSelected(aws.PresetBalanced)
+	c.RestoreTierSelect.SetSelected(s3.TierStandard)
+	c.RestoreDaysEntry.SetPlaceHolder("Days to keep restored copies (default 1)")
+	c.CACertEntry.SetPlaceHolder("PEM file of a private CA to trust (optional)")
+	c.ReportPathEntry.SetPlaceHolder("File to write a JSON report of the run to (optional)")
+	c.ManifestPathEntry.SetPlaceHolder("File to write sha256sum-style checksums of the files to (optional)")
+	c.StateFileEntry.SetPlaceHolder("File recording finished objects, to continue an interrupted job (optional)")
 	c.ProgressBar.Hide()
 	c.StopButton.Hide()
+	c.PauseButton.Hide()
+	c.RetryButton.Hide()
+	c.ErrorsButton.Hide()
+	c.ClearKeysButton.Hide()
 
 	return c
 }
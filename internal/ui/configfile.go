@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"s3downloader/internal/config"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// applyConfigFile fills the form with the job described by a config file
+func (u *UIManager) applyConfigFile(f config.File) {
+	o := f.Download
+	// Setting the bucket may fill in its last region, so the region comes after it
+	u.components.BucketEntry.SetText(f.Bucket)
+	u.components.PrefixEntry.SetText(f.Prefix)
+	u.components.FilePathEntry.SetText(f.Path)
+	if f.Region != "" {
+		u.components.AwsRegionEntry.SetText(f.Region)
+	}
+	u.components.OverwriteCheck.SetChecked(f.Overwrite)
+	u.components.AwsAccessKeyEntry.SetText(f.Credentials.AccessKey)
+	u.components.AwsSecretKeyEntry.SetText(f.Credentials.SecretKey)
+	u.components.AwsTokenEntry.SetText(f.Credentials.SessionToken)
+	u.components.AwsProfileEntry.SetText(f.Credentials.Profile)
+	u.components.AnonymousCheck.SetChecked(f.Credentials.Anonymous)
+	if o.Performance != "" {
+		u.components.PerformanceSelect.SetSelected(o.Performance)
+	}
+	u.components.EndpointEntry.SetText(o.Endpoint)
+	u.components.PathStyleCheck.SetChecked(o.PathStyle)
+	u.components.AccelerateCheck.SetChecked(o.Accelerate)
+	u.components.CACertEntry.SetText(o.CACert)
+	u.components.InsecureSkipVerifyCheck.SetChecked(o.InsecureSkipVerify)
+	u.components.RequesterPaysCheck.SetChecked(o.RequesterPays)
+	maxSpeed := ""
+	if o.MaxSpeed > 0 {
+		maxSpeed = strconv.FormatFloat(float64(o.MaxSpeed), 'f', -1, 64)
+	}
+	u.components.MaxSpeedEntry.SetText(maxSpeed)
+	u.components.IncludeEntry.SetText(strings.Join(o.Include, ", "))
+	u.components.ExcludeEntry.SetText(strings.Join(o.Exclude, ", "))
+	u.components.SkipUnchangedCheck.SetChecked(o.SkipUnchanged)
+	u.components.ResumeCheck.SetChecked(o.Resume)
+	u.components.VerifyCheck.SetChecked(o.Verify)
+	u.components.FlattenCheck.SetChecked(o.Flatten)
+	u.components.StripPrefixCheck.SetChecked(o.StripPrefix)
+	u.components.VersionsCheck.SetChecked(o.Versions)
+	u.components.DecompressGzipCheck.SetChecked(o.DecompressGzip)
+	u.components.FailFastCheck.SetChecked(o.FailFast)
+	u.components.MirrorCheck.SetChecked(o.Mirror)
+	u.components.ReportPathEntry.SetText(o.ReportPath)
+	u.components.ManifestPathEntry.SetText(o.ManifestPath)
+	u.components.StateFileEntry.SetText(o.StateFile)
+}
+
+// applyEnvironment fills the form with the settings given by environment
+// variables, see config.FromEnv; entries without a variable keep their value
+func (u *UIManager) applyEnvironment() {
+	f := config.FromEnv()
+	for _, setting := range []struct {
+		entry interface{ SetText(string) }
+		value string
+	}{
+		// Setting the bucket may fill in its last region, so the region comes after it
+		{u.components.BucketEntry, f.Bucket},
+		{u.components.PrefixEntry, f.Prefix},
+		{u.components.FilePathEntry, f.Path},
+		{u.components.AwsRegionEntry, f.Region},
+		{u.components.AwsAccessKeyEntry, f.Credentials.AccessKey},
+		{u.components.AwsSecretKeyEntry, f.Credentials.SecretKey},
+		{u.components.AwsTokenEntry, f.Credentials.SessionToken},
+	} {
+		if setting.value != "" {
+			setting.entry.SetText(setting.value)
+		}
+	}
+}
+
+// LoadConfigFile fills the form from a YAML or JSON config file, the same
+// format the command-line downloader reads with -config
+func (u *UIManager) LoadConfigFile() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, u.window)
+			return
+		}
+		if reader == nil {
+			return // Dialog canceled
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to read config '%s': %w", reader.URI().Name(), err), u.window)
+			return
+		}
+		f, warnings, err := config.Parse(data)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("invalid config '%s': %w", reader.URI().Name(), err), u.window)
+			return
+		}
+		u.applyConfigFile(f)
+		u.logger.Info("user action", "action", "load config", "file", reader.URI().Name(), "warnings", len(warnings))
+		if len(warnings) > 0 {
+			dialog.ShowInformation("Config Loaded With Warnings", strings.Join(warnings, "\n"), u.window)
+		}
+	}, u.window)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml", ".json"}))
+	open.Show()
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"testing"
+
+	"s3downloader/internal/config"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyConfigFile(t *testing.T) {
+	test.NewApp()
+	u := &UIManager{components: NewComponents()}
+	u.components.AwsRegionEntry.SetText("us-east-1")
+	u.components.MirrorCheck.SetChecked(true)
+
+	u.applyConfigFile(config.File{
+		Bucket:      "app-logs",
+		Prefix:      "production/",
+		Path:        "/var/backups",
+		Credentials: config.Credentials{Profile: "backup"},
+		Download: config.Options{
+			Performance: "Aggressive",
+			MaxSpeed:    12.5,
+			Include:     []string{"*.gz", "*.log"},
+			Verify:      true,
+		},
+	})
+
+	c := u.components
+	assert.Equal(t, "app-logs", c.BucketEntry.Text)
+	assert.Equal(t, "production/", c.PrefixEntry.Text)
+	assert.Equal(t, "/var/backups", c.FilePathEntry.Text)
+	assert.Equal(t, "us-east-1", c.AwsRegionEntry.Text, "a file without a region keeps the form's")
+	assert.Equal(t, "backup", c.AwsProfileEntry.Text)
+	assert.Equal(t, "Aggressive", c.PerformanceSelect.Selected)
+	assert.Equal(t, "12.5", c.MaxSpeedEntry.Text)
+	assert.Equal(t, "*.gz, *.log", c.IncludeEntry.Text)
+	assert.True(t, c.VerifyCheck.Checked)
+	assert.False(t, c.MirrorCheck.Checked, "options the file leaves out are turned off")
+}
+
+func TestApplyEnvironment(t *testing.T) {
+	test.NewApp()
+	u := &UIManager{components: NewComponents()}
+	u.components.PrefixEntry.SetText("saved/")
+	t.Setenv(config.EnvBucket, "env-bucket")
+	t.Setenv(config.EnvPrefix, "")
+	t.Setenv(config.EnvRegion, "ap-south-1")
+	t.Setenv(config.EnvSecretKey, "SECRET")
+
+	u.applyEnvironment()
+
+	c := u.components
+	assert.Equal(t, "env-bucket", c.BucketEntry.Text)
+	assert.Equal(t, "saved/", c.PrefixEntry.Text, "an empty variable keeps the entry's value")
+	assert.Equal(t, "ap-south-1", c.AwsRegionEntry.Text)
+	assert.Equal(t, "SECRET", c.AwsSecretKeyEntry.Text)
+}
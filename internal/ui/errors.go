@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"fmt"
+
+	"s3downloader/internal/aws"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// formatFailures returns a heading and one "key: message" line per failure.
+// total is the run's error count, which may exceed the failures remembered.
+func formatFailures(failures []aws.FailedObject, total int64) (string, []string) {
+	lines := make([]string, len(failures))
+	for i, f := range failures {
+		lines[i] = fmt.Sprintf("%s: %v", f.Key, f.Err)
+	}
+	heading := fmt.Sprintf("%d objects failed", len(failures))
+	if total > int64(len(failures)) {
+		heading = fmt.Sprintf("%d objects failed, showing the first %d", total, len(failures))
+	}
+	return heading, lines
+}
+
+// ShowErrors lists every object that failed in the last run
+func (u *UIManager) ShowErrors() {
+	if u.downloader == nil {
+		return
+	}
+	heading, lines := formatFailures(u.downloader.Failures(), u.downloader.Progress().ErrorCount)
+	list := widget.NewList(
+		func() int { return len(lines) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, item fyne.CanvasObject) { item.(*widget.Label).SetText(lines[id]) },
+	)
+	copyButton := widget.NewButton("Copy all", func() {
+		text := heading
+		for _, line := range lines {
+			text += "\n" + line
+		}
+		u.window.Clipboard().SetContent(text)
+	})
+	content := container.NewBorder(widget.NewLabel(heading), copyButton, nil, nil,
+		container.NewGridWrap(fyne.NewSize(700, 400), list))
+	dialog.ShowCustom("Errors", "Close", content, u.window)
+}
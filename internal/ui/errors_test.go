@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"s3downloader/internal/aws"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatFailures(t *testing.T) {
+	failures := []aws.FailedObject{
+		{Key: "logs/a.gz", Err: errors.New("access denied")},
+		{Key: "data/b.csv", Err: errors.New("checksum mismatch")},
+	}
+
+	heading, lines := formatFailures(failures, 2)
+	assert.Equal(t, "2 objects failed", heading)
+	assert.Equal(t, []string{"logs/a.gz: access denied", "data/b.csv: checksum mismatch"}, lines)
+
+	heading, _ = formatFailures(failures, 30)
+	assert.Equal(t, "30 objects failed, showing the first 2", heading)
+
+	heading, lines = formatFailures(nil, 0)
+	assert.Equal(t, "0 objects failed", heading)
+	assert.Empty(t, lines)
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"s3downloader/internal/aws"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// fieldHelp explains an input field of the form
+type fieldHelp struct {
+	Field string // Name of the field in Components
+	Label string // Name shown in the help dialog
+	Text  string
+}
+
+// helpIntro opens the help dialog
+const helpIntro = `Fill in at least the bucket and the download path, then press Download.
+
+Paste, type or drop an S3 URI into the bucket field to fill in the bucket and prefix at once. s3://bucket/prefix/, AWS console links and https:// bucket URLs all work, and URLs naming a region set it as well.`
+
+// performanceHelp tells apart the two settings of the presets: files downloaded at
+// once, and parts of one large file downloaded at once within each of those
+func performanceHelp() string {
+	presets := make([]string, len(aws.PerformancePresets))
+	for i, name := range aws.PerformancePresets {
+		cfg := aws.PresetConfig(name)
+		presets[i] = fmt.Sprintf("%s %d × %d", name, cfg.MaxWorkers, cfg.Concurrency)
+	}
+	return "Files downloaded at once × parts of each large file downloaded at once: " + strings.Join(presets, ", ") + "."
+}
+
+// helpTexts documents every input field in the order of the form. It backs both
+// the inline hints and the help dialog, so field descriptions live only here.
+var helpTexts = []fieldHelp{
+	{"ProfileSelect", "Connection Profile", "Fills in a saved set of connection settings."},
+	{"StoreSecretCheck", "Store secret key", "Keep the secret key in the system keyring when saving a profile. Without a keyring it is not stored."},
+	{"BucketEntry", "Bucket Name", "The bucket to download from. Paste an s3:// URI to fill in the prefix too."},
+	{"PrefixEntry", "Prefix", "Only download keys starting with this, like a folder path such as logs/2024/. Leave empty for the whole bucket."},
+	{"IncludeEntry", "Include", "Only download keys matching one of these comma-separated patterns."},
+	{"ExcludeEntry", "Exclude", "Skip keys matching any of these comma-separated patterns."},
+	{"TagFilterEntry", "Tags", "Only download objects carrying all of these key=value tags. Each object costs an extra request."},
+	{"ContentTypeEntry", "Content types", "Only download objects whose Content-Type starts with one of these, such as image/. Objects without a known extension cost an extra request."},
+	{"MaxRecentEntry", "Newest Files Only", "Only download this many of the most recently modified objects."},
+	{"FilePathEntry", "Download Path", "Local folder the files are saved in."},
+	{"FlattenCheck", "Flatten folders", "Save every file directly in the download path under its base name."},
+	{"StripPrefixCheck", "Relative to the prefix", "Leave the prefix out of the local paths."},
+	{"FolderMarkersCheck", "Empty folders", "Create local folders for zero-byte folder placeholder objects."},
+	{"OverwriteCheck", "Overwrite", "Download objects again even if the local file exists. Otherwise existing files are skipped."},
+	{"SkipUnchangedCheck", "Compare ETag", "Only skip existing files whose content matches the object."},
+	{"IndexCheck", "index.html", "Write an index.html listing the downloaded files."},
+	{"ResumeCheck", "Resume", "Continue partially downloaded files instead of starting over."},
+	{"VersionsCheck", "Previous versions", "Also download non-current versions, named with their version ID."},
+	{"PerformanceSelect", "Performance", performanceHelp()},
+	{"AdaptiveCheck", "Adaptive", "Tune the number of files downloaded at once to the measured throughput. The parts per file stay as the preset sets them."},
+	{"MaxSpeedEntry", "Max speed", "Limit the download speed in MB/s, or per second with a unit such as 512KiB or 2MB. Leave empty for no limit."},
+	{"AnonymousCheck", "Anonymous", "Send requests without credentials, for public buckets and datasets. Buckets that are not public refuse them."},
+	{"AwsAccessKeyEntry", "AWS Access Key", "Optional. Without keys, the AWS profile, environment or IAM role of this machine is used."},
+	{"AwsSecretKeyEntry", "AWS Secret Key", "Secret belonging to the access key. It is never saved unless you store it in the system keyring with a profile."},
+	{"ShowSecretCheck", "Show secret", "Show the secret key and session token as plain text."},
+	{"AwsTokenEntry", "AWS Session Token", "Only needed with temporary credentials."},
+	{"AwsProfileEntry", "AWS Profile", "Profile from ~/.aws/credentials, used when no keys are given."},
+	{"AwsRegionEntry", "AWS Region", "Region of the bucket. S3-compatible services may use their own names."},
+	{"EndpointEntry", "Endpoint URL", "URL of an S3-compatible service such as MinIO. Leave empty for AWS."},
+	{"PathStyleCheck", "Path-style", "Address the bucket in the URL path. Needed for bucket names with dots."},
+	{"AccelerateCheck", "Transfer Acceleration", "Download through the nearest AWS edge location, for buckets with Transfer Acceleration enabled. Not for custom endpoints or path-style addressing."},
+	{"CACertEntry", "CA Certificate", "PEM file with the certificate of a private CA, for services whose TLS certificate it signed."},
+	{"InsecureSkipVerifyCheck", "Skip TLS verification", "Accept any TLS certificate. Anyone on the network can then read or alter the download; use it only for testing."},
+	{"RequesterPaysCheck", "Requester pays", "Accept paying the transfer costs of Requester Pays buckets."},
+	{"VerifyCheck", "Verify checksums", "Re-read each file and compare it with the object's checksum."},
+	{"DecompressGzipCheck", "Decompress gzip", "Decompress objects stored with gzip content encoding."},
+	{"StripGzipSuffixCheck", "Remove .gz", "Drop the .gz suffix from decompressed files."},
+	{"WriteMetadataCheck", "Metadata", "Save each object's metadata next to it as a .meta.json file."},
+	{"HeadBeforeDownloadCheck", "Confirm sizes", "Ask each object for its size before downloading it, for accurate progress when objects change after listing. Costs one request per object."},
+	{"CleanPartialsCheck", "Clean up", "Before downloading, delete the hidden partial files that interrupted downloads left in the download path more than a day ago. Other files are never touched."},
+	{"FailFastCheck", "Stop on first error", "Abort the whole download as soon as one object fails."},
+	{"MirrorCheck", "Mirror", "After a complete download, delete local files whose object no longer exists."},
+	{"DownloadArchivedCheck", "Archived objects", "Try Glacier and Deep Archive objects, which are skipped otherwise."},
+	{"RestoreArchivedCheck", "Restore archived", "Request restores of archived objects and wait for them."},
+	{"RestoreTierSelect", "Restore Tier", "Restore speed: Expedited is fastest and most expensive, Bulk the cheapest."},
+	{"RestoreDaysEntry", "Restore Days", "How long restored copies stay available."},
+	{"ReportPathEntry", "JSON Report", "Write the result of every object to this file."},
+	{"ManifestPathEntry", "SHA256 Manifest", "Write sha256sum-compatible checksums of the files to this file."},
+	{"StateFileEntry", "State File", "Record finished objects here to continue an interrupted download later."},
+	{"NotifyCheck", "Notification", "Send a system notification when a download finishes."},
+}
+
+// helpText returns the description of the named field
+func helpText(field string) string {
+	for _, h := range helpTexts {
+		if h.Field == field {
+			return h.Text
+		}
+	}
+	return ""
+}
+
+// withHint shows the field's description under a form item
+func withHint(item *widget.FormItem, field string) *widget.FormItem {
+	item.HintText = helpText(field)
+	return item
+}
+
+// ShowHelp opens a dialog describing every field
+func (u *UIManager) ShowHelp() {
+	var text strings.Builder
+	text.WriteString(helpIntro)
+	text.WriteString("\n\n")
+	for _, h := range helpTexts {
+		text.WriteString("**" + h.Label + "**: " + h.Text + "\n\n")
+	}
+	content := widget.NewRichTextFromMarkdown(text.String())
+	content.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(content)
+	scroll.SetMinSize(fyne.NewSize(600, 450))
+	dialog.ShowCustom("Help", "Close", scroll, u.window)
+}
@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelpCoversEveryInput(t *testing.T) {
+	inputs := map[string]bool{"*widget.Entry": true, "*widget.SelectEntry": true, "*ui.BucketEntry": true, "*widget.Check": true, "*widget.Select": true}
+	components := reflect.TypeOf(Components{})
+	for i := 0; i < components.NumField(); i++ {
+		field := components.Field(i)
+		if inputs[field.Type.String()] {
+			assert.NotEmpty(t, helpText(field.Name), "no help for %s", field.Name)
+		}
+	}
+	for _, h := range helpTexts {
+		_, ok := components.FieldByName(h.Field)
+		assert.True(t, ok, "help for unknown field %s", h.Field)
+	}
+}
+
+func TestPerformanceHelp(t *testing.T) {
+	assert.Equal(t, "Files downloaded at once × parts of each large file downloaded at once: Conservative 10 × 3, Balanced 100 × 10, Aggressive 200 × 16.", performanceHelp())
+}
@@ -0,0 +1,236 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"s3downloader/internal/progress"
+)
+
+// JobStatus is the state of a queued download job
+type JobStatus string
+
+// Job statuses, in the order a job normally goes through them
+const (
+	JobQueued   JobStatus = "queued"
+	JobRunning  JobStatus = "running"
+	JobDone     JobStatus = "done"
+	JobFailed   JobStatus = "failed"
+	JobCanceled JobStatus = "canceled"
+)
+
+// finished reports whether a job with status s will not change any more
+func (s JobStatus) finished() bool {
+	return s == JobDone || s == JobFailed || s == JobCanceled
+}
+
+// JobDownloader is the part of aws.Downloader that runs a job
+type JobDownloader interface {
+	ListAndDownloadObjects(ctx context.Context, bucket, prefix, downloadPath string, progressChan chan<- progress.Progress) error
+	DownloadObjects(ctx context.Context, bucket string, keys []string, downloadPath string, progressChan chan<- progress.Progress) ([]string, error)
+	Progress() progress.Progress
+}
+
+// Job is a download of a bucket prefix, or of the given keys, to a local path
+type Job struct {
+	ID           int
+	Bucket       string
+	Prefix       string
+	Keys         []string // Exact keys to download instead of listing the prefix
+	DownloadPath string
+	Status       JobStatus
+	Progress     progress.Progress
+	Missing      []string // Keys that were not found in the bucket
+	Err          error
+}
+
+// String describes the job's source and destination
+func (j Job) String() string {
+	if len(j.Keys) > 0 {
+		return fmt.Sprintf("%d keys from s3://%s to %s", len(j.Keys), j.Bucket, j.DownloadPath)
+	}
+	return fmt.Sprintf("s3://%s/%s to %s", j.Bucket, j.Prefix, j.DownloadPath)
+}
+
+// queuedJob is a job with the downloader and context it runs with
+type queuedJob struct {
+	Job
+	downloader JobDownloader
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// JobQueue runs download jobs one at a time in the order they were added. Each
+// job has its own downloader, context and progress channel.
+type JobQueue struct {
+	mu       sync.Mutex
+	jobs     []*queuedJob
+	nextID   int
+	running  bool
+	idle     sync.WaitGroup
+	onChange func(Job) // Called after a job's status or progress changed, outside of the lock
+}
+
+// NewJobQueue creates an empty queue; onChange may be nil
+func NewJobQueue(onChange func(Job)) *JobQueue {
+	if onChange == nil {
+		onChange = func(Job) {}
+	}
+	return &JobQueue{onChange: onChange}
+}
+
+// Add queues job to run with d and returns its ID. The queue starts working
+// through its jobs if it was idle.
+func (q *JobQueue) Add(job Job, d JobDownloader) int {
+	q.mu.Lock()
+	q.nextID++
+	job.ID = q.nextID
+	job.Status = JobQueued
+	ctx, cancel := context.WithCancel(context.Background())
+	q.jobs = append(q.jobs, &queuedJob{Job: job, downloader: d, ctx: ctx, cancel: cancel})
+	start := !q.running
+	if start {
+		q.running = true
+		q.idle.Add(1)
+	}
+	q.mu.Unlock()
+
+	q.onChange(job)
+	if start {
+		go q.run()
+	}
+	return job.ID
+}
+
+// Cancel stops the job with the given ID if it is running and drops it if it is
+// still waiting; finished jobs are left alone
+func (q *JobQueue) Cancel(id int) {
+	q.mu.Lock()
+	var changed *Job
+	for _, j := range q.jobs {
+		if j.ID != id {
+			continue
+		}
+		j.cancel()
+		if j.Status == JobQueued {
+			j.Status = JobCanceled
+			snapshot := j.Job
+			changed = &snapshot
+		}
+	}
+	q.mu.Unlock()
+
+	if changed != nil {
+		q.onChange(*changed)
+	}
+}
+
+// Status returns a snapshot of every job in the order they were added
+func (q *JobQueue) Status() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]Job, len(q.jobs))
+	for i, j := range q.jobs {
+		jobs[i] = j.Job
+	}
+	return jobs
+}
+
+// Wait blocks until the queue has run out of jobs
+func (q *JobQueue) Wait() {
+	q.idle.Wait()
+}
+
+// run works through the queued jobs until none are left
+func (q *JobQueue) run() {
+	defer q.idle.Done()
+	for {
+		q.mu.Lock()
+		var next *queuedJob
+		for _, j := range q.jobs {
+			if j.Status == JobQueued {
+				next = j
+				break
+			}
+		}
+		if next == nil {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		next.Status = JobRunning
+		snapshot := next.Job
+		q.mu.Unlock()
+
+		q.onChange(snapshot)
+		q.runJob(next)
+	}
+}
+
+// runJob downloads one job, recording its progress and outcome
+func (q *JobQueue) runJob(j *queuedJob) {
+	progressChan := make(chan progress.Progress, 100)
+	consumed := make(chan struct{})
+	go func() {
+		defer close(consumed)
+		for p := range progressChan {
+			q.update(j, func() { j.Progress = p })
+		}
+	}()
+
+	var (
+		missing []string
+		err     error
+	)
+	if len(j.Keys) > 0 {
+		missing, err = j.downloader.DownloadObjects(j.ctx, j.Bucket, j.Keys, j.DownloadPath, progressChan)
+	} else {
+		err = j.downloader.ListAndDownloadObjects(j.ctx, j.Bucket, j.Prefix, j.DownloadPath, progressChan)
+	}
+	close(progressChan)
+	<-consumed
+
+	// The channel may lag behind the last files, so finish with the downloader's own totals
+	final := j.downloader.Progress()
+	q.update(j, func() {
+		j.Progress, j.Missing, j.Err = final, missing, err
+		switch {
+		case j.ctx.Err() != nil:
+			j.Status = JobCanceled
+		case err != nil:
+			j.Status = JobFailed
+		default:
+			j.Status = JobDone
+		}
+	})
+	j.cancel()
+}
+
+// update changes j under the lock and reports the result
+func (q *JobQueue) update(j *queuedJob, change func()) {
+	q.mu.Lock()
+	change()
+	snapshot := j.Job
+	q.mu.Unlock()
+	q.onChange(snapshot)
+}
+
+// overallProgress returns how many jobs have finished and the share of the whole
+// queue that is done, counting the running job by its own progress
+func overallProgress(jobs []Job) (finished int, fraction float64) {
+	if len(jobs) == 0 {
+		return 0, 0
+	}
+	var done float64
+	for _, j := range jobs {
+		switch {
+		case j.Status.finished():
+			finished++
+			done++
+		case j.Status == JobRunning:
+			done += progressFraction(j.Progress)
+		}
+	}
+	return finished, done / float64(len(jobs))
+}
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"s3downloader/internal/progress"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockDownloader records the jobs it runs. Buckets named "fail" fail and buckets
+// named "block" wait until their job is canceled.
+type mockDownloader struct {
+	mu      sync.Mutex
+	calls   []string
+	started chan string
+	last    progress.Progress
+}
+
+func newMockDownloader() *mockDownloader {
+	return &mockDownloader{started: make(chan string, 10)}
+}
+
+func (m *mockDownloader) ListAndDownloadObjects(ctx context.Context, bucket, prefix, downloadPath string, progressChan chan<- progress.Progress) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, bucket+"/"+prefix)
+	m.mu.Unlock()
+	m.started <- bucket
+
+	progressChan <- progress.Progress{FilesFound: 2, FilesDownloaded: 1}
+	switch bucket {
+	case "fail":
+		return errors.New("access denied")
+	case "block":
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	m.mu.Lock()
+	m.last = progress.Progress{FilesFound: 2, FilesDownloaded: 2}
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *mockDownloader) DownloadObjects(ctx context.Context, bucket string, keys []string, downloadPath string, progressChan chan<- progress.Progress) ([]string, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, bucket+":keys")
+	m.mu.Unlock()
+	m.started <- bucket
+	return keys[1:], nil
+}
+
+func (m *mockDownloader) Progress() progress.Progress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
+
+func statuses(jobs []Job) []JobStatus {
+	var s []JobStatus
+	for _, j := range jobs {
+		s = append(s, j.Status)
+	}
+	return s
+}
+
+func TestJobQueueRunsJobsInOrder(t *testing.T) {
+	d := newMockDownloader()
+	var mu sync.Mutex
+	var finished []int
+	q := NewJobQueue(func(j Job) {
+		if j.Status.finished() {
+			mu.Lock()
+			finished = append(finished, j.ID)
+			mu.Unlock()
+		}
+	})
+
+	first := q.Add(Job{Bucket: "one", Prefix: "a/", DownloadPath: "out"}, d)
+	second := q.Add(Job{Bucket: "fail", DownloadPath: "out"}, d)
+	third := q.Add(Job{Bucket: "keys", Keys: []string{"x", "y"}, DownloadPath: "out"}, d)
+	q.Wait()
+
+	assert.Equal(t, []string{"one/a/", "fail/", "keys:keys"}, d.calls)
+	assert.Equal(t, []int{first, second, third}, finished)
+
+	jobs := q.Status()
+	assert.Equal(t, []JobStatus{JobDone, JobFailed, JobDone}, statuses(jobs))
+	assert.Equal(t, int64(2), jobs[0].Progress.FilesDownloaded)
+	assert.EqualError(t, jobs[1].Err, "access denied")
+	assert.Equal(t, []string{"y"}, jobs[2].Missing)
+
+	// An idle queue starts again for new jobs
+	q.Add(Job{Bucket: "four", DownloadPath: "out"}, d)
+	q.Wait()
+	assert.Equal(t, JobDone, q.Status()[3].Status)
+}
+
+func TestJobQueueCancel(t *testing.T) {
+	d := newMockDownloader()
+	q := NewJobQueue(nil)
+
+	running := q.Add(Job{Bucket: "block", DownloadPath: "out"}, d)
+	waiting := q.Add(Job{Bucket: "skipped", DownloadPath: "out"}, d)
+	last := q.Add(Job{Bucket: "last", DownloadPath: "out"}, d)
+	assert.Equal(t, "block", <-d.started)
+
+	// A waiting job is dropped, the running one stopped, and the rest carry on
+	q.Cancel(waiting)
+	assert.Equal(t, []JobStatus{JobRunning, JobCanceled, JobQueued}, statuses(q.Status()))
+	q.Cancel(running)
+	q.Wait()
+
+	assert.Equal(t, []JobStatus{JobCanceled, JobCanceled, JobDone}, statuses(q.Status()))
+	assert.Equal(t, []string{"block/", "last/"}, d.calls)
+
+	// Finished jobs cannot be canceled any more
+	q.Cancel(last)
+	assert.Equal(t, JobDone, q.Status()[2].Status)
+}
+
+func TestOverallProgress(t *testing.T) {
+	testCases := []struct {
+		name         string
+		jobs         []Job
+		wantFinished int
+		wantFraction float64
+	}{
+		{"Empty", nil, 0, 0},
+		{"All queued", []Job{{Status: JobQueued}, {Status: JobQueued}}, 0, 0},
+		{"Running half way", []Job{
+			{Status: JobDone},
+			{Status: JobRunning, Progress: progress.Progress{FilesFound: 2, TotalBytes: 50, TotalBytesExpected: 100}},
+			{Status: JobQueued},
+			{Status: JobQueued},
+		}, 1, 0.375},
+		{"Failed and canceled count as finished", []Job{{Status: JobFailed}, {Status: JobCanceled}}, 2, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			finished, fraction := overallProgress(tc.jobs)
+			assert.Equal(t, tc.wantFinished, finished)
+			assert.Equal(t, tc.wantFraction, fraction)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"s3downloader/internal/redact"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxLogLines is how many lines the activity log keeps; older ones are dropped
+const maxLogLines = 1000
+
+// LogPanel is a read-only activity log keeping the most recent lines, each
+// prefixed with the time it was logged. It implements aws.LogSink and is safe
+// for concurrent use.
+type LogPanel struct {
+	List *widget.List
+
+	mu    sync.Mutex
+	lines []string
+	limit int
+	now   func() time.Time
+	data  binding.StringList
+}
+
+// NewLogPanel creates an empty log keeping at most limit lines
+func NewLogPanel(limit int) *LogPanel {
+	p := &LogPanel{limit: limit, now: time.Now, data: binding.NewStringList()}
+	p.List = widget.NewListWithData(p.data,
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(item binding.DataItem, o fyne.CanvasObject) { o.(*widget.Label).Bind(item.(binding.String)) })
+	// Listeners run one after another on the binding's goroutine, which also refreshes
+	// the list, so the widget is never updated from the goroutines that log
+	p.data.AddListener(binding.NewDataListener(p.List.ScrollToBottom))
+	return p
+}
+
+// Log appends a timestamped line with any credentials masked, dropping the
+// oldest lines beyond the limit
+func (p *LogPanel) Log(line string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lines = append(p.lines, p.now().Format("15:04:05")+" "+redact.String(line))
+	if len(p.lines) > p.limit {
+		p.lines = append([]string(nil), p.lines[len(p.lines)-p.limit:]...)
+	}
+	p.data.Set(append([]string(nil), p.lines...)) // The binding keeps the slice it is given
+}
+
+// Text returns the retained lines, one per line
+func (p *LogPanel) Text() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return strings.Join(p.lines, "\n")
+}
+
+// Clear removes every line
+func (p *LogPanel) Clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lines = nil
+	p.data.Set(nil)
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// waitForRefresh blocks until the list has caught up with every change so far,
+// so that the next test's app does not race with it
+func waitForRefresh(p *LogPanel) {
+	done := make(chan struct{})
+	var once sync.Once
+	l := binding.NewDataListener(func() { once.Do(func() { close(done) }) })
+	p.data.AddListener(l) // Called once, after the listeners already queued
+	<-done
+	p.data.RemoveListener(l)
+}
+
+func TestLogPanel(t *testing.T) {
+	test.NewApp()
+	p := NewLogPanel(3)
+	t.Cleanup(func() { waitForRefresh(p) })
+	clock := time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local)
+	p.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	p.Log("first")
+	p.Log("second")
+	assert.Equal(t, "07:08:10 first\n07:08:11 second", p.Text())
+
+	// Only the newest lines are retained
+	for i := 3; i <= 5; i++ {
+		p.Log(fmt.Sprintf("line %d", i))
+	}
+	assert.Equal(t, "07:08:12 line 3\n07:08:13 line 4\n07:08:14 line 5", p.Text())
+	assert.Equal(t, 3, p.data.Length())
+
+	p.Clear()
+	assert.Empty(t, p.Text())
+	assert.Zero(t, p.data.Length())
+}
+
+// Workers log from their own goroutines; run with -race
+func TestLogPanelConcurrentLog(t *testing.T) {
+	test.NewApp()
+	p := NewLogPanel(maxLogLines)
+	t.Cleanup(func() { waitForRefresh(p) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				p.Log(fmt.Sprintf("worker %d line %d", worker, j))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, strings.Split(p.Text(), "\n"), 160)
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"s3downloader/internal/progress"
+
+	"fyne.io/fyne/v2"
+)
+
+// notifyPreference is the preferences key storing whether finished downloads send a notification
+const notifyPreference = "notify"
+
+// notificationMessage summarizes a finished download for a system notification
+func notificationMessage(p progress.Progress, err error, elapsed time.Duration) (title, content string) {
+	files := fmt.Sprintf("%d files downloaded (%.1f MB)", p.FilesDownloaded-p.FilesSkipped, float64(p.TotalBytes)/(1024*1024))
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "Download stopped", fmt.Sprintf("%s before stopping after %s", files, formatElapsedTime(elapsed))
+	case err != nil:
+		return "Download failed", fmt.Sprintf("%v\n%s in %s", err, files, formatElapsedTime(elapsed))
+	case p.FilesFound == 0:
+		// Not a failure, but nothing to celebrate either
+		return "No files matched", "Check that the bucket name and prefix are correct."
+	}
+	content = fmt.Sprintf("%s in %s", files, formatElapsedTime(elapsed))
+	if p.ErrorCount > 0 {
+		content += fmt.Sprintf("\n%d errors", p.ErrorCount)
+	}
+	return "Download complete", content
+}
+
+// notify sends a system notification about a finished download, unless the user turned them off
+func (u *UIManager) notify(p progress.Progress, err error, elapsed time.Duration) {
+	if !u.components.NotifyCheck.Checked {
+		return
+	}
+	title, content := notificationMessage(p, err, elapsed)
+	fyne.CurrentApp().SendNotification(fyne.NewNotification(title, content))
+}
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"s3downloader/internal/progress"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationMessage(t *testing.T) {
+	p := progress.Progress{FilesFound: 12, FilesDownloaded: 12, FilesSkipped: 2, TotalBytes: 3 * 1024 * 1024}
+	testCases := []struct {
+		name        string
+		p           progress.Progress
+		err         error
+		wantTitle   string
+		wantContent string
+	}{
+		{"Complete", p, nil, "Download complete", "10 files downloaded (3.0 MB) in 00:01:05"},
+		{"Complete with errors", progress.Progress{FilesFound: 3, FilesDownloaded: 1, ErrorCount: 2}, nil, "Download complete", "1 files downloaded (0.0 MB) in 00:01:05\n2 errors"},
+		{"Nothing matched", progress.Progress{}, nil, "No files matched", "Check that the bucket name and prefix are correct."},
+		{"Stopped", p, fmt.Errorf("listing: %w", context.Canceled), "Download stopped", "10 files downloaded (3.0 MB) before stopping after 00:01:05"},
+		{"Failed", p, errors.New("access denied"), "Download failed", "access denied\n10 files downloaded (3.0 MB) in 00:01:05"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			title, content := notificationMessage(tc.p, tc.err, 65*time.Second)
+			assert.Equal(t, tc.wantTitle, title)
+			assert.Equal(t, tc.wantContent, content)
+		})
+	}
+}
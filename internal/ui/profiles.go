@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"s3downloader/internal/secrets"
+
+	"fyne.io/fyne/v2"
+)
+
+// profilesPreference is the preferences key storing the connection profiles as JSON
+const profilesPreference = "profiles"
+
+// Profile is a named set of connection settings
+type Profile struct {
+	Name      string `json:"name"`
+	Region    string `json:"region,omitempty"`
+	Bucket    string `json:"bucket,omitempty"`
+	Prefix    string `json:"prefix,omitempty"`
+	AccessKey string `json:"accessKey,omitempty"`
+	SecretKey string `json:"secretKey,omitempty"` // Only in profiles saved by earlier versions, which kept it in plaintext
+	SecretRef string `json:"secretRef,omitempty"` // Keyring entry of the secret key, when the user chose to store it
+	Endpoint  string `json:"endpoint,omitempty"`
+}
+
+// ErrSecretNotStored is returned by Save when the profile was saved without its
+// secret key because the keyring failed
+var ErrSecretNotStored = errors.New("the secret key was not stored")
+
+// ProfileStore keeps connection profiles in the app preferences as a JSON array
+// and their secret keys in a keyring, never in the preferences
+type ProfileStore struct {
+	prefs   fyne.Preferences
+	secrets secrets.Store
+}
+
+// NewProfileStore creates a store backed by prefs that keeps secret keys in keyring
+func NewProfileStore(prefs fyne.Preferences, keyring secrets.Store) *ProfileStore {
+	return &ProfileStore{prefs: prefs, secrets: keyring}
+}
+
+// read decodes the stored profiles, keyed by name
+func (s *ProfileStore) read() (map[string]Profile, error) {
+	profiles := make(map[string]Profile)
+	data := s.prefs.String(profilesPreference)
+	if data == "" {
+		return profiles, nil
+	}
+	var list []Profile
+	if err := json.Unmarshal([]byte(data), &list); err != nil {
+		return nil, fmt.Errorf("failed to read saved profiles: %w", err)
+	}
+	for _, p := range list {
+		profiles[p.Name] = p
+	}
+	return profiles, nil
+}
+
+// write stores profiles sorted by name
+func (s *ProfileStore) write(profiles map[string]Profile) error {
+	list := make([]Profile, 0, len(profiles))
+	for _, p := range profiles {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	data, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to save profiles: %w", err)
+	}
+	s.prefs.SetString(profilesPreference, string(data))
+	return nil
+}
+
+// Save stores p, replacing any profile of the same name, and secret, if any, in the
+// keyring under the profile's name. When the keyring fails the profile is saved
+// without a secret key and the error wraps ErrSecretNotStored.
+func (s *ProfileStore) Save(p Profile, secret string) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return errors.New("profile name is empty")
+	}
+	profiles, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	p.SecretKey = ""
+	p.SecretRef = ""
+	var secretErr error
+	if secret != "" {
+		if secretErr = s.secrets.Set(p.Name, secret); secretErr == nil {
+			p.SecretRef = p.Name
+		}
+	} else if old, ok := profiles[p.Name]; ok && old.SecretRef != "" {
+		s.secrets.Delete(old.SecretRef) // The user no longer wants it stored
+	}
+
+	profiles[p.Name] = p
+	if err := s.write(profiles); err != nil {
+		return err
+	}
+	if secretErr != nil {
+		return fmt.Errorf("%w with profile '%s': %w", ErrSecretNotStored, p.Name, secretErr)
+	}
+	return nil
+}
+
+// Secret returns the secret key of p from the keyring, or the plaintext one of a
+// profile saved by an earlier version. It is empty if none was stored.
+func (s *ProfileStore) Secret(p Profile) (string, error) {
+	if p.SecretRef == "" {
+		return p.SecretKey, nil
+	}
+	secret, err := s.secrets.Get(p.SecretRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to load the secret key of profile '%s': %w", p.Name, err)
+	}
+	return secret, nil
+}
+
+// Load returns the profile called name
+func (s *ProfileStore) Load(name string) (Profile, error) {
+	profiles, err := s.read()
+	if err != nil {
+		return Profile{}, err
+	}
+	p, ok := profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("profile '%s' does not exist", name)
+	}
+	return p, nil
+}
+
+// List returns the names of the stored profiles in alphabetical order
+func (s *ProfileStore) List() ([]string, error) {
+	profiles, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Delete removes the profile called name and its secret key; unknown names are ignored
+func (s *ProfileStore) Delete(name string) error {
+	profiles, err := s.read()
+	if err != nil {
+		return err
+	}
+	p, ok := profiles[name]
+	if !ok {
+		return nil
+	}
+	delete(profiles, name)
+	if err := s.write(profiles); err != nil {
+		return err
+	}
+	if p.SecretRef != "" {
+		if err := s.secrets.Delete(p.SecretRef); err != nil && !errors.Is(err, secrets.ErrNotFound) {
+			return fmt.Errorf("deleted profile '%s', but not its secret key: %w", name, err)
+		}
+	}
+	return nil
+}
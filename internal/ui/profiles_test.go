@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"s3downloader/internal/secrets"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileStore(t *testing.T) {
+	prefs := test.NewApp().Preferences()
+	store := NewProfileStore(prefs, secrets.NewMemory())
+
+	names, err := store.List()
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+
+	prod := Profile{Name: "prod", Region: "us-east-1", Bucket: "prod-data", Prefix: "exports/", AccessKey: "AKIDPROD"}
+	minio := Profile{Name: "minio", Bucket: "local", Endpoint: "http://localhost:9000", AccessKey: "minio", SecretRef: "minio"}
+	assert.NoError(t, store.Save(prod, ""))
+	assert.NoError(t, store.Save(minio, "minio123"))
+
+	names, err = store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"minio", "prod"}, names)
+
+	loaded, err := store.Load("minio")
+	assert.NoError(t, err)
+	assert.Equal(t, minio, loaded)
+
+	// Saving under an existing name replaces the profile
+	prod.Prefix = "archive/"
+	assert.NoError(t, store.Save(prod, ""))
+	loaded, err = store.Load("prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "archive/", loaded.Prefix)
+
+	assert.NoError(t, store.Delete("prod"))
+	assert.NoError(t, store.Delete("unknown"))
+	_, err = store.Load("prod")
+	assert.Error(t, err)
+	names, err = store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"minio"}, names)
+}
+
+func TestProfileStoreSerialization(t *testing.T) {
+	prefs := test.NewApp().Preferences()
+	store := NewProfileStore(prefs, secrets.NewMemory())
+
+	assert.NoError(t, store.Save(Profile{Name: "  b  ", Bucket: "bucket-b"}, ""))
+	assert.NoError(t, store.Save(Profile{Name: "a", Region: "eu-west-1", AccessKey: "AKID"}, ""))
+	assert.NoError(t, store.Save(Profile{Name: "c", AccessKey: "AKIDC", SecretKey: "plaintext"}, "s3cr3t"))
+	assert.Error(t, store.Save(Profile{Name: " "}, ""))
+
+	// Names are trimmed, profiles sorted and secrets only referenced, never written
+	assert.JSONEq(t, `[{"name":"a","region":"eu-west-1","accessKey":"AKID"},{"name":"b","bucket":"bucket-b"},`+
+		`{"name":"c","accessKey":"AKIDC","secretRef":"c"}]`,
+		prefs.String(profilesPreference))
+	assert.NotContains(t, prefs.String(profilesPreference), "s3cr3t")
+
+	prefs.SetString(profilesPreference, "not json")
+	_, err := store.List()
+	assert.Error(t, err)
+}
+
+func TestProfileStoreSecrets(t *testing.T) {
+	prefs := test.NewApp().Preferences()
+	keyring := secrets.NewMemory()
+	store := NewProfileStore(prefs, keyring)
+
+	assert.NoError(t, store.Save(Profile{Name: "prod", AccessKey: "AKIDPROD"}, "s3cr3t"))
+	stored, err := keyring.Get("prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", stored)
+
+	p, err := store.Load("prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", p.SecretRef)
+	assert.Empty(t, p.SecretKey)
+	secret, err := store.Secret(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", secret)
+
+	// Saving without a secret removes the stored one
+	assert.NoError(t, store.Save(Profile{Name: "prod", AccessKey: "AKIDPROD"}, ""))
+	_, err = keyring.Get("prod")
+	assert.ErrorIs(t, err, secrets.ErrNotFound)
+	p, err = store.Load("prod")
+	assert.NoError(t, err)
+	secret, err = store.Secret(p)
+	assert.NoError(t, err)
+	assert.Empty(t, secret)
+
+	// Deleting a profile deletes its secret
+	assert.NoError(t, store.Save(Profile{Name: "prod"}, "s3cr3t"))
+	assert.NoError(t, store.Delete("prod"))
+	_, err = keyring.Get("prod")
+	assert.ErrorIs(t, err, secrets.ErrNotFound)
+
+	// A secret removed from the keyring outside of the app cannot be loaded
+	assert.NoError(t, store.Save(Profile{Name: "gone"}, "s3cr3t"))
+	assert.NoError(t, keyring.Delete("gone"))
+	p, err = store.Load("gone")
+	assert.NoError(t, err)
+	_, err = store.Secret(p)
+	assert.ErrorIs(t, err, secrets.ErrNotFound)
+	assert.ErrorContains(t, err, "profile 'gone'")
+	assert.NoError(t, store.Delete("gone"), "a missing secret does not fail the deletion")
+}
+
+func TestProfileStoreLegacyPlaintextSecret(t *testing.T) {
+	prefs := test.NewApp().Preferences()
+	keyring := secrets.NewMemory()
+	store := NewProfileStore(prefs, keyring)
+	prefs.SetString(profilesPreference, `[{"name":"old","accessKey":"AKIDOLD","secretKey":"plaintext"}]`)
+
+	p, err := store.Load("old")
+	assert.NoError(t, err)
+	secret, err := store.Secret(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "plaintext", secret)
+
+	// Saving it again moves the secret to the keyring
+	assert.NoError(t, store.Save(p, secret))
+	assert.NotContains(t, prefs.String(profilesPreference), "plaintext")
+	stored, err := keyring.Get("old")
+	assert.NoError(t, err)
+	assert.Equal(t, "plaintext", stored)
+}
+
+func TestProfileStoreKeyringUnavailable(t *testing.T) {
+	prefs := test.NewApp().Preferences()
+	keyring := secrets.NewMemory()
+	keyring.Err = errors.New("no secret service")
+	store := NewProfileStore(prefs, keyring)
+
+	err := store.Save(Profile{Name: "prod", AccessKey: "AKIDPROD"}, "s3cr3t")
+	assert.ErrorIs(t, err, ErrSecretNotStored)
+	assert.ErrorIs(t, err, keyring.Err)
+	assert.EqualError(t, err, "the secret key was not stored with profile 'prod': no secret service")
+
+	// The profile is saved, but without the secret and not in plaintext
+	assert.JSONEq(t, `[{"name":"prod","accessKey":"AKIDPROD"}]`, prefs.String(profilesPreference))
+	p, err := store.Load("prod")
+	assert.NoError(t, err)
+	secret, err := store.Secret(p)
+	assert.NoError(t, err)
+	assert.Empty(t, secret)
+
+	// Profiles without a secret do not need the keyring
+	assert.NoError(t, store.Save(Profile{Name: "public", Bucket: "open-data"}, ""))
+	assert.NoError(t, store.Delete("public"))
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"encoding/json"
+	"sync"
+
+	"fyne.io/fyne/v2"
+)
+
+// recentBucketsPreference is the preferences key storing the recently used buckets as JSON
+const recentBucketsPreference = "recentBuckets"
+
+// maxRecentBuckets is how many recently used buckets are remembered
+const maxRecentBuckets = 10
+
+// RecentBucket is a recently used bucket and the region it was used with
+type RecentBucket struct {
+	Name   string `json:"name"`
+	Region string `json:"region,omitempty"`
+}
+
+// RecentBuckets is a most-recently-used list of buckets, newest first
+type RecentBuckets struct {
+	mu    sync.Mutex
+	items []RecentBucket
+	limit int
+}
+
+// NewRecentBuckets creates an empty list keeping at most limit buckets
+func NewRecentBuckets(limit int) *RecentBuckets {
+	return &RecentBuckets{limit: limit}
+}
+
+// Add moves b to the front of the list, replacing an earlier entry for the same
+// bucket and dropping the oldest one when the list is full
+func (r *RecentBuckets) Add(b RecentBucket) {
+	if b.Name == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	items := []RecentBucket{b}
+	for _, item := range r.items {
+		if item.Name != b.Name && len(items) < r.limit {
+			items = append(items, item)
+		}
+	}
+	r.items = items
+}
+
+// Names returns the bucket names, newest first
+func (r *RecentBuckets) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, len(r.items))
+	for i, item := range r.items {
+		names[i] = item.Name
+	}
+	return names
+}
+
+// Region returns the region the bucket was last used with, or "" if it is not in the list
+func (r *RecentBuckets) Region(name string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, item := range r.items {
+		if item.Name == name {
+			return item.Region
+		}
+	}
+	return ""
+}
+
+// loadRecentBuckets reads the recently used buckets from prefs. A list that
+// cannot be read is not worth an error and starts over empty.
+func loadRecentBuckets(prefs fyne.Preferences) *RecentBuckets {
+	r := NewRecentBuckets(maxRecentBuckets)
+	var items []RecentBucket
+	if err := json.Unmarshal([]byte(prefs.String(recentBucketsPreference)), &items); err != nil {
+		return r
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		r.Add(items[i])
+	}
+	return r
+}
+
+// save writes the list to prefs
+func (r *RecentBuckets) save(prefs fyne.Preferences) {
+	r.mu.Lock()
+	data, err := json.Marshal(r.items)
+	r.mu.Unlock()
+	if err != nil {
+		return
+	}
+	prefs.SetString(recentBucketsPreference, string(data))
+}
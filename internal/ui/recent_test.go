@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentBuckets(t *testing.T) {
+	r := NewRecentBuckets(3)
+	r.Add(RecentBucket{Name: "a", Region: "eu-west-1"})
+	r.Add(RecentBucket{Name: "b", Region: "us-east-1"})
+	r.Add(RecentBucket{Name: ""})
+	assert.Equal(t, []string{"b", "a"}, r.Names())
+
+	// Reusing a bucket moves it to the front and updates its region
+	r.Add(RecentBucket{Name: "a", Region: "eu-central-1"})
+	assert.Equal(t, []string{"a", "b"}, r.Names())
+	assert.Equal(t, "eu-central-1", r.Region("a"))
+	assert.Equal(t, "", r.Region("unknown"))
+
+	// The oldest bucket is dropped once the list is full
+	r.Add(RecentBucket{Name: "c"})
+	r.Add(RecentBucket{Name: "d"})
+	assert.Equal(t, []string{"d", "c", "a"}, r.Names())
+}
+
+func TestRecentBucketsPreferences(t *testing.T) {
+	prefs := test.NewApp().Preferences()
+	assert.Empty(t, loadRecentBuckets(prefs).Names())
+
+	r := NewRecentBuckets(maxRecentBuckets)
+	for i := 0; i < maxRecentBuckets+2; i++ {
+		r.Add(RecentBucket{Name: fmt.Sprintf("bucket-%d", i), Region: "eu-west-1"})
+	}
+	r.save(prefs)
+
+	loaded := loadRecentBuckets(prefs)
+	assert.Equal(t, r.Names(), loaded.Names())
+	assert.Equal(t, "bucket-11", loaded.Names()[0])
+	assert.Equal(t, "eu-west-1", loaded.Region("bucket-5"))
+
+	prefs.SetString(recentBucketsPreference, "not json")
+	assert.Empty(t, loadRecentBuckets(prefs).Names())
+}
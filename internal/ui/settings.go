@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// Preference keys of the settings remembered between launches
+const (
+	bucketPreference       = "bucket"
+	prefixPreference       = "prefix"
+	regionPreference       = "region"
+	downloadPathPreference = "downloadPath"
+	overwritePreference    = "overwrite"
+	endpointPreference     = "endpoint"
+	performancePreference  = "performance"
+	adaptivePreference     = "adaptive"
+	maxSpeedPreference     = "maxSpeed"
+)
+
+// savedSettings are the form values remembered between launches and shared
+// through settings files. Credentials are deliberately not part of them and are
+// never written to the preferences or exported.
+type savedSettings struct {
+	Bucket       string `json:"bucket"`
+	Prefix       string `json:"prefix"`
+	Region       string `json:"region"`
+	DownloadPath string `json:"downloadPath"`
+	Overwrite    bool   `json:"overwrite"`
+	Endpoint     string `json:"endpoint"`
+	Performance  string `json:"performance"`
+	Adaptive     bool   `json:"adaptive"`
+	MaxSpeed     string `json:"maxSpeed"` // MB/s as entered, empty for unlimited
+}
+
+// loadSettings reads the saved settings, taking missing ones from fallback
+func loadSettings(prefs fyne.Preferences, fallback savedSettings) savedSettings {
+	return savedSettings{
+		Bucket:       prefs.StringWithFallback(bucketPreference, fallback.Bucket),
+		Prefix:       prefs.StringWithFallback(prefixPreference, fallback.Prefix),
+		Region:       prefs.StringWithFallback(regionPreference, fallback.Region),
+		DownloadPath: prefs.StringWithFallback(downloadPathPreference, fallback.DownloadPath),
+		Overwrite:    prefs.BoolWithFallback(overwritePreference, fallback.Overwrite),
+		Endpoint:     prefs.StringWithFallback(endpointPreference, fallback.Endpoint),
+		Performance:  prefs.StringWithFallback(performancePreference, fallback.Performance),
+		Adaptive:     prefs.BoolWithFallback(adaptivePreference, fallback.Adaptive),
+		MaxSpeed:     prefs.StringWithFallback(maxSpeedPreference, fallback.MaxSpeed),
+	}
+}
+
+// save writes the settings to prefs
+func (s savedSettings) save(prefs fyne.Preferences) {
+	prefs.SetString(bucketPreference, s.Bucket)
+	prefs.SetString(prefixPreference, s.Prefix)
+	prefs.SetString(regionPreference, s.Region)
+	prefs.SetString(downloadPathPreference, s.DownloadPath)
+	prefs.SetBool(overwritePreference, s.Overwrite)
+	prefs.SetString(endpointPreference, s.Endpoint)
+	prefs.SetString(performancePreference, s.Performance)
+	prefs.SetBool(adaptivePreference, s.Adaptive)
+	prefs.SetString(maxSpeedPreference, s.MaxSpeed)
+}
+
+// clearSettings removes the saved settings from prefs
+func clearSettings(prefs fyne.Preferences) {
+	for _, key := range []string{bucketPreference, prefixPreference, regionPreference, downloadPathPreference, overwritePreference,
+		endpointPreference, performancePreference, adaptivePreference, maxSpeedPreference} {
+		prefs.RemoveValue(key)
+	}
+}
+
+// encodeSettings formats the settings as an indented JSON file
+func encodeSettings(s savedSettings) ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode settings: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// decodeSettings reads a settings file; settings the file leaves out keep
+// their value from base
+func decodeSettings(data []byte, base savedSettings) (savedSettings, error) {
+	if err := json.Unmarshal(data, &base); err != nil {
+		return savedSettings{}, fmt.Errorf("failed to read settings file: %w", err)
+	}
+	return base, nil
+}
+
+// formSettings returns the settings currently entered in the form
+func (u *UIManager) formSettings() savedSettings {
+	return savedSettings{
+		Bucket:       u.components.BucketEntry.Text,
+		Prefix:       u.components.PrefixEntry.Text,
+		Region:       u.components.AwsRegionEntry.Text,
+		DownloadPath: u.components.FilePathEntry.Text,
+		Overwrite:    u.components.OverwriteCheck.Checked,
+		Endpoint:     u.components.EndpointEntry.Text,
+		Performance:  u.components.PerformanceSelect.Selected,
+		Adaptive:     u.components.AdaptiveCheck.Checked,
+		MaxSpeed:     u.components.MaxSpeedEntry.Text,
+	}
+}
+
+// applySettings fills the form with s
+func (u *UIManager) applySettings(s savedSettings) {
+	// Setting the bucket may fill in its last region, so the region comes after it
+	u.components.BucketEntry.SetText(s.Bucket)
+	u.components.PrefixEntry.SetText(s.Prefix)
+	u.components.AwsRegionEntry.SetText(s.Region)
+	u.components.FilePathEntry.SetText(s.DownloadPath)
+	u.components.OverwriteCheck.SetChecked(s.Overwrite)
+	u.components.EndpointEntry.SetText(s.Endpoint)
+	u.components.PerformanceSelect.SetSelected(s.Performance)
+	u.components.AdaptiveCheck.SetChecked(s.Adaptive)
+	u.components.MaxSpeedEntry.SetText(s.MaxSpeed)
+}
+
+// restoreSettings fills the form with the settings saved by an earlier launch,
+// keeping the form's defaults for those that were never saved
+func (u *UIManager) restoreSettings() {
+	u.applySettings(loadSettings(fyne.CurrentApp().Preferences(), u.formSettings()))
+}
+
+// saveSettings remembers the form's current settings for the next launch
+func (u *UIManager) saveSettings() {
+	u.formSettings().save(fyne.CurrentApp().Preferences())
+}
+
+// ClearSavedSettings forgets the settings remembered between launches; the
+// form keeps its current values
+func (u *UIManager) ClearSavedSettings() {
+	clearSettings(fyne.CurrentApp().Preferences())
+	u.logger.Info("user action", "action", "clear settings")
+	dialog.ShowInformation("Settings Cleared", "Saved settings were removed and will not be restored on the next launch", u.window)
+}
+
+// ExportSettings saves the form's settings, without any credentials, to a JSON file
+func (u *UIManager) ExportSettings() {
+	data, err := encodeSettings(u.formSettings())
+	if err != nil {
+		dialog.ShowError(err, u.window)
+		return
+	}
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, u.window)
+			return
+		}
+		if writer == nil {
+			return // Dialog canceled
+		}
+		_, err = writer.Write(data)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to write settings file: %w", err), u.window)
+			return
+		}
+		u.logger.Info("user action", "action", "export settings", "file", writer.URI().Name())
+	}, u.window)
+	save.SetFileName("s3downloader-settings.json")
+	save.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	save.Show()
+}
+
+// ImportSettings fills the form from a JSON file written by ExportSettings
+func (u *UIManager) ImportSettings() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, u.window)
+			return
+		}
+		if reader == nil {
+			return // Dialog canceled
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to read settings file: %w", err), u.window)
+			return
+		}
+		s, err := decodeSettings(data, u.formSettings())
+		if err != nil {
+			dialog.ShowError(err, u.window)
+			return
+		}
+		u.applySettings(s)
+		u.logger.Info("user action", "action", "import settings", "file", reader.URI().Name())
+	}, u.window)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	open.Show()
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedSettingsRoundTrip(t *testing.T) {
+	prefs := test.NewApp().Preferences()
+
+	defaults := savedSettings{Region: "eu-west-1"}
+
+	// Nothing saved yet
+	assert.Equal(t, defaults, loadSettings(prefs, defaults))
+
+	want := savedSettings{Bucket: "my-bucket", Prefix: "logs/2024/", Region: "us-east-2", DownloadPath: "/tmp/out", Overwrite: true,
+		Endpoint: "http://localhost:9000", Performance: "Aggressive", Adaptive: true, MaxSpeed: "25"}
+	want.save(prefs)
+	assert.Equal(t, want, loadSettings(prefs, defaults))
+
+	clearSettings(prefs)
+	assert.Equal(t, defaults, loadSettings(prefs, defaults))
+}
+
+func TestFormSettingsExcludeCredentials(t *testing.T) {
+	prefs := test.NewApp().Preferences()
+	u := &UIManager{components: NewComponents()}
+	u.components.BucketEntry.SetText("my-bucket")
+	u.components.AwsAccessKeyEntry.SetText("AKID")
+	u.components.AwsSecretKeyEntry.SetText("SECRET")
+	u.components.AwsTokenEntry.SetText("TOKEN")
+
+	u.formSettings().save(prefs)
+	exported, err := encodeSettings(u.formSettings())
+	assert.NoError(t, err)
+
+	assert.Equal(t, "my-bucket", loadSettings(prefs, savedSettings{}).Bucket)
+	for _, secret := range []string{"AKID", "SECRET", "TOKEN"} {
+		for _, key := range []string{bucketPreference, prefixPreference, regionPreference, downloadPathPreference, endpointPreference, maxSpeedPreference} {
+			assert.NotEqual(t, secret, prefs.String(key))
+		}
+		assert.NotContains(t, string(exported), secret)
+	}
+}
+
+func TestSettingsFileRoundTrip(t *testing.T) {
+	want := savedSettings{Bucket: "team-data", Prefix: "exports/", Region: "ap-southeast-2", DownloadPath: "/data/exports", Overwrite: true,
+		Endpoint: "https://minio.internal:9000", Performance: "Conservative", Adaptive: true, MaxSpeed: "10"}
+
+	data, err := encodeSettings(want)
+	assert.NoError(t, err)
+	got, err := decodeSettings(data, savedSettings{})
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// Settings missing from the file keep their current value
+	base := savedSettings{Region: "eu-west-1", DownloadPath: "/home/me/Downloads", Performance: "Balanced"}
+	got, err = decodeSettings([]byte(`{"bucket": "other", "overwrite": true}`), base)
+	assert.NoError(t, err)
+	assert.Equal(t, savedSettings{Bucket: "other", Region: "eu-west-1", DownloadPath: "/home/me/Downloads", Overwrite: true, Performance: "Balanced"}, got)
+
+	_, err = decodeSettings([]byte("not json"), base)
+	assert.ErrorContains(t, err, "failed to read settings file")
+}
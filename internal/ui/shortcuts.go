@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// shortcut binds a key combination to the action of a button
+type shortcut struct {
+	Name   string
+	Label  string // Menu text
+	Key    *desktop.CustomShortcut
+	Button *widget.Button
+}
+
+// shortcuts returns the window's keyboard shortcuts. Each one presses its
+// button, so it is only available while the button is.
+func (u *UIManager) shortcuts() []shortcut {
+	return []shortcut{
+		{"start", "Start Download", &desktop.CustomShortcut{KeyName: fyne.KeyReturn, Modifier: fyne.KeyModifierShortcutDefault}, u.components.DownloadButton},
+		{"stop", "Stop Download", &desktop.CustomShortcut{KeyName: fyne.KeyEscape}, u.components.StopButton},
+		{"validate", "Validate Bucket", &desktop.CustomShortcut{KeyName: fyne.KeyB, Modifier: fyne.KeyModifierShortcutDefault}, u.components.ValidateButton},
+	}
+}
+
+// runShortcut presses the button of the named shortcut, reporting whether it
+// was available. Disabled and hidden buttons are left alone, so that for
+// example starting does nothing while a download runs.
+func (u *UIManager) runShortcut(name string) bool {
+	for _, s := range u.shortcuts() {
+		if s.Name != name {
+			continue
+		}
+		if s.Button.Disabled() || !s.Button.Visible() || s.Button.OnTapped == nil {
+			return false
+		}
+		s.Button.OnTapped()
+		return true
+	}
+	return false
+}
+
+// setupShortcuts registers the shortcuts with the window and returns a menu listing them
+func (u *UIManager) setupShortcuts() *fyne.Menu {
+	canvas := u.window.Canvas()
+	plainKeys := make(map[fyne.KeyName]func())
+	var items []*fyne.MenuItem
+	for _, s := range u.shortcuts() {
+		name := s.Name
+		action := func() { u.runShortcut(name) }
+		if s.Key.Modifier == 0 {
+			// Plain keys arrive as typed keys rather than shortcuts
+			plainKeys[s.Key.KeyName] = action
+		} else {
+			canvas.AddShortcut(s.Key, func(fyne.Shortcut) { action() })
+		}
+		item := fyne.NewMenuItem(s.Label, action)
+		item.Shortcut = s.Key
+		items = append(items, item)
+	}
+	canvas.SetOnTypedKey(func(event *fyne.KeyEvent) {
+		if action, ok := plainKeys[event.Name]; ok {
+			action()
+		}
+	})
+	return fyne.NewMenu("Download", items...)
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunShortcut(t *testing.T) {
+	test.NewApp()
+	u := &UIManager{window: test.NewWindow(nil), components: NewComponents()}
+	var pressed []string
+	u.components.DownloadButton.OnTapped = func() { pressed = append(pressed, "start") }
+	u.components.StopButton.OnTapped = func() { pressed = append(pressed, "stop") }
+	u.components.ValidateButton.OnTapped = func() { pressed = append(pressed, "validate") }
+
+	// Idle: there is nothing to stop
+	assert.True(t, u.runShortcut("start"))
+	assert.False(t, u.runShortcut("stop"))
+	assert.True(t, u.runShortcut("validate"))
+
+	// Downloading: only stopping is possible
+	u.disableInputs()
+	assert.False(t, u.runShortcut("start"))
+	assert.True(t, u.runShortcut("stop"))
+	assert.False(t, u.runShortcut("validate"))
+
+	assert.False(t, u.runShortcut("unknown"))
+	assert.Equal(t, []string{"start", "validate", "stop"}, pressed)
+}
+
+func TestEscapeStops(t *testing.T) {
+	test.NewApp()
+	u := &UIManager{window: test.NewWindow(nil), components: NewComponents()}
+	stopped := false
+	u.components.StopButton.OnTapped = func() { stopped = true }
+	u.components.StopButton.Show()
+
+	menu := u.setupShortcuts()
+	assert.Len(t, menu.Items, len(u.shortcuts()))
+	assert.Equal(t, "Stop Download", menu.Items[1].Label)
+
+	u.window.Canvas().OnTypedKey()(&fyne.KeyEvent{Name: fyne.KeyEscape})
+	assert.True(t, stopped)
+}
@@ -2,18 +2,33 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"s3downloader/internal/aws"
+	"s3downloader/internal/logging"
 	"s3downloader/internal/progress"
+	"s3downloader/internal/secrets"
+	"s3downloader/pkg/fileutils"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
+// idleTime is shown in place of the elapsed and remaining time when they are not known
+const idleTime = "--"
+
+// validateTimeout bounds how long bucket validation may take
+const validateTimeout = 30 * time.Second
+
 // UIManager struct handles the UI lifecycle and interactions
 type UIManager struct {
 	window            fyne.Window
@@ -21,43 +36,178 @@ type UIManager struct {
 	components        *Components
 	cancelFunc        context.CancelFunc
 	downloadStartTime time.Time
+	keys              []string // Exact keys to download instead of listing the prefix
+	ignoreFreeSpace   bool     // Set while restarting a download the user confirmed despite low disk space
+	profiles          *ProfileStore
+	recent            *RecentBuckets
+	queue             *JobQueue
+	selectedJob       int             // ID of the job selected in the queue list, 0 for none
+	logger            *logging.Logger // Audit trail of user actions and downloads, nil when off
 }
 
 // NewUIManager initializes a new UIManager
 func NewUIManager(window fyne.Window) *UIManager {
-	return &UIManager{
+	u := &UIManager{
 		window:     window,
 		components: NewComponents(),
 	}
+	u.queue = NewJobQueue(u.jobChanged)
+	return u
+}
+
+// SetLogger sets where user actions and downloads are logged
+func (u *UIManager) SetLogger(logger *logging.Logger) {
+	u.logger = logger
 }
 
 // SetupUI sets up the UI components and layout
 func (u *UIManager) SetupUI() {
 	u.components.DownloadButton.OnTapped = u.StartDownload
 	u.components.StopButton.OnTapped = u.StopDownload
+	u.components.RetryButton.OnTapped = u.RetryFailed
+	u.components.ErrorsButton.OnTapped = u.ShowErrors
+	u.components.PauseButton.OnTapped = u.TogglePause
+	u.components.ValidateButton.OnTapped = u.ValidateBucket
 	u.components.ShowSecretCheck.OnChanged = func(checked bool) {
 		u.components.AwsSecretKeyEntry.Password = !checked
 		u.components.AwsSecretKeyEntry.Refresh()
+		u.components.AwsTokenEntry.Password = !checked
+		u.components.AwsTokenEntry.Refresh()
+	}
+	u.components.AnonymousCheck.OnChanged = u.setAnonymous
+	u.components.PerformanceSelect.OnChanged = func(preset string) {
+		fyne.CurrentApp().Preferences().SetString(performancePreference, preset)
+	}
+	u.components.NotifyCheck.SetChecked(fyne.CurrentApp().Preferences().BoolWithFallback(notifyPreference, true))
+	u.components.NotifyCheck.OnChanged = func(checked bool) {
+		fyne.CurrentApp().Preferences().SetBool(notifyPreference, checked)
+	}
+	u.components.CopyLogButton.OnTapped = func() {
+		u.window.Clipboard().SetContent(u.components.LogPanel.Text())
+	}
+	u.components.ClearLogButton.OnTapped = u.components.LogPanel.Clear
+	u.components.BucketEntry.OnPaste = u.fillFromS3URI
+	u.components.BucketEntry.OnChanged = u.bucketChanged
+	u.recent = loadRecentBuckets(fyne.CurrentApp().Preferences())
+	u.components.BucketEntry.SetOptions(u.recent.Names())
+	u.window.SetOnDropped(func(_ fyne.Position, items []fyne.URI) {
+		if len(items) == 0 || u.components.BucketEntry.Disabled() {
+			return
+		}
+		// Dropped text arrives wrapped in a file URI
+		if !u.fillFromS3URI(items[0].Path()) {
+			dialog.ShowInformation("Not an S3 URI", "Drop an S3 URI such as s3://my-bucket/some/prefix/ to fill in the bucket and prefix", u.window)
+		}
+	})
+	u.components.AwsRegionEntry.Validator = u.validateRegion
+	u.components.EndpointEntry.OnChanged = func(string) {
+		u.components.AwsRegionEntry.Validate()
 	}
+	u.components.LoadKeysButton.OnTapped = u.LoadKeysFile
+	u.components.QueueButton.OnTapped = u.AddToQueue
+	u.components.CancelJobButton.OnTapped = u.CancelSelectedJob
+	u.setupQueueList()
+	u.components.ClearKeysButton.OnTapped = u.ClearKeys
+	u.restoreSettings()
+	// Variables set for this launch win over the settings remembered from the last one
+	u.applyEnvironment()
+
+	u.profiles = NewProfileStore(fyne.CurrentApp().Preferences(), secrets.Keyring{})
+	u.components.ProfileSelect.OnChanged = u.SelectProfile
+	u.components.SaveProfileButton.OnTapped = u.SaveProfile
+	u.components.DeleteProfileButton.OnTapped = u.DeleteProfile
+	u.refreshProfiles("")
+
+	u.window.SetMainMenu(fyne.NewMainMenu(
+		fyne.NewMenu("File",
+			fyne.NewMenuItem("Load Config File...", u.LoadConfigFile),
+			fyne.NewMenuItem("Import Settings...", u.ImportSettings),
+			fyne.NewMenuItem("Export Settings...", u.ExportSettings),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem("Clear Saved Settings", u.ClearSavedSettings),
+		),
+		u.setupShortcuts(),
+		fyne.NewMenu("Help", fyne.NewMenuItem("Fields", u.ShowHelp)),
+	))
+	u.window.SetCloseIntercept(func() {
+		u.saveSettings()
+		u.window.Close()
+	})
 
 	content := container.NewVBox(
-		widget.NewLabel("S3 Downloader"),
+		container.NewBorder(nil, nil, nil, widget.NewButtonWithIcon("", theme.HelpIcon(), u.ShowHelp), widget.NewLabel("S3 Downloader")),
 		widget.NewForm(
-			widget.NewFormItem("Bucket Name", u.components.BucketEntry),
-			widget.NewFormItem("Prefix", u.components.PrefixEntry),
-			widget.NewFormItem("Download Path", u.components.FilePathEntry),
+			widget.NewFormItem("Connection Profile", container.NewBorder(nil, nil, nil,
+				container.NewHBox(u.components.SaveProfileButton, u.components.DeleteProfileButton), u.components.ProfileSelect)),
+			widget.NewFormItem("", u.components.StoreSecretCheck),
+			withHint(widget.NewFormItem("Bucket Name", container.NewBorder(nil, nil, nil, u.components.ValidateButton, u.components.BucketEntry)), "BucketEntry"),
+			withHint(widget.NewFormItem("Prefix", u.components.PrefixEntry), "PrefixEntry"),
+			widget.NewFormItem("Include", u.components.IncludeEntry),
+			widget.NewFormItem("Exclude", u.components.ExcludeEntry),
+			widget.NewFormItem("Tags", u.components.TagFilterEntry),
+			widget.NewFormItem("Content types", u.components.ContentTypeEntry),
+			widget.NewFormItem("Newest Files Only", u.components.MaxRecentEntry),
+			widget.NewFormItem("Keys File", container.NewHBox(u.components.LoadKeysButton, u.components.ClearKeysButton, u.components.KeysLabel)),
+			widget.NewFormItem("Download Path", container.NewBorder(nil, nil, nil, u.components.DestinationSizeLabel, u.components.FilePathEntry)),
+			widget.NewFormItem("", u.components.FlattenCheck),
+			widget.NewFormItem("", u.components.StripPrefixCheck),
+			widget.NewFormItem("", u.components.FolderMarkersCheck),
 			widget.NewFormItem("", u.components.OverwriteCheck),
-			widget.NewFormItem("AWS Access Key", u.components.AwsAccessKeyEntry),
+			widget.NewFormItem("", u.components.SkipUnchangedCheck),
+			widget.NewFormItem("", u.components.IndexCheck),
+			widget.NewFormItem("", u.components.ResumeCheck),
+			widget.NewFormItem("", u.components.VersionsCheck),
+			widget.NewFormItem("Performance", u.components.PerformanceSelect),
+			widget.NewFormItem("", u.components.AdaptiveCheck),
+			widget.NewFormItem("Max speed (MB/s)", u.components.MaxSpeedEntry),
+			widget.NewFormItem("", u.components.AnonymousCheck),
+			withHint(widget.NewFormItem("AWS Access Key", u.components.AwsAccessKeyEntry), "AwsAccessKeyEntry"),
 			widget.NewFormItem("AWS Secret Key", container.NewBorder(nil, nil, nil, u.components.ShowSecretCheck, u.components.AwsSecretKeyEntry)),
+			widget.NewFormItem("AWS Session Token", u.components.AwsTokenEntry),
+			widget.NewFormItem("AWS Profile", u.components.AwsProfileEntry),
 			widget.NewFormItem("AWS Region", u.components.AwsRegionEntry),
+			widget.NewFormItem("Endpoint URL", u.components.EndpointEntry),
+			widget.NewFormItem("", u.components.PathStyleCheck),
+			widget.NewFormItem("", u.components.AccelerateCheck),
+			widget.NewFormItem("CA Certificate", u.components.CACertEntry),
+			widget.NewFormItem("", u.components.InsecureSkipVerifyCheck),
+			widget.NewFormItem("", u.components.RequesterPaysCheck),
+			widget.NewFormItem("", u.components.VerifyCheck),
+			widget.NewFormItem("", u.components.DecompressGzipCheck),
+			widget.NewFormItem("", u.components.StripGzipSuffixCheck),
+			widget.NewFormItem("", u.components.WriteMetadataCheck),
+			widget.NewFormItem("", u.components.HeadBeforeDownloadCheck),
+			widget.NewFormItem("", u.components.CleanPartialsCheck),
+			widget.NewFormItem("", u.components.FailFastCheck),
+			widget.NewFormItem("", u.components.MirrorCheck),
+			widget.NewFormItem("", u.components.DownloadArchivedCheck),
+			widget.NewFormItem("", u.components.RestoreArchivedCheck),
+			widget.NewFormItem("Restore Tier", u.components.RestoreTierSelect),
+			widget.NewFormItem("Restore Days", u.components.RestoreDaysEntry),
+			widget.NewFormItem("JSON Report", u.components.ReportPathEntry),
+			widget.NewFormItem("SHA256 Manifest", u.components.ManifestPathEntry),
+			widget.NewFormItem("State File", u.components.StateFileEntry),
+			widget.NewFormItem("", u.components.NotifyCheck),
 		),
 		container.NewVBox(
 			widget.NewSeparator(),
-			container.NewCenter(container.NewHBox(u.components.DownloadButton, u.components.StopButton)),
+			container.NewCenter(container.NewHBox(u.components.DownloadButton, u.components.QueueButton, u.components.PauseButton, u.components.StopButton, u.components.RetryButton, u.components.ErrorsButton)),
 			widget.NewSeparator(),
 		),
 		u.components.ProgressBar,
+		container.NewHBox(
+			widget.NewIcon(theme.HistoryIcon()), u.components.ElapsedLabel,
+			widget.NewIcon(theme.MediaFastForwardIcon()), u.components.EtaLabel,
+		),
 		u.components.StatusLabel,
+		widget.NewAccordion(widget.NewAccordionItem("Activity Log", container.NewBorder(
+			nil, container.NewHBox(u.components.CopyLogButton, u.components.ClearLogButton), nil, nil,
+			container.NewGridWrap(fyne.NewSize(760, 200), u.components.LogPanel.List),
+		))),
+		widget.NewAccordion(widget.NewAccordionItem("Download Queue", container.NewBorder(
+			container.NewVBox(u.components.QueueLabel, u.components.QueueProgressBar), u.components.CancelJobButton, nil, nil,
+			container.NewGridWrap(fyne.NewSize(760, 150), u.components.QueueList),
+		))),
 	)
 
 	paddedContent := container.NewVBox(content)
@@ -76,40 +226,516 @@ func (u *UIManager) StartDownload() {
 		return
 	}
 
+	u.saveSettings()
+	u.rememberBucket(bucket)
+	u.logger.Info("user action", "action", "start download", "bucket", bucket, "prefix", prefix, "path", downloadPath, "keys", len(u.keys))
+
 	u.components.ProgressBar.Show()
 	u.disableInputs()
 
 	// Initialize the downloader with AWS credentials
 	var err error
-	u.downloader, err = aws.NewDownloader(u.components.AwsRegionEntry.Text, u.components.AwsAccessKeyEntry.Text, u.components.AwsSecretKeyEntry.Text)
+	u.downloader, err = u.newDownloader()
 	if err != nil {
 		dialog.ShowError(fmt.Errorf("failed to create downloader: %w", err), u.window)
 		u.enableInputs()
 		return
 	}
 
+	u.downloader.SetLogSink(u.components.LogPanel)
 	u.downloadStartTime = time.Now() // Capture the start time
-
-	progressChan := make(chan progress.Progress, 1)
-	doneChan := make(chan struct{})
+	if len(u.keys) > 0 {
+		u.components.LogPanel.Log(fmt.Sprintf("Downloading %d keys from bucket '%s' to '%s'", len(u.keys), bucket, downloadPath))
+	} else {
+		u.components.LogPanel.Log(fmt.Sprintf("Downloading s3://%s/%s to '%s'", bucket, prefix, downloadPath))
+	}
 
 	// Start downloading files
-	go u.downloadFiles(bucket, prefix, downloadPath, progressChan, doneChan)
+	keys := u.keys
+	go u.downloadFiles(keys, func(ctx context.Context) ([]string, error) {
+		if len(keys) > 0 {
+			return u.downloader.DownloadObjects(ctx, bucket, keys, downloadPath, nil)
+		}
+		return nil, u.downloader.ListAndDownloadObjects(ctx, bucket, prefix, downloadPath, nil)
+	})
+}
+
+// RetryFailed downloads the objects that failed in the last run again with the
+// same settings, replacing any local files they left behind
+func (u *UIManager) RetryFailed() {
+	if u.downloader == nil {
+		return
+	}
+	var keys []string
+	for _, f := range u.downloader.Failures() {
+		keys = append(keys, f.Key)
+	}
+	if len(keys) == 0 {
+		return
+	}
+	bucket := u.components.BucketEntry.Text
+	downloadPath := u.components.FilePathEntry.Text
+
+	u.components.ProgressBar.Show()
+	u.disableInputs()
+	u.downloadStartTime = time.Now()
+	u.components.LogPanel.Log(fmt.Sprintf("Retrying %d failed objects from bucket '%s'", len(keys), bucket))
+	u.logger.Info("user action", "action", "retry failed", "bucket", bucket, "keys", len(keys))
+
+	go u.downloadFiles(keys, func(ctx context.Context) ([]string, error) {
+		return nil, u.downloader.DownloadKeys(ctx, bucket, keys, downloadPath, true, nil)
+	})
+}
+
+// newDownloader creates a downloader from the credentials and options entered in the form
+func (u *UIManager) newDownloader() (*aws.Downloader, error) {
+	cfg := aws.PresetConfig(u.components.PerformanceSelect.Selected)
+	cfg.GenerateIndex = u.components.IndexCheck.Checked
+	cfg.AdaptiveConcurrency = u.components.AdaptiveCheck.Checked
+	cfg.ResumePartial = u.components.ResumeCheck.Checked
+	cfg.ListVersions = u.components.VersionsCheck.Checked
+	cfg.Flatten = u.components.FlattenCheck.Checked
+	cfg.StripPrefix = u.components.StripPrefixCheck.Checked
+	cfg.CreateDirectoryMarkers = u.components.FolderMarkersCheck.Checked
+	cfg.SkipUnchanged = u.components.SkipUnchangedCheck.Checked
+	cfg.Overwrite = u.components.OverwriteCheck.Checked
+	cfg.Profile = u.components.AwsProfileEntry.Text
+	cfg.Anonymous = u.components.AnonymousCheck.Checked
+	cfg.SessionToken = u.components.AwsTokenEntry.Text
+	cfg.Endpoint = u.components.EndpointEntry.Text
+	cfg.PathStyle = u.components.PathStyleCheck.Checked
+	cfg.Accelerate = u.components.AccelerateCheck.Checked
+	cfg.CACertFile = u.components.CACertEntry.Text
+	cfg.InsecureSkipVerify = u.components.InsecureSkipVerifyCheck.Checked
+	cfg.RequesterPays = u.components.RequesterPaysCheck.Checked
+	cfg.VerifyChecksum = u.components.VerifyCheck.Checked
+	cfg.FailFast = u.components.FailFastCheck.Checked
+	cfg.Mirror = u.components.MirrorCheck.Checked
+	cfg.DecompressGzip = u.components.DecompressGzipCheck.Checked
+	cfg.StripGzipSuffix = u.components.StripGzipSuffixCheck.Checked
+	cfg.WriteMetadata = u.components.WriteMetadataCheck.Checked
+	cfg.HeadBeforeDownload = u.components.HeadBeforeDownloadCheck.Checked
+	if u.components.CleanPartialsCheck.Checked {
+		cfg.CleanPartialsOlderThan = aws.StalePartialAge
+	}
+	cfg.ReportPath = strings.TrimSpace(u.components.ReportPathEntry.Text)
+	cfg.ManifestPath = strings.TrimSpace(u.components.ManifestPathEntry.Text)
+	cfg.StateFile = strings.TrimSpace(u.components.StateFileEntry.Text)
+	cfg.CheckFreeSpace = !u.ignoreFreeSpace
+	cfg.DownloadArchived = u.components.DownloadArchivedCheck.Checked
+	cfg.RestoreArchived = u.components.RestoreArchivedCheck.Checked
+	cfg.RestoreTier = u.components.RestoreTierSelect.Selected
+	restoreDays, err := parseRestoreDays(u.components.RestoreDaysEntry.Text)
+	if err != nil {
+		return nil, err
+	}
+	cfg.RestoreDays = restoreDays
+	cfg.IncludePatterns = splitPatterns(u.components.IncludeEntry.Text)
+	cfg.ExcludePatterns = splitPatterns(u.components.ExcludeEntry.Text)
+	tagFilters, err := parseTagFilters(u.components.TagFilterEntry.Text)
+	if err != nil {
+		return nil, err
+	}
+	cfg.TagFilters = tagFilters
+	cfg.ContentTypePrefixes = splitPatterns(u.components.ContentTypeEntry.Text)
+	maxRecent, err := parseMaxRecent(u.components.MaxRecentEntry.Text)
+	if err != nil {
+		return nil, err
+	}
+	cfg.MaxRecentFiles = maxRecent
+	maxBytesPerSec, err := parseMaxSpeed(u.components.MaxSpeedEntry.Text)
+	if err != nil {
+		return nil, err
+	}
+	cfg.MaxBytesPerSec = maxBytesPerSec
+
+	d, err := aws.NewDownloaderWithConfig(u.components.AwsRegionEntry.Text, u.components.AwsAccessKeyEntry.Text, u.components.AwsSecretKeyEntry.Text, cfg)
+	if err != nil {
+		return nil, err
+	}
+	d.SetLogger(u.logger)
+	return d, nil
+}
+
+// parseMaxSpeed converts a speed to bytes per second: a plain number is MB/s, and a
+// size with a unit such as 512KiB is per second. Empty means unlimited.
+func parseMaxSpeed(text string) (int64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	if mbps, err := strconv.ParseFloat(text, 64); err == nil {
+		if mbps < 0 {
+			return 0, fmt.Errorf("invalid max speed '%s': must not be negative", text)
+		}
+		return int64(mbps * 1024 * 1024), nil
+	}
+	bytesPerSec, err := fileutils.ParseSize(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max speed: enter a number of MB/s or a size such as 512KiB: %w", err)
+	}
+	return bytesPerSec, nil
+}
+
+// parseRestoreDays parses the retention of restored copies; empty means the default
+func parseRestoreDays(text string) (int64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	days, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || days < 1 {
+		return 0, fmt.Errorf("invalid restore days '%s': enter a whole number of days", text)
+	}
+	return days, nil
+}
+
+// parseMaxRecent parses how many of the newest files to download; empty means all
+func parseMaxRecent(text string) (int, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid number of newest files '%s': enter a whole number", text)
+	}
+	return n, nil
+}
+
+// splitPatterns splits a comma-separated list of glob patterns, dropping empty entries
+func splitPatterns(text string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(text, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// parseTagFilters parses comma-separated key=value pairs; empty means no tag filtering
+func parseTagFilters(text string) (map[string]string, error) {
+	var filters map[string]string
+	for _, pair := range splitPatterns(text) {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid tag filter '%s': enter tags as key=value", pair)
+		}
+		if filters == nil {
+			filters = make(map[string]string)
+		}
+		filters[key] = strings.TrimSpace(value)
+	}
+	return filters, nil
+}
+
+// ValidateBucket checks that the bucket is reachable with the entered settings
+func (u *UIManager) ValidateBucket() {
+	bucket := u.components.BucketEntry.Text
+	if bucket == "" {
+		dialog.ShowInformation("Missing Information", "Please enter a bucket name", u.window)
+		return
+	}
+	u.logger.Info("user action", "action", "validate bucket", "bucket", bucket)
+
+	downloader, err := u.newDownloader()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to create downloader: %w", err), u.window)
+		return
+	}
+
+	u.components.ValidateButton.Disable()
+	go func() {
+		defer u.components.ValidateButton.Enable()
+
+		ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+		defer cancel()
+
+		// Instance and assumed roles are only resolved here, under the timeout
+		if err := downloader.CheckCredentials(ctx); err != nil {
+			u.logger.Warn("validation failed", "bucket", bucket, "error", err)
+			dialog.ShowError(err, u.window)
+			return
+		}
+
+		// S3-compatible services generally have no STS, so only check credentials against AWS;
+		// anonymous requests have none to check
+		message := fmt.Sprintf("Bucket '%s' is accessible", bucket)
+		if u.components.EndpointEntry.Text == "" && !u.components.AnonymousCheck.Checked {
+			identity, err := downloader.ValidateCredentials(ctx)
+			if err != nil {
+				u.logger.Warn("validation failed", "bucket", bucket, "error", err)
+				dialog.ShowError(err, u.window)
+				return
+			}
+			message += fmt.Sprintf("\nAccount: %s\nIdentity: %s", identity.Account, identity.ARN)
+		}
+
+		if err := downloader.ValidateBucketExists(ctx, bucket); err != nil {
+			u.logger.Warn("validation failed", "bucket", bucket, "error", err)
+			var regionErr *aws.WrongRegionError
+			if errors.As(err, &regionErr) {
+				u.offerRegionFix(regionErr)
+				return
+			}
+			dialog.ShowError(err, u.window)
+			return
+		}
+		u.rememberBucket(bucket)
+
+		// A reachable bucket may still refuse listing or reading its objects
+		access := downloader.CheckAccess(ctx, bucket, u.components.PrefixEntry.Text)
+		message += "\n" + access.String()
+		if err := errors.Join(access.ListErr, access.ReadErr); err != nil {
+			u.logger.Warn("missing permissions", "bucket", bucket, "access", access.String(), "error", err)
+			dialog.ShowInformation("Missing Permissions", message+fmt.Sprintf("\n\nDownloads will fail: %v", err), u.window)
+			return
+		}
+		dialog.ShowInformation("Bucket Valid", message, u.window)
+	}()
+}
+
+// offerRegionFix asks whether to switch the region field to the region the
+// bucket is actually in
+func (u *UIManager) offerRegionFix(regionErr *aws.WrongRegionError) {
+	message := fmt.Sprintf("Bucket '%s' is in region '%s'.\n\nSwitch the region to '%s'?", regionErr.Bucket, regionErr.Expected, regionErr.Expected)
+	dialog.ShowConfirm("Wrong Region", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		u.logger.Info("user action", "action", "fix region", "from", u.components.AwsRegionEntry.Text, "to", regionErr.Expected)
+		u.components.AwsRegionEntry.SetText(regionErr.Expected)
+		u.components.LogPanel.Log(fmt.Sprintf("Region changed to '%s'", regionErr.Expected))
+	}, u.window)
+}
+
+// LoadKeysFile lets the user pick a text file of object keys to download instead of a prefix
+func (u *UIManager) LoadKeysFile() {
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, u.window)
+			return
+		}
+		if reader == nil {
+			return // Dialog canceled
+		}
+		path := reader.URI().Path()
+		reader.Close()
+
+		keys, err := fileutils.ReadLines(path)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to read keys file: %w", err), u.window)
+			return
+		}
+		if len(keys) == 0 {
+			dialog.ShowInformation("Empty Keys File", "The selected file does not contain any keys", u.window)
+			return
+		}
+
+		u.keys = keys
+		u.components.KeysLabel.SetText(fmt.Sprintf("%d keys from %s (prefix ignored)", len(keys), filepath.Base(path)))
+		u.components.ClearKeysButton.Show()
+	}, u.window)
+}
+
+// ClearKeys discards the loaded keys so the prefix is listed again
+func (u *UIManager) ClearKeys() {
+	u.keys = nil
+	u.components.KeysLabel.SetText("No keys file loaded")
+	u.components.ClearKeysButton.Hide()
+}
+
+// fillFromS3URI sets the bucket, prefix and, if the URL names one, region from
+// text that looks like a URI, reporting whether it did. Malformed URIs show an
+// error and count as handled.
+func (u *UIManager) fillFromS3URI(text string) bool {
+	if !strings.Contains(text, "://") {
+		return false
+	}
+	loc, err := aws.ParseS3Location(text)
+	if err != nil {
+		dialog.ShowError(err, u.window)
+		return true
+	}
+	u.applyS3Location(loc)
+	return true
+}
+
+// validateRegion flags regions AWS does not have, which would only fail once
+// the download starts. Any region goes with a custom endpoint.
+func (u *UIManager) validateRegion(region string) error {
+	if u.components.EndpointEntry.Text != "" || aws.KnownRegion(region) {
+		return nil
+	}
+	return fmt.Errorf("'%s' is not an AWS region", region)
+}
+
+// bucketChanged splits URIs entered in the bucket field and fills in the region
+// a recently used bucket was last used with
+func (u *UIManager) bucketChanged(text string) {
+	if strings.Contains(text, "://") {
+		u.splitBucketURI(text)
+		return
+	}
+	if region := u.recent.Region(text); region != "" {
+		u.components.AwsRegionEntry.SetText(region)
+	}
+}
+
+// rememberBucket moves bucket to the front of the recently used buckets
+func (u *UIManager) rememberBucket(bucket string) {
+	u.recent.Add(RecentBucket{Name: bucket, Region: u.components.AwsRegionEntry.Text})
+	u.recent.save(fyne.CurrentApp().Preferences())
+	u.components.BucketEntry.SetOptions(u.recent.Names())
+}
+
+// splitBucketURI replaces an S3 URI typed or dropped into the bucket field with
+// its bucket, moving the rest to the other fields. Partial URIs being typed are
+// left alone.
+func (u *UIManager) splitBucketURI(text string) {
+	if !strings.Contains(text, "://") {
+		return
+	}
+	if loc, err := aws.ParseS3Location(text); err == nil {
+		u.applyS3Location(loc)
+	}
+}
+
+// applyS3Location fills the bucket, prefix and region fields
+func (u *UIManager) applyS3Location(loc aws.S3Location) {
+	u.components.BucketEntry.SetText(loc.Bucket)
+	u.components.PrefixEntry.SetText(loc.Prefix)
+	if loc.Region != "" {
+		u.components.AwsRegionEntry.SetText(loc.Region)
+	}
+}
+
+// refreshProfiles reloads the profile names into the dropdown and shows selected
+// without applying it
+func (u *UIManager) refreshProfiles(selected string) {
+	names, err := u.profiles.List()
+	if err != nil {
+		dialog.ShowError(err, u.window)
+		return
+	}
+	u.components.ProfileSelect.Options = names
+	u.components.ProfileSelect.Selected = selected
+	u.components.ProfileSelect.Refresh()
+}
+
+// SelectProfile fills the connection fields from the named profile
+func (u *UIManager) SelectProfile(name string) {
+	if name == "" {
+		return
+	}
+	p, err := u.profiles.Load(name)
+	if err != nil {
+		dialog.ShowError(err, u.window)
+		return
+	}
+	if p.AccessKey != "" {
+		u.components.AnonymousCheck.SetChecked(false)
+	}
+	// The bucket goes first so its recently used region does not override the profile's
+	u.components.BucketEntry.SetText(p.Bucket)
+	u.components.AwsRegionEntry.SetText(p.Region)
+	u.components.PrefixEntry.SetText(p.Prefix)
+	u.components.AwsAccessKeyEntry.SetText(p.AccessKey)
+	u.components.EndpointEntry.SetText(p.Endpoint)
+	// A secret left over from another account would not match the access key
+	secret, err := u.profiles.Secret(p)
+	u.components.AwsSecretKeyEntry.SetText(secret)
+	u.components.StoreSecretCheck.SetChecked(secret != "")
+	if err != nil {
+		u.logger.Warn("failed to load secret key", "profile", name, "error", err)
+		dialog.ShowInformation("Secret Key Not Loaded",
+			fmt.Sprintf("%v\n\nEnter the secret key to use this profile.", err), u.window)
+	}
+}
+
+// SaveProfile asks for a name and stores the connection fields under it. The
+// secret key is only included when the user opted in, and then goes to the
+// system keyring; without one the profile is saved without it.
+func (u *UIManager) SaveProfile() {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(u.components.ProfileSelect.Selected)
+	dialog.ShowForm("Save Connection Profile", "Save", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Name", nameEntry),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		p := Profile{
+			Name:      nameEntry.Text,
+			Region:    u.components.AwsRegionEntry.Text,
+			Bucket:    u.components.BucketEntry.Text,
+			Prefix:    u.components.PrefixEntry.Text,
+			AccessKey: u.components.AwsAccessKeyEntry.Text,
+			Endpoint:  u.components.EndpointEntry.Text,
+		}
+		var secret string
+		if u.components.StoreSecretCheck.Checked {
+			secret = u.components.AwsSecretKeyEntry.Text
+		}
+		err := u.profiles.Save(p, secret)
+		if err != nil && !errors.Is(err, ErrSecretNotStored) {
+			dialog.ShowError(err, u.window)
+			return
+		}
+		u.logger.Info("user action", "action", "save profile", "profile", p.Name, "secretStored", secret != "" && err == nil)
+		u.refreshProfiles(strings.TrimSpace(p.Name))
+		if err != nil {
+			u.logger.Warn("failed to store secret key", "profile", p.Name, "error", err)
+			dialog.ShowInformation("Secret Key Not Stored",
+				fmt.Sprintf("%v\n\nThe profile was saved without the secret key, which is never written to disk\nunencrypted. Enter it again when you use this profile.", err), u.window)
+		}
+	}, u.window)
+}
+
+// DeleteProfile removes the selected profile after confirmation
+func (u *UIManager) DeleteProfile() {
+	name := u.components.ProfileSelect.Selected
+	if name == "" {
+		dialog.ShowInformation("No Profile Selected", "Select the connection profile to delete", u.window)
+		return
+	}
+	dialog.ShowConfirm("Delete Profile", fmt.Sprintf("Delete the connection profile '%s'?", name), func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if err := u.profiles.Delete(name); err != nil {
+			dialog.ShowError(err, u.window)
+			return
+		}
+		u.logger.Info("user action", "action", "delete profile", "profile", name)
+		u.refreshProfiles("")
+	}, u.window)
 }
 
-func (u *UIManager) downloadFiles(bucket, prefix, downloadPath string, progressChan chan progress.Progress, doneChan chan struct{}) {
+// downloadFiles runs a download started by run, showing its progress and outcome.
+// keys are the exact keys it downloads, if it does not list a prefix.
+func (u *UIManager) downloadFiles(keys []string, run func(ctx context.Context) ([]string, error)) {
 	ctx, cancel := context.WithCancel(context.Background())
 	u.cancelFunc = cancel
 
-	var finalProgress progress.Progress
-
-	// Update progress in a separate goroutine
+	// Repaint from the downloader's progress snapshot on a fixed interval,
+	// independent of how many events the workers produce
+	stopChan := make(chan struct{})
+	doneChan := make(chan struct{})
 	go func() {
-		for p := range progressChan {
-			u.updateProgress(p)
-			finalProgress = p // Keep the last progress update for the summary
+		defer close(doneChan)
+		ticker := time.NewTicker(progress.UpdateInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				u.updateProgress(u.downloader.Progress())
+			case <-stopChan:
+				return
+			}
 		}
-		doneChan <- struct{}{}
 	}()
 
 	/*
@@ -130,49 +756,241 @@ func (u *UIManager) downloadFiles(bucket, prefix, downloadPath string, progressC
 		}
 	*/
 
-	// List and download objects using the downloader
-	err := u.downloader.ListAndDownloadObjects(ctx, bucket, prefix, downloadPath, progressChan)
+	destination := u.components.FilePathEntry.Text
+	go u.showDestinationSize(destination)
+
+	missing, err := run(ctx)
 
-	close(progressChan)
+	close(stopChan)
 	<-doneChan // Wait for the progress update goroutine to finish
 
+	// Final repaint so the display always reflects the finished run
+	finalProgress := u.downloader.Progress()
+	u.updateProgress(finalProgress)
+
 	u.components.ProgressBar.SetValue(0)
 	u.components.ProgressBar.Hide()
+	u.components.ElapsedLabel.SetText("Elapsed: " + idleTime)
+	u.components.EtaLabel.SetText("Remaining: " + idleTime)
+	u.showDestinationSize(destination)
 	u.enableInputs()
+	if failed := len(u.downloader.Failures()); failed > 0 {
+		u.components.RetryButton.SetText(fmt.Sprintf("Retry %d failed", failed))
+		u.components.RetryButton.Show()
+		u.components.ErrorsButton.Show()
+	}
 
 	elapsedTime := time.Since(u.downloadStartTime) // Calculate the elapsed time
+	u.notify(finalProgress, err, elapsedTime)
 
-	if err != nil {
+	var spaceErr *aws.InsufficientSpaceError
+	var regionErr *aws.WrongRegionError
+	if errors.As(err, &spaceErr) {
+		// Nothing much was written yet; let the user decide whether to go ahead anyway
+		u.components.StatusLabel.SetText("Stopped: not enough disk space")
+		u.components.LogPanel.Log(fmt.Sprintf("Stopped: %v", err))
+		dialog.ShowConfirm("Not Enough Disk Space", fmt.Sprintf("%s.\n\nDownload anyway?", spaceErr), func(proceed bool) {
+			if proceed {
+				u.ignoreFreeSpace = true
+				u.StartDownload()
+				u.ignoreFreeSpace = false
+			}
+		}, u.window)
+	} else if errors.As(err, &regionErr) {
+		u.components.StatusLabel.SetText("Failed: wrong region")
+		u.components.LogPanel.Log(fmt.Sprintf("Failed: %v", err))
+		u.offerRegionFix(regionErr)
+	} else if err != nil {
 		dialog.ShowError(fmt.Errorf("failed to list or download objects: %w", err), u.window)
-		u.components.StatusLabel.SetText("Failed")
+		u.components.StatusLabel.SetText(fmt.Sprintf("Failed\nErrors: %d", finalProgress.ErrorCount))
+		u.components.LogPanel.Log(fmt.Sprintf("Failed after %s: %v", formatElapsedTime(elapsedTime), err))
+	} else if finalProgress.FilesFound == 0 {
+		// Nothing matched; this is not an error but must not look like a successful download
+		hint := "Check that the bucket name and prefix are correct."
+		if finalProgress.ArchivedSkipped > 0 {
+			hint = fmt.Sprintf("%d archived objects were skipped; enable downloading archived objects to include them.", finalProgress.ArchivedSkipped)
+		} else if len(keys) > 0 {
+			hint = "None of the keys in the loaded file exist in the bucket."
+		}
+		u.components.StatusLabel.SetText("No files matched\n" + hint)
+		u.components.LogPanel.Log("No files matched")
 	} else {
-		summary := fmt.Sprintf("Download complete\nFiles found: %d\nDownloads: %d\nSkipped: %d\nTime taken: %s",
-			finalProgress.FilesFound, finalProgress.FilesDownloaded, finalProgress.FilesSkipped, formatElapsedTime(elapsedTime))
+		summary := fmt.Sprintf("Download complete\nFiles found: %d\nDownloads: %d\nSkipped: %d\nArchived: %d\nErrors: %d\nTime taken: %s",
+			finalProgress.FilesFound, finalProgress.FilesDownloaded-finalProgress.FilesSkipped, finalProgress.FilesSkipped, finalProgress.ArchivedSkipped, finalProgress.ErrorCount, formatElapsedTime(elapsedTime))
+		if finalProgress.TagFiltered > 0 {
+			summary += fmt.Sprintf("\nNot matching tags: %d", finalProgress.TagFiltered)
+		}
+		if finalProgress.TypeFiltered > 0 {
+			summary += fmt.Sprintf("\nNot matching content types: %d", finalProgress.TypeFiltered)
+		}
+		if finalProgress.WarningCount > 0 {
+			summary += fmt.Sprintf("\nWarnings: %d (metadata could not be saved)", finalProgress.WarningCount)
+		}
+		if finalProgress.Phase == progress.PhaseCleaning {
+			summary += fmt.Sprintf("\nStale local files deleted: %d", finalProgress.FilesDeleted)
+		}
 		u.components.StatusLabel.SetText(summary)
+		u.components.LogPanel.Log(fmt.Sprintf("Finished in %s: %d downloaded, %d skipped, %d errors",
+			formatElapsedTime(elapsedTime), finalProgress.FilesDownloaded-finalProgress.FilesSkipped, finalProgress.FilesSkipped, finalProgress.ErrorCount))
+	}
+
+	for _, key := range missing {
+		u.components.LogPanel.Log(fmt.Sprintf("Not found: '%s'", key))
+	}
+	if len(missing) > 0 {
+		dialog.ShowInformation("Missing Keys", fmt.Sprintf("%d keys were not found in the bucket:\n%s",
+			len(missing), strings.Join(missing, "\n")), u.window)
+	}
+}
+
+// AddToQueue queues a download of the bucket, prefix and keys entered in the form,
+// using the options set when it is added
+func (u *UIManager) AddToQueue() {
+	bucket := u.components.BucketEntry.Text
+	downloadPath := u.components.FilePathEntry.Text
+	if bucket == "" || downloadPath == "" {
+		dialog.ShowInformation("Missing Information", "Please fill in all required fields", u.window)
+		return
+	}
+	u.saveSettings()
+	u.rememberBucket(bucket)
+
+	downloader, err := u.newDownloader()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to create downloader: %w", err), u.window)
+		return
+	}
+	downloader.SetLogSink(u.components.LogPanel)
+
+	job := Job{Bucket: bucket, Prefix: u.components.PrefixEntry.Text, Keys: u.keys, DownloadPath: downloadPath}
+	id := u.queue.Add(job, downloader)
+	u.components.LogPanel.Log(fmt.Sprintf("Queued job %d: %s", id, job))
+	u.logger.Info("user action", "action", "queue job", "job", id, "bucket", bucket, "prefix", job.Prefix, "path", downloadPath)
+}
+
+// CancelSelectedJob cancels the job selected in the queue list
+func (u *UIManager) CancelSelectedJob() {
+	if u.selectedJob != 0 {
+		u.logger.Info("user action", "action", "cancel job", "job", u.selectedJob)
+		u.queue.Cancel(u.selectedJob)
+	}
+}
+
+// setupQueueList shows one row per queued job with its status and progress
+func (u *UIManager) setupQueueList() {
+	list := u.components.QueueList
+	list.Length = func() int {
+		return len(u.queue.Status())
+	}
+	list.CreateItem = func() fyne.CanvasObject {
+		return widget.NewLabel("")
+	}
+	list.UpdateItem = func(id widget.ListItemID, item fyne.CanvasObject) {
+		jobs := u.queue.Status()
+		if id >= len(jobs) {
+			return
+		}
+		item.(*widget.Label).SetText(formatJob(jobs[id]))
+	}
+	list.OnSelected = func(id widget.ListItemID) {
+		if jobs := u.queue.Status(); id < len(jobs) {
+			u.selectedJob = jobs[id].ID
+		}
+	}
+	list.OnUnselected = func(widget.ListItemID) {
+		u.selectedJob = 0
+	}
+}
+
+// jobChanged repaints the queue after a job was added, made progress or finished
+func (u *UIManager) jobChanged(job Job) {
+	jobs := u.queue.Status()
+	finished, fraction := overallProgress(jobs)
+	u.components.QueueLabel.SetText(fmt.Sprintf("Queue: %d of %d jobs finished", finished, len(jobs)))
+	u.components.QueueProgressBar.SetValue(fraction)
+	u.components.QueueList.Refresh()
+
+	switch job.Status {
+	case JobDone:
+		u.components.LogPanel.Log(fmt.Sprintf("Job %d finished: %d downloaded, %d skipped, %d errors",
+			job.ID, job.Progress.FilesDownloaded-job.Progress.FilesSkipped, job.Progress.FilesSkipped, job.Progress.ErrorCount))
+		for _, key := range job.Missing {
+			u.components.LogPanel.Log(fmt.Sprintf("Not found: '%s'", key))
+		}
+	case JobFailed:
+		u.components.LogPanel.Log(fmt.Sprintf("Job %d failed: %v", job.ID, job.Err))
+	case JobCanceled:
+		u.components.LogPanel.Log(fmt.Sprintf("Job %d canceled", job.ID))
+	}
+}
+
+// formatJob describes a job in the queue list
+func formatJob(job Job) string {
+	text := fmt.Sprintf("#%d %s: %s", job.ID, job, job.Status)
+	if job.Status == JobRunning {
+		text += fmt.Sprintf(" %.0f%%", progressFraction(job.Progress)*100)
 	}
+	return text
 }
 
 // StopDownload cancels the ongoing download process
 func (u *UIManager) StopDownload() {
 	if u.cancelFunc != nil {
+		u.logger.Info("user action", "action", "stop download")
 		u.cancelFunc()
 	}
 }
 
+// TogglePause pauses the ongoing download or resumes a paused one
+func (u *UIManager) TogglePause() {
+	if u.downloader == nil {
+		return
+	}
+	if u.downloader.Paused() {
+		u.logger.Info("user action", "action", "resume download")
+		u.downloader.Resume()
+		u.components.PauseButton.SetText("Pause")
+	} else {
+		u.logger.Info("user action", "action", "pause download")
+		u.downloader.Pause()
+		u.components.PauseButton.SetText("Resume")
+	}
+}
+
 // updateProgress updates the progress bar and status label
 func (u *UIManager) updateProgress(p progress.Progress) {
 	filesFound := p.FilesFound
-	filesDownloaded := p.FilesDownloaded
-	if filesFound == 0 {
-		u.components.ProgressBar.SetValue(0)
+	filesDownloaded := p.FilesDownloaded - p.FilesSkipped
+	u.components.ProgressBar.SetValue(progressFraction(p))
+	if p.ListingComplete {
+		u.components.ProgressBar.TextFormatter = nil
 	} else {
-		u.components.ProgressBar.SetValue(float64(filesDownloaded) / float64(filesFound))
+		// The total keeps growing while listing, so the bar may move backwards
+		value := u.components.ProgressBar.Value
+		u.components.ProgressBar.TextFormatter = func() string {
+			return fmt.Sprintf("%.0f%% of the files listed so far", value*100)
+		}
 	}
 
-	elapsedTime := time.Since(u.downloadStartTime) // Calculate the elapsed time
+	elapsed, remaining := progressTimes(p, time.Since(u.downloadStartTime))
+	u.components.ElapsedLabel.SetText(elapsed)
+	u.components.EtaLabel.SetText(remaining)
 
-	u.components.StatusLabel.SetText(fmt.Sprintf("Files found: %d, Downloaded: %d, Skipped: %d Elapsed time: %s",
-		filesFound, filesDownloaded, p.FilesSkipped, formatElapsedTime(elapsedTime)))
+	status := fmt.Sprintf("Files found: %d, Downloaded: %d, Skipped: %d, Archived: %d, Errors: %d",
+		filesFound, filesDownloaded, p.FilesSkipped, p.ArchivedSkipped, p.ErrorCount)
+	if p.Phase == progress.PhaseDownloading && p.CurrentFile.Key != "" {
+		status += fmt.Sprintf("\nDownloading %s: %.1f MB", p.CurrentFile.Key, float64(p.CurrentFile.Bytes)/(1024*1024))
+	}
+	if u.downloader != nil && u.downloader.Paused() {
+		status += "\nPaused: files already downloading finish, the rest wait for Resume"
+	}
+	if p.FilesRestoring > 0 {
+		status += fmt.Sprintf("\nWaiting for %d archived objects to be restored", p.FilesRestoring)
+	}
+	if p.Phase == progress.PhaseCleaning {
+		status += fmt.Sprintf("\nMirroring: scanned %d local files, deleted %d", p.FilesScanned, p.FilesDeleted)
+	}
+	u.components.StatusLabel.SetText(status)
 	u.window.Canvas().Refresh(u.components.ProgressBar)
 	fyne.CurrentApp().Driver().CanvasForObject(u.components.StatusLabel).Refresh(u.components.StatusLabel)
 }
@@ -180,25 +998,124 @@ func (u *UIManager) updateProgress(p progress.Progress) {
 // disableInputs disables all input fields during the download process
 func (u *UIManager) disableInputs() {
 	for _, w := range []fyne.Disableable{
-		u.components.BucketEntry, u.components.PrefixEntry, u.components.FilePathEntry,
-		u.components.AwsAccessKeyEntry, u.components.AwsSecretKeyEntry, u.components.AwsRegionEntry,
-		u.components.OverwriteCheck, u.components.DownloadButton, u.components.ShowSecretCheck,
+		u.components.BucketEntry, u.components.PrefixEntry, u.components.IncludeEntry, u.components.ExcludeEntry, u.components.TagFilterEntry, u.components.ContentTypeEntry, u.components.MaxRecentEntry, u.components.FilePathEntry, u.components.FlattenCheck, u.components.StripPrefixCheck, u.components.FolderMarkersCheck,
+		u.components.AnonymousCheck, u.components.AwsAccessKeyEntry, u.components.AwsSecretKeyEntry, u.components.AwsTokenEntry, u.components.AwsRegionEntry, u.components.AwsProfileEntry,
+		u.components.EndpointEntry, u.components.PathStyleCheck, u.components.AccelerateCheck, u.components.CACertEntry, u.components.InsecureSkipVerifyCheck, u.components.RequesterPaysCheck, u.components.VerifyCheck, u.components.DecompressGzipCheck, u.components.StripGzipSuffixCheck, u.components.WriteMetadataCheck, u.components.HeadBeforeDownloadCheck, u.components.CleanPartialsCheck, u.components.FailFastCheck, u.components.MirrorCheck, u.components.DownloadArchivedCheck,
+		u.components.RestoreArchivedCheck, u.components.RestoreTierSelect, u.components.RestoreDaysEntry, u.components.ReportPathEntry, u.components.ManifestPathEntry, u.components.StateFileEntry, u.components.ValidateButton,
+		u.components.OverwriteCheck, u.components.IndexCheck, u.components.ResumeCheck, u.components.VersionsCheck, u.components.SkipUnchangedCheck, u.components.PerformanceSelect, u.components.AdaptiveCheck, u.components.MaxSpeedEntry, u.components.DownloadButton, u.components.QueueButton, u.components.ShowSecretCheck,
+		u.components.LoadKeysButton, u.components.ClearKeysButton, u.components.ProfileSelect, u.components.SaveProfileButton, u.components.DeleteProfileButton, u.components.StoreSecretCheck,
 	} {
 		w.Disable()
 	}
 	u.components.StopButton.Show()
+	u.components.RetryButton.Hide()
+	u.components.ErrorsButton.Hide()
+	u.components.PauseButton.SetText("Pause")
+	u.components.PauseButton.Show()
 }
 
 // enableInputs enables all input fields after the download process
 func (u *UIManager) enableInputs() {
 	for _, w := range []fyne.Disableable{
-		u.components.BucketEntry, u.components.PrefixEntry, u.components.FilePathEntry,
-		u.components.AwsAccessKeyEntry, u.components.AwsSecretKeyEntry, u.components.AwsRegionEntry,
-		u.components.OverwriteCheck, u.components.DownloadButton, u.components.ShowSecretCheck,
+		u.components.BucketEntry, u.components.PrefixEntry, u.components.IncludeEntry, u.components.ExcludeEntry, u.components.TagFilterEntry, u.components.ContentTypeEntry, u.components.MaxRecentEntry, u.components.FilePathEntry, u.components.FlattenCheck, u.components.StripPrefixCheck, u.components.FolderMarkersCheck,
+		u.components.AnonymousCheck, u.components.AwsAccessKeyEntry, u.components.AwsSecretKeyEntry, u.components.AwsTokenEntry, u.components.AwsRegionEntry, u.components.AwsProfileEntry,
+		u.components.EndpointEntry, u.components.PathStyleCheck, u.components.AccelerateCheck, u.components.CACertEntry, u.components.InsecureSkipVerifyCheck, u.components.RequesterPaysCheck, u.components.VerifyCheck, u.components.DecompressGzipCheck, u.components.StripGzipSuffixCheck, u.components.WriteMetadataCheck, u.components.HeadBeforeDownloadCheck, u.components.CleanPartialsCheck, u.components.FailFastCheck, u.components.MirrorCheck, u.components.DownloadArchivedCheck,
+		u.components.RestoreArchivedCheck, u.components.RestoreTierSelect, u.components.RestoreDaysEntry, u.components.ReportPathEntry, u.components.ManifestPathEntry, u.components.StateFileEntry, u.components.ValidateButton,
+		u.components.OverwriteCheck, u.components.IndexCheck, u.components.ResumeCheck, u.components.VersionsCheck, u.components.SkipUnchangedCheck, u.components.PerformanceSelect, u.components.AdaptiveCheck, u.components.MaxSpeedEntry, u.components.DownloadButton, u.components.QueueButton, u.components.ShowSecretCheck,
+		u.components.LoadKeysButton, u.components.ClearKeysButton, u.components.ProfileSelect, u.components.SaveProfileButton, u.components.DeleteProfileButton, u.components.StoreSecretCheck,
 	} {
 		w.Enable()
 	}
+	if u.components.AnonymousCheck.Checked {
+		u.setAnonymous(true)
+	}
 	u.components.StopButton.Hide()
+	u.components.PauseButton.Hide()
+}
+
+// setAnonymous clears and disables the credential fields while anonymous access
+// is on, so that no key is sent or saved with it, and enables them again after
+func (u *UIManager) setAnonymous(anonymous bool) {
+	for _, e := range []*widget.Entry{u.components.AwsAccessKeyEntry, u.components.AwsSecretKeyEntry, u.components.AwsTokenEntry, u.components.AwsProfileEntry} {
+		if anonymous {
+			e.SetText("")
+			e.Disable()
+		} else {
+			e.Enable()
+		}
+	}
+}
+
+// progressFraction returns the share of the expected bytes downloaded so far, or of
+// the files when the objects found so far are empty
+func progressFraction(p progress.Progress) float64 {
+	var fraction float64
+	switch {
+	case p.TotalBytesExpected > 0:
+		fraction = float64(p.TotalBytes) / float64(p.TotalBytesExpected)
+	case p.FilesFound > 0:
+		fraction = float64(p.Completed()) / float64(p.FilesFound)
+	}
+	// Decompressed objects can write more bytes than their listed size
+	return math.Min(fraction, 1)
+}
+
+// progressTimes returns the texts of the elapsed and remaining time labels. The
+// remaining time is estimated from the average speed so far, and only once all
+// objects are listed, since the total grows until then.
+func progressTimes(p progress.Progress, elapsed time.Duration) (string, string) {
+	var bytesPerSec float64
+	if elapsed > 0 {
+		bytesPerSec = float64(p.TotalBytes) / elapsed.Seconds()
+	}
+	remaining := idleTime
+	if p.ListingComplete {
+		remaining = formatETA(p.TotalBytesExpected-p.TotalBytes, bytesPerSec)
+	}
+	return "Elapsed: " + formatElapsedTime(elapsed),
+		fmt.Sprintf("Remaining: %s (%.1f MB/s)", remaining, bytesPerSec/(1024*1024))
+}
+
+// showDestinationSize shows how much data the download path holds next to it,
+// or nothing if it cannot be measured, as before it is created
+func (u *UIManager) showDestinationSize(path string) {
+	size, err := fileutils.DirectorySize(path)
+	if err != nil {
+		u.logger.Debug("failed to measure download path", "path", path, "error", err)
+		u.components.DestinationSizeLabel.SetText("")
+		return
+	}
+	u.components.DestinationSizeLabel.SetText(formatBytes(size) + " in use")
+}
+
+// formatBytes formats a byte count with a binary unit, like "1.5 GB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n) / unit
+	suffix := "KB"
+	for _, next := range []string{"MB", "GB", "TB"} {
+		if value < unit {
+			break
+		}
+		value /= unit
+		suffix = next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+// formatETA formats the time needed to download the remaining bytes at bytesPerSec,
+// or "--:--" when the speed is zero or unknown
+func formatETA(remaining int64, bytesPerSec float64) string {
+	if bytesPerSec <= 0 || math.IsNaN(bytesPerSec) || math.IsInf(bytesPerSec, 0) {
+		return "--:--"
+	}
+	if remaining <= 0 {
+		return formatElapsedTime(0)
+	}
+	return formatElapsedTime(time.Duration(float64(remaining) / bytesPerSec * float64(time.Second)))
 }
 
 // formatElapsedTime formats a duration into a human-readable string
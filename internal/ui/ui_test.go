@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+
+	"s3downloader/internal/logging"
+	"s3downloader/internal/progress"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatETA(t *testing.T) {
+	testCases := []struct {
+		name        string
+		remaining   int64
+		bytesPerSec float64
+		want        string
+	}{
+		{"Seconds", 5 * 1024 * 1024, 1024 * 1024, "00:00:05"},
+		{"Minutes", 90 * 1000, 1000, "00:01:30"},
+		{"Hours", 3 * 3600 * 1000, 1000, "03:00:00"},
+		{"Nothing left", 0, 1000, "00:00:00"},
+		{"Overshoot", -10, 1000, "00:00:00"},
+		{"Zero speed", 1000, 0, "--:--"},
+		{"Negative speed", 1000, -1, "--:--"},
+		{"Unknown speed", 1000, math.NaN(), "--:--"},
+		{"Infinite speed", 1000, math.Inf(1), "--:--"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, formatETA(tc.remaining, tc.bytesPerSec))
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	testCases := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+		{3*1024*1024*1024 + 512*1024*1024, "3.5 GB"},
+		{2 * 1024 * 1024 * 1024 * 1024, "2.0 TB"},
+		{3000 * 1024 * 1024 * 1024 * 1024, "3000.0 TB"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.want, func(t *testing.T) {
+			assert.Equal(t, tc.want, formatBytes(tc.n))
+		})
+	}
+}
+
+func TestParseMaxSpeed(t *testing.T) {
+	testCases := []struct {
+		text    string
+		want    int64
+		wantErr string
+	}{
+		{"", 0, ""},
+		{"25", 25 * 1024 * 1024, ""},
+		{"0.5", 512 * 1024, ""},
+		{"512KiB", 512 * 1024, ""},
+		{"2MB", 2000000, ""},
+		{" 1 gib ", 1 << 30, ""},
+		{"-1", 0, "invalid max speed '-1': must not be negative"},
+		{"-1MB", 0, "must not be negative"},
+		{"fast", 0, "invalid max speed: enter a number of MB/s or a size such as 512KiB"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			got, err := parseMaxSpeed(tc.text)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestProgressFraction(t *testing.T) {
+	testCases := []struct {
+		name string
+		p    progress.Progress
+		want float64
+	}{
+		{"Nothing found", progress.Progress{}, 0},
+		{"By bytes", progress.Progress{FilesFound: 2, FilesDownloaded: 1, TotalBytes: 250, TotalBytesExpected: 1000}, 0.25},
+		{"Empty files", progress.Progress{FilesFound: 4, FilesDownloaded: 1, TagFiltered: 1}, 0.5},
+		{"Filtered by content type", progress.Progress{FilesFound: 4, FilesDownloaded: 1, TagFiltered: 1, TypeFiltered: 2}, 1},
+		{"All downloaded", progress.Progress{FilesFound: 3, FilesDownloaded: 3, TotalBytes: 17, TotalBytesExpected: 17}, 1},
+		{"All skipped", progress.Progress{FilesFound: 3, FilesDownloaded: 3, FilesSkipped: 3}, 1},
+		{"All skipped while listing", progress.Progress{FilesFound: 3, FilesDownloaded: 2, FilesSkipped: 2}, 2.0 / 3},
+		{"Mixed", progress.Progress{FilesFound: 3, FilesDownloaded: 3, FilesSkipped: 1, TotalBytes: 12, TotalBytesExpected: 12}, 1},
+		{"Mixed while downloading", progress.Progress{FilesFound: 3, FilesDownloaded: 2, FilesSkipped: 1, TotalBytes: 5, TotalBytesExpected: 10}, 0.5},
+		{"More bytes than listed", progress.Progress{FilesFound: 1, FilesDownloaded: 1, TotalBytes: 300, TotalBytesExpected: 100}, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, progressFraction(tc.p))
+		})
+	}
+}
+
+func TestValidateRegion(t *testing.T) {
+	test.NewApp()
+	u := &UIManager{components: NewComponents()}
+
+	assert.NoError(t, u.validateRegion("eu-west-1"))
+	assert.EqualError(t, u.validateRegion("us-east1"), "'us-east1' is not an AWS region")
+
+	u.components.EndpointEntry.SetText("http://localhost:9000")
+	assert.NoError(t, u.validateRegion("us-east1"), "custom endpoints take any region")
+}
+
+func TestProgressTimes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		p             progress.Progress
+		elapsed       time.Duration
+		wantElapsed   string
+		wantRemaining string
+	}{
+		{"Just started", progress.Progress{}, 0, "Elapsed: 00:00:00", "Remaining: -- (0.0 MB/s)"},
+		{"Still listing", progress.Progress{TotalBytes: 10 * 1024 * 1024, TotalBytesExpected: 20 * 1024 * 1024}, 5 * time.Second,
+			"Elapsed: 00:00:05", "Remaining: -- (2.0 MB/s)"},
+		{"Listed", progress.Progress{TotalBytes: 10 * 1024 * 1024, TotalBytesExpected: 40 * 1024 * 1024, ListingComplete: true}, 5 * time.Second,
+			"Elapsed: 00:00:05", "Remaining: 00:00:15 (2.0 MB/s)"},
+		{"Nothing downloaded yet", progress.Progress{TotalBytesExpected: 100, ListingComplete: true}, time.Hour + 2*time.Minute,
+			"Elapsed: 01:02:00", "Remaining: --:-- (0.0 MB/s)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			elapsed, remaining := progressTimes(tc.p, tc.elapsed)
+			assert.Equal(t, tc.wantElapsed, elapsed)
+			assert.Equal(t, tc.wantRemaining, remaining)
+		})
+	}
+}
+
+func TestStopDownloadIsLogged(t *testing.T) {
+	test.NewApp()
+	var buf bytes.Buffer
+	u := &UIManager{components: NewComponents()}
+	u.SetLogger(logging.New(&buf, slog.LevelInfo))
+
+	u.StopDownload()
+	assert.Empty(t, buf.String(), "nothing to stop")
+
+	canceled := false
+	u.cancelFunc = func() { canceled = true }
+	u.StopDownload()
+	assert.True(t, canceled)
+	assert.Contains(t, buf.String(), `"level":"INFO","msg":"user action","action":"stop download"`)
+}
+
+func TestAnonymousClearsCredentials(t *testing.T) {
+	test.NewApp()
+	u := &UIManager{components: NewComponents()}
+	u.components.AnonymousCheck.OnChanged = u.setAnonymous
+	credentials := []*widget.Entry{u.components.AwsAccessKeyEntry, u.components.AwsSecretKeyEntry, u.components.AwsTokenEntry, u.components.AwsProfileEntry}
+	for _, e := range credentials {
+		e.SetText("value")
+	}
+
+	u.components.AnonymousCheck.SetChecked(true)
+	for _, e := range credentials {
+		assert.Empty(t, e.Text)
+		assert.True(t, e.Disabled())
+	}
+
+	// A finished download leaves them disabled
+	u.disableInputs()
+	u.enableInputs()
+	for _, e := range credentials {
+		assert.True(t, e.Disabled())
+	}
+	assert.False(t, u.components.AnonymousCheck.Disabled())
+
+	u.components.AnonymousCheck.SetChecked(false)
+	for _, e := range credentials {
+		assert.False(t, e.Disabled())
+	}
+}
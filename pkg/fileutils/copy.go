@@ -0,0 +1,84 @@
+package fileutils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// rename moves files for MoveFile; tests replace it to simulate moves across filesystems
+var rename = os.Rename
+
+// CopyFile copies the contents and permissions of src to dst, creating the parent
+// directories of dst. The copy is written next to dst and moved into place once
+// complete, so a failure never leaves a partial file and keeps any existing dst.
+func CopyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open '%s': %w", src, err)
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read '%s': %w", src, err)
+	}
+
+	if err := EnsureDirectoryExists(filepath.Dir(dst)); err != nil {
+		return fmt.Errorf("failed to create directory for '%s': %w", dst, err)
+	}
+	out, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create '%s': %w", dst, err)
+	}
+	tmp := out.Name()
+	if err := writeCopy(out, in, info.Mode().Perm()); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to copy '%s' to '%s': %w", src, dst, err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to move copy of '%s' into place: %w", src, err)
+	}
+	return nil
+}
+
+// writeCopy fills out from in through a buffered writer, sets its permissions
+// and closes it
+func writeCopy(out *os.File, in io.Reader, mode os.FileMode) error {
+	w := bufio.NewWriter(out)
+	_, err := io.Copy(w, in)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = out.Chmod(mode)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// MoveFile moves src to dst, creating the parent directories of dst. When src
+// cannot be renamed, as across filesystems, it is copied and then removed.
+func MoveFile(src, dst string) error {
+	if err := EnsureDirectoryExists(filepath.Dir(dst)); err != nil {
+		return fmt.Errorf("failed to create directory for '%s': %w", dst, err)
+	}
+	err := rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	if _, statErr := os.Stat(src); statErr != nil {
+		return fmt.Errorf("failed to move '%s': %w", src, err)
+	}
+	if err := CopyFile(src, dst); err != nil {
+		return err
+	}
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("failed to remove '%s' after copying it: %w", src, err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package fileutils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyFileAcrossDirectories(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src", "report.csv")
+	dst := filepath.Join(dir, "dst", "nested", "report.csv")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(src), 0o755))
+	assert.NoError(t, os.WriteFile(src, []byte("a,b\n1,2\n"), 0o600))
+
+	assert.NoError(t, CopyFile(src, dst))
+
+	data, err := os.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "a,b\n1,2\n", string(data))
+	assert.FileExists(t, src)
+}
+
+func TestCopyFileReplacesDestination(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "new.txt"), filepath.Join(dir, "old.txt")
+	assert.NoError(t, os.WriteFile(src, []byte("new"), 0o644))
+	assert.NoError(t, os.WriteFile(dst, []byte("old content"), 0o644))
+
+	assert.NoError(t, CopyFile(src, dst))
+
+	data, err := os.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+}
+
+func TestCopyFileFailureCleansUp(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "out", "file.txt")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(dst), 0o755))
+	assert.NoError(t, os.WriteFile(dst, []byte("keep me"), 0o644))
+
+	// A directory opens like a file but fails once it is read
+	source := filepath.Join(dir, "source")
+	assert.NoError(t, os.Mkdir(source, 0o755))
+	err := CopyFile(source, dst)
+	assert.ErrorContains(t, err, "'"+source+"'")
+
+	err = CopyFile(filepath.Join(dir, "missing.txt"), dst)
+	assert.ErrorContains(t, err, "failed to open '")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	entries, err := os.ReadDir(filepath.Dir(dst))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "no partial copy is left behind")
+	data, err := os.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "keep me", string(data))
+}
+
+func TestMoveFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.txt")
+	dst := filepath.Join(dir, "moved", "a.txt")
+	assert.NoError(t, os.WriteFile(src, []byte("alpha"), 0o640))
+
+	assert.NoError(t, MoveFile(src, dst))
+
+	assert.NoFileExists(t, src)
+	data, err := os.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "alpha", string(data))
+}
+
+func TestMoveFileAcrossFilesystems(t *testing.T) {
+	rename = func(oldPath, newPath string) error {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: errors.New("invalid cross-device link")}
+	}
+	t.Cleanup(func() { rename = os.Rename })
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.txt")
+	dst := filepath.Join(dir, "other", "a.txt")
+	assert.NoError(t, os.WriteFile(src, []byte("alpha"), 0o600))
+
+	assert.NoError(t, MoveFile(src, dst))
+
+	assert.NoFileExists(t, src)
+	data, err := os.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "alpha", string(data))
+}
+
+func TestMoveFileMissingSource(t *testing.T) {
+	dir := t.TempDir()
+	err := MoveFile(filepath.Join(dir, "missing.txt"), filepath.Join(dir, "dst.txt"))
+	assert.ErrorContains(t, err, "failed to move '")
+	assert.NoFileExists(t, filepath.Join(dir, "dst.txt"))
+}
@@ -1,20 +1,156 @@
 package fileutils
 
 import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"hash"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
+// DefaultDirMode is the permission of directories created by EnsureDirectoryExists
+const DefaultDirMode os.FileMode = 0o755
+
 // EnsureDirectoryExists creates the specified directory if it does not exist
 func EnsureDirectoryExists(path string) error {
+	return EnsureDirectoryExistsMode(path, DefaultDirMode)
+}
+
+// EnsureDirectoryExistsMode creates the specified directory and any missing
+// parents with the given permission, before the umask, if it does not exist
+func EnsureDirectoryExistsMode(path string, mode os.FileMode) error {
 	if path == "" {
 		return fmt.Errorf("empty path provided")
 	}
-	return os.MkdirAll(path, os.ModePerm)
+	return os.MkdirAll(path, mode)
 }
 
-// FileExists checks if a file exists at the specified path
+// FileExists checks if anything exists at the specified path. It is also true
+// for directories; use IsFile to tell whether a regular file is there.
 func FileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
+
+// IsFile reports whether path is a regular file, following symlinks
+func IsFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// IsDir reports whether path is a directory, following symlinks
+func IsDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// DirectorySize returns the total size of the regular files under path. Symlinks
+// are neither followed nor counted, so a link to a large tree costs nothing.
+func DirectorySize(path string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure '%s': %w", path, err)
+	}
+	return total, nil
+}
+
+// SafeJoin joins the slash-separated key to base and fails if the cleaned result
+// lies outside base, as for keys like "../../etc/passwd". A leading slash does not
+// make a key absolute; it is placed under base like any other.
+func SafeJoin(base, key string) (string, error) {
+	path := filepath.Join(base, filepath.FromSlash(key))
+	rel, err := filepath.Rel(filepath.Clean(base), path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("key '%s' resolves to a path outside of '%s'", key, base)
+	}
+	return path, nil
+}
+
+// ReadLines reads the non-empty lines of a text file, trimming surrounding whitespace
+// and skipping lines starting with '#'
+func ReadLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// ComputeFileChecksum returns the hex-encoded digest of a file using the "md5" or
+// "sha256" algorithm, streaming the contents through the hash
+func ComputeFileChecksum(path, algo string) (string, error) {
+	sums, err := ComputeFileChecksums(path, algo)
+	if err != nil {
+		return "", err
+	}
+	return sums[algo], nil
+}
+
+// ComputeFileChecksums returns the hex-encoded digests of a file for each of the
+// given algorithms, keyed by algorithm, reading the file only once
+func ComputeFileChecksums(path string, algos ...string) (map[string]string, error) {
+	hashes := make(map[string]hash.Hash, len(algos))
+	writers := make([]io.Writer, 0, len(algos))
+	for _, algo := range algos {
+		if _, ok := hashes[algo]; ok {
+			continue
+		}
+		var h hash.Hash
+		switch algo {
+		case "md5":
+			h = md5.New()
+		case "sha256":
+			h = sha256.New()
+		default:
+			return nil, fmt.Errorf("unsupported checksum algorithm '%s'", algo)
+		}
+		hashes[algo] = h
+		writers = append(writers, h)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open '%s': %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(io.MultiWriter(writers...), f); err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+	sums := make(map[string]string, len(hashes))
+	for algo, h := range hashes {
+		sums[algo] = hex.EncodeToString(h.Sum(nil))
+	}
+	return sums, nil
+}
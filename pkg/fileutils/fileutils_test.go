@@ -1,7 +1,9 @@
 package fileutils
 
 import (
+	"bytes"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -64,3 +66,206 @@ func TestFileExists(t *testing.T) {
 	// Cleanup
 	os.Remove(testFile)
 }
+
+func TestIsFileAndIsDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	assert.NoError(t, os.WriteFile(file, []byte("content"), 0o644))
+
+	testCases := []struct {
+		name       string
+		path       string
+		wantExists bool
+		wantFile   bool
+		wantDir    bool
+	}{
+		{"File", file, true, true, false},
+		{"Directory", dir, true, false, true},
+		{"Missing path", filepath.Join(dir, "missing"), false, false, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantExists, FileExists(tc.path))
+			assert.Equal(t, tc.wantFile, IsFile(tc.path))
+			assert.Equal(t, tc.wantDir, IsDir(tc.path))
+		})
+	}
+}
+
+func TestDirectorySize(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]int{
+		"a.txt":              10,
+		"sub/b.bin":          1000,
+		"sub/deeper/c.bin":   4096,
+		"sub/deeper/empty":   0,
+		"other/.hidden.part": 7,
+	}
+	for name, size := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, make([]byte, size), 0o644))
+	}
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "empty", "dir"), 0o755))
+
+	size, err := DirectorySize(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10+1000+4096+7), size)
+
+	size, err = DirectorySize(filepath.Join(dir, "sub"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000+4096), size)
+
+	size, err = DirectorySize(filepath.Join(dir, "a.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), size, "a file is its own size")
+
+	_, err = DirectorySize(filepath.Join(dir, "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestReadLines(t *testing.T) {
+	testFile := "testkeys.txt"
+	err := os.WriteFile(testFile, []byte("# keys to fetch\na.txt\n\n  dir/b.txt  \r\nc.txt"), 0o600)
+	assert.NoError(t, err)
+	defer os.Remove(testFile)
+
+	lines, err := ReadLines(testFile)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "dir/b.txt", "c.txt"}, lines)
+
+	_, err = ReadLines("nonexistent.txt")
+	assert.Error(t, err)
+}
+
+func TestComputeFileChecksumMD5(t *testing.T) {
+	testFile := "testchecksum.txt"
+	err := os.WriteFile(testFile, []byte("The quick brown fox jumps over the lazy dog"), 0o600)
+	assert.NoError(t, err)
+	defer os.Remove(testFile)
+
+	sum, err := ComputeFileChecksum(testFile, "md5")
+	assert.NoError(t, err)
+	assert.Equal(t, "9e107d9d372bb6826bd81d3542a419d6", sum)
+
+	_, err = ComputeFileChecksum("nonexistent.txt", "md5")
+	assert.ErrorContains(t, err, "nonexistent.txt")
+}
+
+func TestComputeFileChecksumSHA256(t *testing.T) {
+	testFile := "testchecksum256.txt"
+	err := os.WriteFile(testFile, []byte("abc"), 0o600)
+	assert.NoError(t, err)
+	defer os.Remove(testFile)
+
+	sum, err := ComputeFileChecksum(testFile, "sha256")
+	assert.NoError(t, err)
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", sum)
+}
+
+func TestComputeFileChecksumVectors(t *testing.T) {
+	testCases := []struct {
+		name       string
+		content    []byte
+		wantMD5    string
+		wantSHA256 string
+	}{
+		{"Empty", nil, "d41d8cd98f00b204e9800998ecf8427e", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"Small", []byte("abc"), "900150983cd24fb0d6963f7d28e17f72", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"One million a", bytes.Repeat([]byte("a"), 1000000), "7707d6ae4e027c70eea2a935c2296f21", "cdc76e5c9914fb9281a1c7e284d73e67f1809a48a497200e046d39ccc7112cd0"},
+		{"8 MiB of zeros", make([]byte, 8<<20), "96995b58d4cbf6aaa9041b4f00c7f6ae", "2daeb1f36095b44b318410b3f4e8b5d989dcc7bb023d1426c492dab0a3053e74"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			assert.NoError(t, os.WriteFile(path, tc.content, 0o600))
+
+			sum, err := ComputeFileChecksum(path, "md5")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantMD5, sum)
+
+			sum, err = ComputeFileChecksum(path, "sha256")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantSHA256, sum)
+		})
+	}
+}
+
+func TestComputeFileChecksumErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(path, []byte("abc"), 0o600))
+
+	_, err := ComputeFileChecksum(path, "sha1")
+	assert.EqualError(t, err, "unsupported checksum algorithm 'sha1'")
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	_, err = ComputeFileChecksum(missing, "sha256")
+	assert.ErrorContains(t, err, "failed to open '"+missing+"'")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	dir := t.TempDir()
+	_, err = ComputeFileChecksum(dir, "sha256")
+	assert.ErrorContains(t, err, "'"+dir+"'")
+}
+
+func TestComputeFileChecksums(t *testing.T) {
+	testFile := "testchecksums.txt"
+	err := os.WriteFile(testFile, []byte("abc"), 0o600)
+	assert.NoError(t, err)
+	defer os.Remove(testFile)
+
+	sums, err := ComputeFileChecksums(testFile, "md5", "sha256", "md5")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"md5":    "900150983cd24fb0d6963f7d28e17f72",
+		"sha256": "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+	}, sums)
+
+	_, err = ComputeFileChecksums(testFile, "crc32")
+	assert.ErrorContains(t, err, "unsupported checksum algorithm")
+}
+
+func TestSafeJoin(t *testing.T) {
+	base := filepath.Join("downloads", "bucket")
+	testCases := []struct {
+		name    string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"Plain key", "a.txt", filepath.Join(base, "a.txt"), false},
+		{"Nested key", "dir/sub/b.txt", filepath.Join(base, "dir", "sub", "b.txt"), false},
+		{"Dot segments that stay inside", "dir/../c.txt", filepath.Join(base, "c.txt"), false},
+		{"Absolute key", "/etc/passwd", filepath.Join(base, "etc", "passwd"), false},
+		{"Dots in a name", "..hidden/x..y", filepath.Join(base, "..hidden", "x..y"), false},
+		{"Parent escape", "../../etc/passwd", "", true},
+		{"Escape after a folder", "dir/../../other/secret", "", true},
+		{"Parent only", "..", "", true},
+		{"Base itself", "dir/..", base, false},
+		{"Empty key", "", base, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SafeJoin(base, tc.key)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSafeJoinAbsoluteBase(t *testing.T) {
+	base := t.TempDir()
+	got, err := SafeJoin(base, "/nested/file.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(base, "nested", "file.txt"), got)
+
+	_, err = SafeJoin(base, "../"+filepath.Base(base)+"-sibling/file.txt")
+	assert.Error(t, err)
+}
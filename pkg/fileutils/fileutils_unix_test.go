@@ -0,0 +1,89 @@
+//go:build unix
+
+package fileutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+// currentUmask returns the process umask, which can only be read by setting it
+func currentUmask() os.FileMode {
+	mask := unix.Umask(0)
+	unix.Umask(mask)
+	return os.FileMode(mask)
+}
+
+func TestEnsureDirectoryExistsMode(t *testing.T) {
+	umask := currentUmask()
+	testCases := []struct {
+		name string
+		mode os.FileMode
+	}{
+		{"Private", 0o700},
+		{"Group readable", 0o750},
+		{"Default", DefaultDirMode},
+		{"World writable", 0o777},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			base := t.TempDir()
+			assert.NoError(t, EnsureDirectoryExistsMode(filepath.Join(base, "a", "b"), tc.mode))
+
+			// Every missing parent gets the requested mode
+			for _, dir := range []string{"a", filepath.Join("a", "b")} {
+				info, err := os.Stat(filepath.Join(base, dir))
+				assert.NoError(t, err)
+				assert.Equal(t, tc.mode&^umask, info.Mode().Perm(), dir)
+			}
+		})
+	}
+}
+
+func TestEnsureDirectoryExistsDefaultMode(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "default")
+	assert.NoError(t, EnsureDirectoryExists(dir))
+
+	info, err := os.Stat(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultDirMode&^currentUmask(), info.Mode().Perm())
+	assert.Zero(t, info.Mode().Perm()&0o022, "not writable by group or others")
+}
+
+func TestCopyAndMovePreserveMode(t *testing.T) {
+	for _, mode := range []os.FileMode{0o600, 0o640, 0o755} {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "src")
+		assert.NoError(t, os.WriteFile(src, []byte("content"), 0o600))
+		assert.NoError(t, os.Chmod(src, mode))
+
+		copied, moved := filepath.Join(dir, "copy", "file"), filepath.Join(dir, "move", "file")
+		assert.NoError(t, CopyFile(src, copied))
+		assert.NoError(t, MoveFile(src, moved))
+
+		for _, path := range []string{copied, moved} {
+			info, err := os.Stat(path)
+			assert.NoError(t, err)
+			assert.Equal(t, mode, info.Mode().Perm(), path)
+		}
+	}
+}
+
+func TestDirectorySizeSkipsSymlinks(t *testing.T) {
+	target := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(target, "big.bin"), make([]byte, 5000), 0o644))
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "small.txt"), make([]byte, 20), 0o644))
+	assert.NoError(t, os.Symlink(target, filepath.Join(dir, "linked-dir")))
+	assert.NoError(t, os.Symlink(filepath.Join(target, "big.bin"), filepath.Join(dir, "linked-file")))
+
+	size, err := DirectorySize(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), size)
+}
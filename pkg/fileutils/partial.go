@@ -0,0 +1,65 @@
+package fileutils
+
+import (
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// partialName matches the hidden names of downloads in progress, from PartialPath,
+// and of copies in progress, from CopyFile
+var partialName = regexp.MustCompile(`^\..+\.(part|tmp)\d+$`)
+
+// PartialPath returns a unique hidden name in the directory of path for a file
+// being written, such as ".report.csv.part123456"
+func PartialPath(path string) string {
+	dir, name := filepath.Split(path)
+	return filepath.Join(dir, fmt.Sprintf(".%s.part%d", name, rand.Uint32()))
+}
+
+// IsPartial reports whether name is the base name of a file left by PartialPath
+// or CopyFile
+func IsPartial(name string) bool {
+	return partialName.MatchString(name)
+}
+
+// CleanPartials removes the partial files under dir that were last modified more
+// than olderThan ago, as left by a crashed or killed download, and returns how many
+// it removed. Files not named like a partial file are never touched, nor are those
+// of a download still writing them, and symlinks are not followed. A missing dir
+// holds nothing to clean.
+func CleanPartials(dir string, olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+	removed := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.Type().IsRegular() || !IsPartial(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.ModTime().Before(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("failed to clean partial files in '%s': %w", dir, err)
+	}
+	return removed, nil
+}
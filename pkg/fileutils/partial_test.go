@@ -0,0 +1,93 @@
+package fileutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartialPath(t *testing.T) {
+	path := filepath.Join("out", "dir", "report.csv")
+	part := PartialPath(path)
+	assert.Equal(t, filepath.Join("out", "dir"), filepath.Dir(part))
+	assert.Regexp(t, `^\.report\.csv\.part\d+$`, filepath.Base(part))
+	assert.NotEqual(t, part, PartialPath(path))
+	assert.True(t, IsPartial(filepath.Base(part)))
+}
+
+func TestIsPartial(t *testing.T) {
+	testCases := []struct {
+		name string
+		want bool
+	}{
+		{".report.csv.part123456", true},
+		{".archive.tar.gz.part0", true},
+		{".notes.txt.tmp4031285", true},
+		{"report.csv.part123456", false}, // Not hidden
+		{".report.csv.part", false},      // No number
+		{".report.csv.part12x", false},
+		{".part123", false}, // No file name
+		{"part1.rar", false},
+		{".bashrc", false},
+		{"report.csv", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, IsPartial(tc.name))
+		})
+	}
+}
+
+func TestCleanPartials(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+	files := []struct {
+		name    string
+		old     bool
+		removed bool
+	}{
+		{".a.txt.part123", true, true},
+		{"sub/deeper/.b.bin.part42", true, true},
+		{"sub/.copy.txt.tmp99", true, true},
+		{".fresh.txt.part7", false, false}, // Possibly still being written
+		{"sub/.fresh.bin.tmp8", false, false},
+		{"a.txt", true, false},
+		{"sub/.config", true, false},
+		{"sub/video.part1", true, false},
+		{"sub/.b.bin.part", true, false},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f.name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte("data"), 0o644))
+		if f.old {
+			assert.NoError(t, os.Chtimes(path, old, old))
+		}
+	}
+	// A directory named like a partial file is not one
+	partDir := filepath.Join(dir, ".dir.part5")
+	assert.NoError(t, os.Mkdir(partDir, 0o755))
+	assert.NoError(t, os.Chtimes(partDir, old, old))
+
+	removed, err := CleanPartials(dir, 24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, removed)
+	for _, f := range files {
+		assert.Equal(t, !f.removed, FileExists(filepath.Join(dir, filepath.FromSlash(f.name))), f.name)
+	}
+	assert.True(t, IsDir(partDir))
+
+	removed, err = CleanPartials(dir, 24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, removed, "a second pass finds nothing")
+}
+
+func TestCleanPartialsMissingDirectory(t *testing.T) {
+	removed, err := CleanPartials(filepath.Join(t.TempDir(), "missing"), time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, removed)
+}
@@ -0,0 +1,59 @@
+package fileutils
+
+import "strings"
+
+// windowsReservedChars cannot appear in Windows file names; path separators are
+// handled separately
+const windowsReservedChars = `<>:"|?*`
+
+// windowsDeviceNames are reserved on Windows with or without an extension
+var windowsDeviceNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// sanitizeWindowsName makes every segment of key between '/' or '\' separators
+// a valid Windows file name, keeping the separators themselves
+func sanitizeWindowsName(key string) string {
+	var b strings.Builder
+	start := 0
+	for i := 0; i <= len(key); i++ {
+		if i == len(key) || key[i] == '/' || key[i] == '\\' {
+			b.WriteString(sanitizeWindowsSegment(key[start:i]))
+			if i < len(key) {
+				b.WriteByte(key[i])
+			}
+			start = i + 1
+		}
+	}
+	return b.String()
+}
+
+// sanitizeWindowsSegment replaces reserved and control characters with '_',
+// turns trailing dots and spaces into '_' and adds '_' to device names, so
+// "a:b?", "notes." and "con.txt" become "a_b_", "notes_" and "con_.txt"
+func sanitizeWindowsSegment(name string) string {
+	if name == "" || name == "." || name == ".." {
+		return name // Resolved as path elements, never created
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(windowsReservedChars, r) {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if trimmed := strings.TrimRight(name, ". "); trimmed != name {
+		name = trimmed + strings.Repeat("_", len(name)-len(trimmed))
+	}
+
+	base, _, _ := strings.Cut(name, ".")
+	if windowsDeviceNames[strings.ToUpper(strings.TrimRight(base, " "))] {
+		name = base + "_" + name[len(base):]
+	}
+	return name
+}
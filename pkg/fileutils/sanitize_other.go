@@ -0,0 +1,11 @@
+//go:build !windows
+
+package fileutils
+
+import "strings"
+
+// SanitizeFilename turns an object key into a relative path the local filesystem
+// can create. Only NUL bytes are replaced, as any other character is allowed.
+func SanitizeFilename(key string) string {
+	return strings.ReplaceAll(key, "\x00", "_")
+}
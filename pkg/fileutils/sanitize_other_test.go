@@ -0,0 +1,19 @@
+//go:build !windows
+
+package fileutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	// Names Windows rejects are valid here and kept as they are
+	for _, tc := range windowsNameCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.key, SanitizeFilename(tc.key))
+		})
+	}
+	assert.Equal(t, "a_b", SanitizeFilename("a\x00b"))
+}
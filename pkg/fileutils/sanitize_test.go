@@ -0,0 +1,35 @@
+package fileutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// windowsNameCases map keys to the names they are saved under on Windows
+var windowsNameCases = []struct {
+	name string
+	key  string
+	want string
+}{
+	{"Plain key", "dir/file.txt", "dir/file.txt"},
+	{"Reserved characters", `logs/12:30/a<b>"c"|d?e*.txt`, `logs/12_30/a_b__c__d_e_.txt`},
+	{"Control characters", "a\tb\x01.txt", "a_b_.txt"},
+	{"Trailing dot", "dir./notes.", "dir_/notes_"},
+	{"Trailing spaces", "name  /x", "name__/x"},
+	{"Device name", "CON", "CON_"},
+	{"Device name with extension", "out/nul.tar.gz", "out/nul_.tar.gz"},
+	{"Lower case device name", "lpt1/com9.log", "lpt1_/com9_.log"},
+	{"Device name prefix only", "console/CONFIG.sys", "console/CONFIG.sys"},
+	{"Backslash separators", `a\b:c\aux`, `a\b_c\aux_`},
+	{"Empty and dot segments", "a//./../b", "a//./../b"},
+	{"Unicode", "données/résumé?.pdf", "données/résumé_.pdf"},
+}
+
+func TestSanitizeWindowsName(t *testing.T) {
+	for _, tc := range windowsNameCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, sanitizeWindowsName(tc.key))
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package fileutils
+
+// SanitizeFilename turns an object key into a relative path Windows can create,
+// replacing reserved characters and device names in each segment while keeping
+// the path separators
+func SanitizeFilename(key string) string {
+	return sanitizeWindowsName(key)
+}
@@ -0,0 +1,15 @@
+package fileutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	for _, tc := range windowsNameCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, SanitizeFilename(tc.key))
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package fileutils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits maps lower-case unit names to their number of bytes: SI units are
+// powers of 1000 and IEC units powers of 1024
+var sizeUnits = map[string]float64{
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// ParseSize parses a human-readable size such as "10MB", "512KiB" or "1.5 GB" into
+// bytes. Units are case-insensitive; KB, MB, GB and TB are decimal, KiB, MiB, GiB
+// and TiB binary. A unit is required, since a bare number could mean either.
+func ParseSize(s string) (int64, error) {
+	text := strings.TrimSpace(s)
+	end := strings.IndexFunc(text, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.' && r != '-' && r != '+'
+	})
+	if end < 0 {
+		return 0, fmt.Errorf("invalid size '%s': add a unit such as MB or MiB", s)
+	}
+	number, unit := text[:end], strings.TrimSpace(text[end:])
+	multiplier, ok := sizeUnits[strings.ToLower(unit)]
+	if !ok {
+		return 0, fmt.Errorf("invalid size '%s': unknown unit '%s'", s, unit)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size '%s': '%s' is not a number", s, number)
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("invalid size '%s': must not be negative", s)
+	}
+	bytes := math.Round(value * multiplier)
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size '%s': too large", s)
+	}
+	return int64(bytes), nil
+}
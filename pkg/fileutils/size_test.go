@@ -0,0 +1,51 @@
+package fileutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSize(t *testing.T) {
+	testCases := []struct {
+		input   string
+		want    int64
+		wantErr string
+	}{
+		{"512B", 512, ""},
+		{"10KB", 10000, ""},
+		{"10kb", 10000, ""},
+		{"10MB", 10000000, ""},
+		{"1.5GB", 1500000000, ""},
+		{"2TB", 2000000000000, ""},
+		{"512KiB", 512 * 1024, ""},
+		{"10MiB", 10 * 1024 * 1024, ""},
+		{"10mib", 10 * 1024 * 1024, ""},
+		{"1.5GiB", 3 * 512 * 1024 * 1024, ""},
+		{"1TiB", 1 << 40, ""},
+		{" 16 MiB ", 16 * 1024 * 1024, ""},
+		{"0MB", 0, ""},
+		{".5KiB", 512, ""},
+		{"", 0, "invalid size '': add a unit such as MB or MiB"},
+		{"1024", 0, "invalid size '1024': add a unit such as MB or MiB"},
+		{"1.5", 0, "invalid size '1.5': add a unit such as MB or MiB"},
+		{"-5MB", 0, "invalid size '-5MB': must not be negative"},
+		{"10XB", 0, "invalid size '10XB': unknown unit 'XB'"},
+		{"MB", 0, "invalid size 'MB': '' is not a number"},
+		{"1.2.3MB", 0, "invalid size '1.2.3MB': '1.2.3' is not a number"},
+		{"10 M B", 0, "invalid size '10 M B': unknown unit 'M B'"},
+		{"99999999TiB", 0, "invalid size '99999999TiB': too large"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseSize(tc.input)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package fileutils
+
+import "errors"
+
+// AvailableSpace is not supported on this platform
+func AvailableSpace(path string) (int64, error) {
+	return 0, errors.New("free space is not available on this platform")
+}
@@ -0,0 +1,15 @@
+//go:build linux || darwin || freebsd
+
+package fileutils
+
+import "golang.org/x/sys/unix"
+
+// AvailableSpace returns the number of bytes an unprivileged user can still
+// write to the volume holding path
+func AvailableSpace(path string) (int64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
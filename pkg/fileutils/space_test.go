@@ -0,0 +1,19 @@
+//go:build linux || darwin || freebsd || windows
+
+package fileutils
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvailableSpace(t *testing.T) {
+	free, err := AvailableSpace(t.TempDir())
+	assert.NoError(t, err)
+	assert.Greater(t, free, int64(0))
+
+	_, err = AvailableSpace(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
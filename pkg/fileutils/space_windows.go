@@ -0,0 +1,19 @@
+//go:build windows
+
+package fileutils
+
+import "golang.org/x/sys/windows"
+
+// AvailableSpace returns the number of bytes the current user can still write
+// to the volume holding path
+func AvailableSpace(path string) (int64, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var free uint64
+	if err := windows.GetDiskFreeSpaceEx(name, &free, nil, nil); err != nil {
+		return 0, err
+	}
+	return int64(free), nil
+}